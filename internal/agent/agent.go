@@ -12,11 +12,13 @@ import (
 	"context"
 	_ "embed"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,10 +33,12 @@ import (
 	"charm.land/fantasy/providers/openrouter"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/crush/internal/agent/hyper"
+	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/filetracker"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/session"
@@ -71,6 +75,10 @@ type SessionAgentCall struct {
 	TopK             *int64
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
+	// Model replaces the large model for this call when set.
+	Model *Model
+	// AllowedTools limits the tools of this call, nil allows them all.
+	AllowedTools []string
 }
 
 type SessionAgent interface {
@@ -101,10 +109,15 @@ type sessionAgent struct {
 	systemPromptPrefix *csync.Value[string]
 	systemPrompt       *csync.Value[string]
 	tools              *csync.Slice[fantasy.AgentTool]
+	// loadedTools holds the deferred tools loaded with tool_search, by
+	// session ID.
+	loadedTools *csync.Map[string, []string]
 
 	isSubAgent           bool
 	sessions             session.Service
 	messages             message.Service
+	filetracker          filetracker.Service
+	nestedContext        *prompt.NestedContext
 	disableAutoSummarize bool
 	isYolo               bool
 
@@ -123,6 +136,8 @@ type SessionAgentOptions struct {
 	Sessions             session.Service
 	Messages             message.Service
 	Tools                []fantasy.AgentTool
+	FileTracker          filetracker.Service
+	NestedContext        *prompt.NestedContext
 }
 
 func NewSessionAgent(
@@ -136,8 +151,11 @@ func NewSessionAgent(
 		isSubAgent:           opts.IsSubAgent,
 		sessions:             opts.Sessions,
 		messages:             opts.Messages,
+		filetracker:          opts.FileTracker,
+		nestedContext:        opts.NestedContext,
 		disableAutoSummarize: opts.DisableAutoSummarize,
 		tools:                csync.NewSliceFrom(opts.Tools),
+		loadedTools:          csync.NewMap[string, []string](),
 		isYolo:               opts.IsYolo,
 		messageQueue:         csync.NewMap[string, []SessionAgentCall](),
 		activeRequests:       csync.NewMap[string, context.CancelFunc](),
@@ -167,6 +185,14 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 	agentTools := a.tools.Copy()
 	largeModel := a.largeModel.Get()
 	systemPrompt := a.systemPrompt.Get()
+	if call.Model != nil {
+		largeModel = *call.Model
+	}
+	if call.AllowedTools != nil {
+		agentTools = slices.DeleteFunc(agentTools, func(tool fantasy.AgentTool) bool {
+			return !slices.Contains(call.AllowedTools, tool.Info().Name)
+		})
+	}
 	promptPrefix := a.systemPromptPrefix.Get()
 	var instructions strings.Builder
 
@@ -184,6 +210,21 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		systemPrompt += "\n\n<mcp-instructions>\n" + s + "\n</mcp-instructions>"
 	}
 
+	// Deferred tools go first, so the last tool, which is cached, is always
+	// sent.
+	hasDeferredTools := slices.ContainsFunc(agentTools, isDeferred)
+	if hasDeferredTools {
+		slices.SortStableFunc(agentTools, func(a, b fantasy.AgentTool) int {
+			if isDeferred(a) == isDeferred(b) {
+				return 0
+			}
+			if isDeferred(a) {
+				return -1
+			}
+			return 1
+		})
+	}
+
 	if len(agentTools) > 0 {
 		// Add Anthropic caching to the last tool.
 		agentTools[len(agentTools)-1].SetProviderOptions(a.getCacheControlOptions())
@@ -233,11 +274,26 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 
 	history, files := a.preparePrompt(msgs, call.Attachments...)
 
+	// Media of tool results after the first item, which the results can't
+	// carry, by tool call ID.
+	toolMedia := csync.NewMap[string, []fantasy.FilePart]()
+	for _, msg := range msgs {
+		for _, result := range msg.ToolResults() {
+			if media := toolResultMedia(result); len(media) > 0 {
+				toolMedia.Set(result.ToolCallID, media)
+			}
+			a.loadTools(call.SessionID, loadedToolNames(result))
+		}
+	}
+
 	startTime := time.Now()
 	a.eventPromptSent(call.SessionID)
 
 	var currentAssistant *message.Message
 	var shouldSummarize bool
+	// Reminders of nested context files sent in earlier steps, kept where
+	// they were sent since messages prepared for a step aren't kept.
+	var nestedReminders []stepReminder
 	result, err := agent.Stream(genCtx, fantasy.AgentStreamCall{
 		Prompt:           message.PromptWithTextAttachments(call.Prompt, call.Attachments),
 		Files:            files,
@@ -254,6 +310,7 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 			for i := range prepared.Messages {
 				prepared.Messages[i].ProviderOptions = nil
 			}
+			prepared.Messages = withReminders(prepared.Messages, nestedReminders)
 
 			queuedCalls, _ := a.messageQueue.Get(call.SessionID)
 			a.messageQueue.Del(call.SessionID)
@@ -265,6 +322,18 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 				prepared.Messages = append(prepared.Messages, userMessage.ToAIMessage()...)
 			}
 
+			if reminder := a.nestedContextReminder(call.SessionID, options.Steps); reminder != "" {
+				nestedReminders = append(nestedReminders, stepReminder{after: len(options.Messages), text: reminder})
+				prepared.Messages = append(prepared.Messages, fantasy.NewUserMessage(reminder))
+			}
+			if reminder := a.externalChangesReminder(callContext, call.SessionID); reminder != "" {
+				prepared.Messages = append(prepared.Messages, fantasy.NewUserMessage(reminder))
+			}
+
+			prepared.Messages = attachToolMedia(prepared.Messages, toolMedia)
+			if hasDeferredTools {
+				prepared.Tools = a.activeTools(call.SessionID, agentTools)
+			}
 			prepared.Messages = a.workaroundProviderMediaLimitations(prepared.Messages, largeModel)
 
 			lastSystemRoleInx := 0
@@ -368,6 +437,10 @@ func (a *sessionAgent) Run(ctx context.Context, call SessionAgentCall) (*fantasy
 		},
 		OnToolResult: func(result fantasy.ToolResultContent) error {
 			toolResult := a.convertToToolResult(result)
+			if media := toolResultMedia(toolResult); len(media) > 0 {
+				toolMedia.Set(toolResult.ToolCallID, media)
+			}
+			a.loadTools(call.SessionID, loadedToolNames(toolResult))
 			_, createMsgErr := a.messages.Create(genCtx, currentAssistant.SessionID, message.CreateMessageParams{
 				Role: message.Tool,
 				Parts: []message.ContentPart{
@@ -701,6 +774,90 @@ func (a *sessionAgent) createUserMessage(ctx context.Context, call SessionAgentC
 	return msg, nil
 }
 
+// stepReminder is a reminder sent after the first messages of a step.
+type stepReminder struct {
+	after int
+	text  string
+}
+
+// withReminders inserts the reminders sent in earlier steps in msgs, where
+// they were sent.
+func withReminders(msgs []fantasy.Message, reminders []stepReminder) []fantasy.Message {
+	if len(reminders) == 0 {
+		return msgs
+	}
+	result := make([]fantasy.Message, 0, len(msgs)+len(reminders))
+	var prev int
+	for _, r := range reminders {
+		result = append(result, msgs[prev:r.after]...)
+		result = append(result, fantasy.NewUserMessage(r.text))
+		prev = r.after
+	}
+	return append(result, msgs[prev:]...)
+}
+
+// nestedContextReminder loads the context files of the directories of the
+// files viewed or edited in the last step, and returns the ones that weren't
+// sent yet. In the first step of a prompt, the ones loaded by earlier prompts
+// are sent again since they aren't part of the history.
+func (a *sessionAgent) nestedContextReminder(sessionID string, steps []fantasy.StepResult) string {
+	if a.nestedContext == nil {
+		return ""
+	}
+	var files []prompt.ContextFile
+	if len(steps) == 0 {
+		files = a.nestedContext.Loaded(sessionID)
+	} else {
+		for _, tc := range steps[len(steps)-1].Content.ToolCalls() {
+			switch tc.ToolName {
+			case tools.ViewToolName, tools.EditToolName, tools.MultiEditToolName, tools.WriteToolName, tools.NotebookEditToolName:
+			default:
+				continue
+			}
+			var params struct {
+				FilePath string `json:"file_path"`
+			}
+			if err := json.Unmarshal([]byte(tc.Input), &params); err != nil || params.FilePath == "" {
+				continue
+			}
+			files = append(files, a.nestedContext.Load(sessionID, params.FilePath)...)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<system_reminder>These memory files apply to the directories of the files you are working on. Follow their instructions there, in addition to the ones of the system prompt:\n<memory>\n")
+	for _, file := range files {
+		fmt.Fprintf(&sb, "<file path=%q>\n%s\n</file>\n", file.Path, file.Content)
+	}
+	sb.WriteString("</memory>\n</system_reminder>")
+	return sb.String()
+}
+
+// externalChangesReminder tells the agent about the files it read that were
+// changed on disk since, e.g. by the user in their editor.
+func (a *sessionAgent) externalChangesReminder(ctx context.Context, sessionID string) string {
+	if a.filetracker == nil {
+		return ""
+	}
+	changes := a.filetracker.ExternalChanges(ctx, sessionID)
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<system_reminder>The following files were changed outside of this session since you last read them. Read them again before modifying them, and don't undo these changes unless asked to:\n")
+	for _, change := range changes {
+		if change.Deleted {
+			fmt.Fprintf(&sb, "- %s (deleted)\n", change.Path)
+		} else {
+			fmt.Fprintf(&sb, "- %s\n", change.Path)
+		}
+	}
+	sb.WriteString("</system_reminder>")
+	return sb.String()
+}
+
 func (a *sessionAgent) preparePrompt(msgs []message.Message, attachments ...message.Attachment) ([]fantasy.Message, []fantasy.FilePart) {
 	var history []fantasy.Message
 	if !a.isSubAgent {
@@ -1049,6 +1206,97 @@ func (a *sessionAgent) convertToToolResult(result fantasy.ToolResultContent) mes
 	return baseResult
 }
 
+// toolResultMedia returns the media of a tool result after the first item,
+// kept in the metadata of MCP tool results.
+func toolResultMedia(result message.ToolResult) []fantasy.FilePart {
+	if result.Metadata == "" || result.IsError {
+		return nil
+	}
+	var metadata tools.MCPResponseMetadata
+	if err := json.Unmarshal([]byte(result.Metadata), &metadata); err != nil {
+		return nil
+	}
+	files := make([]fantasy.FilePart, 0, len(metadata.Media))
+	for i, media := range metadata.Media {
+		files = append(files, fantasy.FilePart{
+			Data:      media.Data,
+			MediaType: media.MediaType,
+			Filename:  fmt.Sprintf("tool-result-%s-%d", result.ToolCallID, i+2),
+		})
+	}
+	return files
+}
+
+// isDeferred reports whether tool is only sent to the model once it's loaded
+// with tool_search.
+func isDeferred(tool fantasy.AgentTool) bool {
+	t, ok := tool.(tools.DeferredTool)
+	return ok && t.Deferred()
+}
+
+// loadedToolNames returns the tools a tool_search result loaded.
+func loadedToolNames(result message.ToolResult) []string {
+	if result.Name != tools.ToolSearchToolName || result.Metadata == "" || result.IsError {
+		return nil
+	}
+	var metadata tools.ToolSearchResponseMetadata
+	if err := json.Unmarshal([]byte(result.Metadata), &metadata); err != nil {
+		return nil
+	}
+	return metadata.Tools
+}
+
+// loadTools makes the deferred tools names available for the rest of the
+// session.
+func (a *sessionAgent) loadTools(sessionID string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	loaded, _ := a.loadedTools.Get(sessionID)
+	loaded = slices.Clone(loaded)
+	for _, name := range names {
+		if !slices.Contains(loaded, name) {
+			loaded = append(loaded, name)
+		}
+	}
+	a.loadedTools.Set(sessionID, loaded)
+}
+
+// activeTools returns the tools sent to the model in the session: the ones
+// that aren't deferred, and the deferred ones it loaded.
+func (a *sessionAgent) activeTools(sessionID string, agentTools []fantasy.AgentTool) []fantasy.AgentTool {
+	loaded, _ := a.loadedTools.Get(sessionID)
+	return slices.DeleteFunc(slices.Clone(agentTools), func(tool fantasy.AgentTool) bool {
+		return isDeferred(tool) && !slices.Contains(loaded, tool.Info().Name)
+	})
+}
+
+// attachToolMedia sends the media of tool results after the first item in a
+// user message following the tool results.
+func attachToolMedia(messages []fantasy.Message, toolMedia *csync.Map[string, []fantasy.FilePart]) []fantasy.Message {
+	if toolMedia.Len() == 0 {
+		return messages
+	}
+	result := make([]fantasy.Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, msg)
+		if msg.Role != fantasy.MessageRoleTool {
+			continue
+		}
+		var files []fantasy.FilePart
+		for _, part := range msg.Content {
+			if toolResult, ok := fantasy.AsMessagePart[fantasy.ToolResultPart](part); ok {
+				media, _ := toolMedia.Get(toolResult.ToolCallID)
+				files = append(files, media...)
+			}
+		}
+		if len(files) > 0 {
+			result = append(result, fantasy.NewUserMessage("Here is the rest of the media content from the tool result:", files...))
+		}
+	}
+	return result
+}
+
 // workaroundProviderMediaLimitations converts media content in tool results to
 // user messages for providers that don't natively support images in tool results.
 //
@@ -5,14 +5,17 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"charm.land/fantasy"
 
 	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/permission"
 )
 
@@ -50,6 +53,16 @@ func validateAgenticFetchParams(ctx context.Context, params tools.AgenticFetchPa
 //go:embed templates/agentic_fetch_prompt.md.tpl
 var agenticFetchPromptTmpl []byte
 
+// newFetchCache creates the cache shared by the fetch tools, or returns nil
+// if it's disabled.
+func newFetchCache(cfg *config.Config) *tools.FetchCache {
+	if cfg.Tools.Fetch.DisableCache || cfg.Options == nil || cfg.Options.DataDirectory == "" {
+		return nil
+	}
+	ttl, maxSize := cfg.Tools.Fetch.CacheLimits()
+	return tools.NewFetchCache(filepath.Join(cfg.Options.DataDirectory, "cache", "fetch"), ttl, maxSize)
+}
+
 func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (fantasy.AgentTool, error) {
 	if client == nil {
 		client = &http.Client{
@@ -82,7 +95,7 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 			p, err := c.permissions.Request(ctx,
 				permission.CreatePermissionRequest{
 					SessionID:   validationResult.SessionID,
-					Path:        c.cfg.WorkingDir(),
+					Path:        c.cfg().WorkingDir(),
 					ToolCallID:  call.ID,
 					ToolName:    tools.AgenticFetchToolName,
 					Action:      "fetch",
@@ -97,7 +110,7 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
 			}
 
-			tmpDir, err := os.MkdirTemp(c.cfg.Options.DataDirectory, "crush-fetch-*")
+			tmpDir, err := os.MkdirTemp(c.cfg().Options.DataDirectory, "crush-fetch-*")
 			if err != nil {
 				return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to create temporary directory: %s", err)), nil
 			}
@@ -107,7 +120,7 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 
 			if params.URL != "" {
 				// URL mode: fetch the URL content first.
-				content, err := tools.FetchURLAndConvert(ctx, client, params.URL)
+				content, err := tools.FetchURLAndConvert(ctx, client, c.fetchCache, params.URL)
 				if err != nil {
 					return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to fetch URL: %s", err)), nil
 				}
@@ -150,25 +163,37 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 				return fantasy.ToolResponse{}, fmt.Errorf("error building models: %s", err)
 			}
 
-			systemPrompt, err := promptTemplate.Build(ctx, small.Model.Provider(), small.Model.Model(), *c.cfg)
+			systemPrompt, err := promptTemplate.Build(ctx, small.Model.Provider(), small.Model.Model(), *c.cfg())
 			if err != nil {
 				return fantasy.ToolResponse{}, fmt.Errorf("error building system prompt: %s", err)
 			}
 
-			smallProviderCfg, ok := c.cfg.Providers.Get(small.ModelCfg.Provider)
+			smallProviderCfg, ok := c.cfg().Providers.Get(small.ModelCfg.Provider)
 			if !ok {
 				return fantasy.ToolResponse{}, errors.New("small model provider not configured")
 			}
 
-			webFetchTool := tools.NewWebFetchTool(tmpDir, client)
-			webSearchTool := tools.NewWebSearchTool(client)
 			fetchTools := []fantasy.AgentTool{
-				webFetchTool,
-				webSearchTool,
+				tools.NewWebFetchTool(tmpDir, client, c.fetchCache),
+			}
+			// The page is already fetched in URL mode, so a broken search
+			// config only leaves the web_search tool out.
+			searchBackend, err := tools.NewSearchBackend(c.cfg().Tools.WebSearch, c.cfg().Resolver(), client)
+			switch {
+			case err == nil:
+				fetchTools = append(fetchTools, tools.NewWebSearchTool(client, searchBackend))
+			case params.URL == "":
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to configure web search: %s", err)), nil
+			default:
+				slog.Warn("Leaving out web_search tool", "error", err)
+			}
+			fetchTools = append(fetchTools,
 				tools.NewGlobTool(tmpDir),
 				tools.NewGrepTool(tmpDir),
-				tools.NewSourcegraphTool(client),
-				tools.NewViewTool(c.lspClients, c.permissions, c.filetracker, tmpDir),
+				tools.NewViewTool(c.lspManager, c.permissions, c.filetracker, tmpDir),
+			)
+			if c.sourcegraphEnabled() {
+				fetchTools = append(fetchTools, tools.NewSourcegraphTool(client, c.cfg().Tools.Sourcegraph, c.cfg().Resolver(), nil))
 			}
 
 			agent := NewSessionAgent(SessionAgentOptions{
@@ -176,7 +201,7 @@ func (c *coordinator) agenticFetchTool(_ context.Context, client *http.Client) (
 				SmallModel:           small,
 				SystemPromptPrefix:   smallProviderCfg.SystemPromptPrefix,
 				SystemPrompt:         systemPrompt,
-				DisableAutoSummarize: c.cfg.Options.DisableAutoSummarize,
+				DisableAutoSummarize: c.cfg().Options.DisableAutoSummarize,
 				IsYolo:               c.permissions.SkipRequests(),
 				Sessions:             c.sessions,
 				Messages:             c.messages,
@@ -20,8 +20,8 @@ import (
 	"github.com/charmbracelet/crush/internal/agent/hyper"
 	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
-	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/filetracker"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/log"
@@ -47,6 +47,8 @@ type Coordinator interface {
 	// INFO: (kujtim) this is not used yet we will use this when we have multiple agents
 	// SetMainAgent(string)
 	Run(ctx context.Context, sessionID, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error)
+	// RunWithOptions is Run with a different model or tools for this prompt.
+	RunWithOptions(ctx context.Context, sessionID, prompt string, opts RunOptions, attachments ...message.Attachment) (*fantasy.AgentResult, error)
 	Cancel(sessionID string)
 	CancelAll()
 	IsSessionBusy(sessionID string) bool
@@ -59,14 +61,27 @@ type Coordinator interface {
 	UpdateModels(ctx context.Context) error
 }
 
+// RunOptions changes how a single prompt is run, as custom commands do.
+type RunOptions struct {
+	// Model is large, small, or a model ID, optionally prefixed with its
+	// provider. Empty uses the current model.
+	Model string
+	// Agent limits the tools to the ones of the agent.
+	Agent string
+	// AllowedTools limits the tools, nil allows them all.
+	AllowedTools []string
+}
+
 type coordinator struct {
-	cfg         *config.Config
 	sessions    session.Service
 	messages    message.Service
 	permissions permission.Service
 	history     history.Service
 	filetracker filetracker.Service
-	lspClients  *csync.Map[string, *lsp.Client]
+	lspManager  *lsp.Manager
+	fetchCache  *tools.FetchCache
+
+	nestedContext *prompt.NestedContext
 
 	currentAgent SessionAgent
 	agents       map[string]SessionAgent
@@ -74,6 +89,11 @@ type coordinator struct {
 	readyWg errgroup.Group
 }
 
+// cfg returns the current config, which is replaced when it's reloaded.
+func (c *coordinator) cfg() *config.Config {
+	return config.Get()
+}
+
 func NewCoordinator(
 	ctx context.Context,
 	cfg *config.Config,
@@ -82,17 +102,19 @@ func NewCoordinator(
 	permissions permission.Service,
 	history history.Service,
 	filetracker filetracker.Service,
-	lspClients *csync.Map[string, *lsp.Client],
+	lspManager *lsp.Manager,
 ) (Coordinator, error) {
 	c := &coordinator{
-		cfg:         cfg,
 		sessions:    sessions,
 		messages:    messages,
 		permissions: permissions,
 		history:     history,
 		filetracker: filetracker,
-		lspClients:  lspClients,
+		lspManager:  lspManager,
+		fetchCache:  newFetchCache(cfg),
 		agents:      make(map[string]SessionAgent),
+
+		nestedContext: prompt.NewNestedContext(cfg.WorkingDir(), cfg.Options.ContextPaths),
 	}
 
 	agentCfg, ok := cfg.Agents[config.AgentCoder]
@@ -101,7 +123,10 @@ func NewCoordinator(
 	}
 
 	// TODO: make this dynamic when we support multiple agents
-	prompt, err := coderPrompt(prompt.WithWorkingDir(c.cfg.WorkingDir()))
+	prompt, err := coderPrompt(
+		prompt.WithWorkingDir(cfg.WorkingDir()),
+		prompt.WithAllowedSkills(agentCfg.AllowedSkills),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -112,11 +137,25 @@ func NewCoordinator(
 	}
 	c.currentAgent = agent
 	c.agents[config.AgentCoder] = agent
+
+	// MCP servers sample with the small model.
+	mcp.SetSampler(func(ctx context.Context) (fantasy.LanguageModel, error) {
+		model, err := c.resolveModel(ctx, string(config.SelectedModelTypeSmall))
+		if err != nil {
+			return nil, err
+		}
+		return model.Model, nil
+	})
 	return c, nil
 }
 
 // Run implements Coordinator.
 func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
+	return c.RunWithOptions(ctx, sessionID, prompt, RunOptions{}, attachments...)
+}
+
+// RunWithOptions implements Coordinator.
+func (c *coordinator) RunWithOptions(ctx context.Context, sessionID string, prompt string, opts RunOptions, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
 	if err := c.readyWg.Wait(); err != nil {
 		return nil, err
 	}
@@ -127,6 +166,19 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 	}
 
 	model := c.currentAgent.Model()
+	var modelOverride *Model
+	if opts.Model != "" && opts.Model != string(config.SelectedModelTypeLarge) {
+		m, err := c.resolveModel(ctx, opts.Model)
+		if err != nil {
+			return nil, err
+		}
+		model, modelOverride = m, &m
+	}
+	allowedTools, err := c.allowedTools(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	maxTokens := model.CatwalkCfg.DefaultMaxTokens
 	if model.ModelCfg.MaxTokens != 0 {
 		maxTokens = model.ModelCfg.MaxTokens
@@ -143,7 +195,7 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 		attachments = filteredAttachments
 	}
 
-	providerCfg, ok := c.cfg.Providers.Get(model.ModelCfg.Provider)
+	providerCfg, ok := c.cfg().Providers.Get(model.ModelCfg.Provider)
 	if !ok {
 		return nil, errors.New("model provider not configured")
 	}
@@ -169,6 +221,8 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 			TopK:             topK,
 			FrequencyPenalty: freqPenalty,
 			PresencePenalty:  presPenalty,
+			Model:            modelOverride,
+			AllowedTools:     allowedTools,
 		})
 	}
 	result, originalErr := run()
@@ -195,6 +249,91 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 	return result, originalErr
 }
 
+// resolveModel builds the model of a [RunOptions], which is either small or
+// a model ID, optionally prefixed with its provider.
+func (c *coordinator) resolveModel(ctx context.Context, spec string) (Model, error) {
+	var selected config.SelectedModel
+	if spec == string(config.SelectedModelTypeSmall) {
+		small, ok := c.cfg().Models[config.SelectedModelTypeSmall]
+		if !ok {
+			return Model{}, errors.New("small model not selected")
+		}
+		selected = small
+	} else {
+		var matches []config.SelectedModel
+		for name, providerCfg := range c.cfg().Providers.Seq2() {
+			if providerCfg.Disable {
+				continue
+			}
+			for _, m := range providerCfg.Models {
+				if m.ID == spec || name+"/"+m.ID == spec {
+					matches = append(matches, config.SelectedModel{Provider: name, Model: m.ID})
+				}
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return Model{}, fmt.Errorf("model %q not found", spec)
+		case 1:
+			selected = matches[0]
+		default:
+			return Model{}, fmt.Errorf("model %q found in multiple providers, use the provider/model format", spec)
+		}
+		// Keep the options of the model when it's one of the selected ones.
+		for _, m := range c.cfg().Models {
+			if m.Provider == selected.Provider && m.Model == selected.Model {
+				selected = m
+				break
+			}
+		}
+	}
+
+	providerCfg, ok := c.cfg().Providers.Get(selected.Provider)
+	if !ok {
+		return Model{}, fmt.Errorf("provider %q not configured", selected.Provider)
+	}
+	catwalkModel := c.cfg().GetModel(selected.Provider, selected.Model)
+	if catwalkModel == nil {
+		return Model{}, fmt.Errorf("model %q not found in provider config", selected.Model)
+	}
+	provider, err := c.buildProvider(providerCfg, selected, false)
+	if err != nil {
+		return Model{}, err
+	}
+	modelID := selected.Model
+	if selected.Provider == openrouter.Name && isExactoSupported(modelID) {
+		modelID += ":exacto"
+	}
+	languageModel, err := provider.LanguageModel(ctx, modelID)
+	if err != nil {
+		return Model{}, err
+	}
+	return Model{
+		Model:      languageModel,
+		CatwalkCfg: *catwalkModel,
+		ModelCfg:   selected,
+	}, nil
+}
+
+// allowedTools returns the tools a [RunOptions] allows, nil when it doesn't
+// limit them.
+func (c *coordinator) allowedTools(opts RunOptions) ([]string, error) {
+	allowed := opts.AllowedTools
+	if opts.Agent == "" || opts.Agent == config.AgentCoder {
+		return allowed, nil
+	}
+	agentCfg, ok := c.cfg().Agents[opts.Agent]
+	if !ok {
+		return nil, fmt.Errorf("agent %q not found", opts.Agent)
+	}
+	if allowed == nil {
+		return agentCfg.AllowedTools, nil
+	}
+	return slices.DeleteFunc(slices.Clone(allowed), func(name string) bool {
+		return !slices.Contains(agentCfg.AllowedTools, name)
+	}), nil
+}
+
 func getProviderOptions(model Model, providerCfg config.ProviderConfig) fantasy.ProviderOptions {
 	options := fantasy.ProviderOptions{}
 
@@ -331,22 +470,24 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 		return nil, err
 	}
 
-	largeProviderCfg, _ := c.cfg.Providers.Get(large.ModelCfg.Provider)
+	largeProviderCfg, _ := c.cfg().Providers.Get(large.ModelCfg.Provider)
 	result := NewSessionAgent(SessionAgentOptions{
 		large,
 		small,
 		largeProviderCfg.SystemPromptPrefix,
 		"",
 		isSubAgent,
-		c.cfg.Options.DisableAutoSummarize,
+		c.cfg().Options.DisableAutoSummarize,
 		c.permissions.SkipRequests(),
 		c.sessions,
 		c.messages,
 		nil,
+		c.filetracker,
+		c.nestedContext,
 	})
 
 	c.readyWg.Go(func() error {
-		systemPrompt, err := prompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg)
+		systemPrompt, err := prompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg())
 		if err != nil {
 			return err
 		}
@@ -386,31 +527,51 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 
 	// Get the model name for the agent
 	modelName := ""
-	if modelCfg, ok := c.cfg.Models[agent.Model]; ok {
-		if model := c.cfg.GetModel(modelCfg.Provider, modelCfg.Model); model != nil {
+	if modelCfg, ok := c.cfg().Models[agent.Model]; ok {
+		if model := c.cfg().GetModel(modelCfg.Provider, modelCfg.Model); model != nil {
 			modelName = model.Name
 		}
 	}
 
 	allTools = append(allTools,
-		tools.NewBashTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Options.Attribution, modelName),
+		tools.NewBashTool(c.permissions, c.cfg().WorkingDir(), c.cfg().Options.Attribution, modelName),
 		tools.NewJobOutputTool(),
 		tools.NewJobKillTool(),
-		tools.NewDownloadTool(c.permissions, c.cfg.WorkingDir(), nil),
-		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
-		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
-		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), nil),
-		tools.NewGlobTool(c.cfg.WorkingDir()),
-		tools.NewGrepTool(c.cfg.WorkingDir()),
-		tools.NewLsTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Tools.Ls),
-		tools.NewSourcegraphTool(nil),
+		tools.NewDownloadTool(c.permissions, c.cfg().WorkingDir(), nil),
+		tools.NewEditTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
+		tools.NewMultiEditTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
+		tools.NewNotebookEditTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
+		tools.NewMoveTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
+		tools.NewCopyTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
+		tools.NewDeleteTool(c.lspManager, c.permissions, c.history, c.cfg().WorkingDir()),
+		tools.NewMemoryTool(c.permissions, c.history, c.filetracker, c.cfg().WorkingDir(), c.cfg().Options.InitializeAs),
+		tools.NewFetchTool(c.permissions, c.cfg().WorkingDir(), nil, c.fetchCache),
+		tools.NewGlobTool(c.cfg().WorkingDir()),
+		tools.NewGrepTool(c.cfg().WorkingDir()),
+		tools.NewLsTool(c.permissions, c.cfg().WorkingDir(), c.cfg().Tools.Ls),
+		tools.NewRepoMapTool(c.cfg().WorkingDir(), c.cfg().Tools.RepoMap),
 		tools.NewTodosTool(c.sessions),
-		tools.NewViewTool(c.lspClients, c.permissions, c.filetracker, c.cfg.WorkingDir(), c.cfg.Options.SkillsPaths...),
-		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
+		tools.NewViewTool(c.lspManager, c.permissions, c.filetracker, c.cfg().WorkingDir(), c.cfg().Options.SkillsPaths...),
+		tools.NewWriteTool(c.lspManager, c.permissions, c.history, c.filetracker, c.cfg().WorkingDir()),
 	)
 
-	if len(c.cfg.LSP) > 0 {
-		allTools = append(allTools, tools.NewDiagnosticsTool(c.lspClients), tools.NewReferencesTool(c.lspClients), tools.NewLSPRestartTool(c.lspClients))
+	if c.sourcegraphEnabled() {
+		allTools = append(allTools, tools.NewSourcegraphTool(nil, c.cfg().Tools.Sourcegraph, c.cfg().Resolver(), func() {
+			slog.Warn("Disabling sourcegraph tool, no instance is reachable", "url", c.cfg().Tools.Sourcegraph.URL)
+			go func() {
+				if err := c.UpdateModels(context.Background()); err != nil {
+					slog.Warn("Failed to update agent after disabling sourcegraph tool", "error", err)
+				}
+			}()
+		}))
+	}
+
+	if len(c.cfg().LSP) > 0 {
+		allTools = append(allTools, tools.NewDiagnosticsTool(c.lspManager), tools.NewReferencesTool(c.lspManager), tools.NewLSPRestartTool(c.lspManager.Clients()))
+	}
+
+	if len(c.cfg().MCP) > 0 {
+		allTools = append(allTools, tools.NewReadMCPResourceTool(agent.AllowedMCP))
 	}
 
 	var filteredTools []fantasy.AgentTool
@@ -420,10 +581,11 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 		}
 	}
 
-	for _, tool := range tools.GetMCPTools(c.permissions, c.cfg.WorkingDir()) {
+	var mcpTools []*tools.Tool
+	for _, tool := range tools.GetMCPTools(c.permissions, c.cfg().WorkingDir()) {
 		if agent.AllowedMCP == nil {
 			// No MCP restrictions
-			filteredTools = append(filteredTools, tool)
+			mcpTools = append(mcpTools, tool)
 			continue
 		}
 		if len(agent.AllowedMCP) == 0 {
@@ -437,29 +599,66 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 				continue
 			}
 			if len(tools) == 0 || slices.Contains(tools, tool.MCPToolName()) {
-				filteredTools = append(filteredTools, tool)
+				mcpTools = append(mcpTools, tool)
 			}
 		}
 		slog.Debug("MCP not allowed", "tool", tool.Name(), "agent", agent.Name)
 	}
+	filteredTools = append(filteredTools, c.deferMCPTools(agent, mcpTools)...)
+
 	slices.SortFunc(filteredTools, func(a, b fantasy.AgentTool) int {
 		return strings.Compare(a.Info().Name, b.Info().Name)
 	})
 	return filteredTools, nil
 }
 
+// deferMCPTools marks the MCP tools as deferred when there are more of them
+// than the tool search threshold, except the ones the servers always load,
+// and adds the tool_search tool to load them on demand.
+func (c *coordinator) deferMCPTools(agent config.Agent, mcpTools []*tools.Tool) []fantasy.AgentTool {
+	result := make([]fantasy.AgentTool, 0, len(mcpTools)+1)
+	for _, tool := range mcpTools {
+		result = append(result, tool)
+	}
+	limit := c.cfg().Tools.ToolSearch.Limit()
+	if limit == 0 || len(mcpTools) <= limit || !slices.Contains(agent.AllowedTools, tools.ToolSearchToolName) {
+		return result
+	}
+
+	var deferred []fantasy.AgentTool
+	for _, tool := range mcpTools {
+		if slices.Contains(c.cfg().MCP[tool.MCP()].AlwaysLoaded, tool.MCPToolName()) {
+			continue
+		}
+		tool.SetDeferred(true)
+		deferred = append(deferred, tool)
+	}
+	slog.Debug("Loading MCP tools on demand", "tools", len(mcpTools), "deferred", len(deferred), "threshold", limit)
+	if len(deferred) == 0 {
+		return result
+	}
+	return append(result, tools.NewToolSearchTool(deferred))
+}
+
+// sourcegraphEnabled reports whether the sourcegraph tool should be offered:
+// it's not disabled and its instance wasn't found to be unreachable.
+func (c *coordinator) sourcegraphEnabled() bool {
+	return !slices.Contains(c.cfg().Options.DisabledTools, tools.SourcegraphToolName) &&
+		!tools.SourcegraphUnreachable(c.cfg().Tools.Sourcegraph)
+}
+
 // TODO: when we support multiple agents we need to change this so that we pass in the agent specific model config
 func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Model, Model, error) {
-	largeModelCfg, ok := c.cfg.Models[config.SelectedModelTypeLarge]
+	largeModelCfg, ok := c.cfg().Models[config.SelectedModelTypeLarge]
 	if !ok {
 		return Model{}, Model{}, errors.New("large model not selected")
 	}
-	smallModelCfg, ok := c.cfg.Models[config.SelectedModelTypeSmall]
+	smallModelCfg, ok := c.cfg().Models[config.SelectedModelTypeSmall]
 	if !ok {
 		return Model{}, Model{}, errors.New("small model not selected")
 	}
 
-	largeProviderCfg, ok := c.cfg.Providers.Get(largeModelCfg.Provider)
+	largeProviderCfg, ok := c.cfg().Providers.Get(largeModelCfg.Provider)
 	if !ok {
 		return Model{}, Model{}, errors.New("large model provider not configured")
 	}
@@ -469,7 +668,7 @@ func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Mo
 		return Model{}, Model{}, err
 	}
 
-	smallProviderCfg, ok := c.cfg.Providers.Get(smallModelCfg.Provider)
+	smallProviderCfg, ok := c.cfg().Providers.Get(smallModelCfg.Provider)
 	if !ok {
 		return Model{}, Model{}, errors.New("large model provider not configured")
 	}
@@ -552,7 +751,7 @@ func (c *coordinator) buildAnthropicProvider(baseURL, apiKey string, headers map
 		opts = append(opts, anthropic.WithBaseURL(baseURL))
 	}
 
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, anthropic.WithHTTPClient(httpClient))
 	}
@@ -564,7 +763,7 @@ func (c *coordinator) buildOpenaiProvider(baseURL, apiKey string, headers map[st
 		openai.WithAPIKey(apiKey),
 		openai.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, openai.WithHTTPClient(httpClient))
 	}
@@ -581,7 +780,7 @@ func (c *coordinator) buildOpenrouterProvider(_, apiKey string, headers map[stri
 	opts := []openrouter.Option{
 		openrouter.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, openrouter.WithHTTPClient(httpClient))
 	}
@@ -601,8 +800,8 @@ func (c *coordinator) buildOpenaiCompatProvider(baseURL, apiKey string, headers
 	var httpClient *http.Client
 	if providerID == string(catwalk.InferenceProviderCopilot) {
 		opts = append(opts, openaicompat.WithUseResponsesAPI())
-		httpClient = copilot.NewClient(isSubAgent, c.cfg.Options.Debug)
-	} else if c.cfg.Options.Debug {
+		httpClient = copilot.NewClient(isSubAgent, c.cfg().Options.Debug)
+	} else if c.cfg().Options.Debug {
 		httpClient = log.NewHTTPClient()
 	}
 	if httpClient != nil {
@@ -626,7 +825,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 		azure.WithAPIKey(apiKey),
 		azure.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, azure.WithHTTPClient(httpClient))
 	}
@@ -645,7 +844,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 
 func (c *coordinator) buildBedrockProvider(headers map[string]string) (fantasy.Provider, error) {
 	var opts []bedrock.Option
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, bedrock.WithHTTPClient(httpClient))
 	}
@@ -664,7 +863,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 		google.WithBaseURL(baseURL),
 		google.WithGeminiAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
@@ -676,7 +875,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 
 func (c *coordinator) buildGoogleVertexProvider(headers map[string]string, options map[string]string) (fantasy.Provider, error) {
 	opts := []google.Option{}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
@@ -697,7 +896,7 @@ func (c *coordinator) buildHyperProvider(baseURL, apiKey string) (fantasy.Provid
 		hyper.WithBaseURL(baseURL),
 		hyper.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, hyper.WithHTTPClient(httpClient))
 	}
@@ -738,8 +937,8 @@ func (c *coordinator) buildProvider(providerCfg config.ProviderConfig, model con
 		}
 	}
 
-	apiKey, _ := c.cfg.Resolve(providerCfg.APIKey)
-	baseURL, _ := c.cfg.Resolve(providerCfg.BaseURL)
+	apiKey, _ := c.cfg().Resolve(providerCfg.APIKey)
+	baseURL, _ := c.cfg().Resolve(providerCfg.BaseURL)
 
 	switch providerCfg.Type {
 	case openai.Name:
@@ -814,7 +1013,7 @@ func (c *coordinator) UpdateModels(ctx context.Context) error {
 	}
 	c.currentAgent.SetModels(large, small)
 
-	agentCfg, ok := c.cfg.Agents[config.AgentCoder]
+	agentCfg, ok := c.cfg().Agents[config.AgentCoder]
 	if !ok {
 		return errors.New("coder agent not configured")
 	}
@@ -836,7 +1035,7 @@ func (c *coordinator) QueuedPromptsList(sessionID string) []string {
 }
 
 func (c *coordinator) Summarize(ctx context.Context, sessionID string) error {
-	providerCfg, ok := c.cfg.Providers.Get(c.currentAgent.Model().ModelCfg.Provider)
+	providerCfg, ok := c.cfg().Providers.Get(c.currentAgent.Model().ModelCfg.Provider)
 	if !ok {
 		return errors.New("model provider not configured")
 	}
@@ -849,7 +1048,7 @@ func (c *coordinator) isUnauthorized(err error) bool {
 }
 
 func (c *coordinator) refreshOAuth2Token(ctx context.Context, providerCfg config.ProviderConfig) error {
-	if err := c.cfg.RefreshOAuthToken(ctx, providerCfg.ID); err != nil {
+	if err := c.cfg().RefreshOAuthToken(ctx, providerCfg.ID); err != nil {
 		slog.Error("Failed to refresh OAuth token after 401 error", "provider", providerCfg.ID, "error", err)
 		return err
 	}
@@ -860,14 +1059,14 @@ func (c *coordinator) refreshOAuth2Token(ctx context.Context, providerCfg config
 }
 
 func (c *coordinator) refreshApiKeyTemplate(ctx context.Context, providerCfg config.ProviderConfig) error {
-	newAPIKey, err := c.cfg.Resolve(providerCfg.APIKeyTemplate)
+	newAPIKey, err := c.cfg().Resolve(providerCfg.APIKeyTemplate)
 	if err != nil {
 		slog.Error("Failed to re-resolve API key after 401 error", "provider", providerCfg.ID, "error", err)
 		return err
 	}
 
 	providerCfg.APIKey = newAPIKey
-	c.cfg.Providers.Set(providerCfg.ID, providerCfg)
+	c.cfg().Providers.Set(providerCfg.ID, providerCfg)
 
 	if err := c.UpdateModels(ctx); err != nil {
 		return err
@@ -0,0 +1,214 @@
+package prompt
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/home"
+)
+
+const (
+	// MaxContextTokens is the token budget of the context files loaded in a
+	// prompt, or in a session for nested context files, imports included.
+	MaxContextTokens = 20000
+
+	// maxImportDepth is how deep @path imports are followed.
+	maxImportDepth = 5
+)
+
+var (
+	importRegex     = regexp.MustCompile(`(?:^|\s)@([^\s@` + "`" + `]+)`)
+	inlineCodeRegex = regexp.MustCompile("`[^`]*`")
+)
+
+// contextLoader loads context files and the files they import, once each,
+// within a total token budget.
+type contextLoader struct {
+	workingDir string
+	budget     int // remaining characters, ~4 per token
+	seen       map[string]bool
+}
+
+func newContextLoader(workingDir string, maxTokens int) *contextLoader {
+	return &contextLoader{
+		workingDir: workingDir,
+		budget:     maxTokens * 4,
+		seen:       make(map[string]bool),
+	}
+}
+
+// load returns the context file at path followed by the files it imports.
+func (l *contextLoader) load(path string) []ContextFile {
+	return l.loadDepth(path, 0)
+}
+
+func (l *contextLoader) loadDepth(path string, depth int) []ContextFile {
+	// Context file names differ only in case on case-insensitive file
+	// systems, e.g. AGENTS.md and agents.md.
+	key := strings.ToLower(filepath.Clean(path))
+	if l.seen[key] || l.budget <= 0 {
+		return nil
+	}
+	l.seen[key] = true
+
+	file := processFile(path)
+	if file == nil {
+		return nil
+	}
+	if len(file.Content) > l.budget {
+		file.Content = file.Content[:l.budget] + "\n... (truncated, context files exceed the token budget)"
+	}
+	l.budget -= len(file.Content)
+
+	files := []ContextFile{*file}
+	if depth == maxImportDepth {
+		return files
+	}
+	dir := filepath.Dir(path)
+	for _, imported := range findImports(file.Content, dir) {
+		if !importAllowed(imported, dir, l.workingDir) {
+			slog.Warn("Ignoring context import outside of the project", "file", path, "import", imported)
+			continue
+		}
+		files = append(files, l.loadDepth(imported, depth+1)...)
+	}
+	return files
+}
+
+// findImports returns the existing files referenced with @path in content,
+// resolved relative to dir. References in code are ignored.
+func findImports(content, dir string) []string {
+	var imports []string
+	inFence := false
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = inlineCodeRegex.ReplaceAllString(line, "")
+		for _, match := range importRegex.FindAllStringSubmatch(line, -1) {
+			path := strings.TrimRight(match[1], ".,;:!?)]}'\"")
+			path = home.Long(path)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				imports = append(imports, path)
+			}
+		}
+	}
+	return imports
+}
+
+// importAllowed reports whether a context file in dir can import the file at
+// path. Only files under the working directory or under dir can be imported,
+// so a context file can't pull files like credentials into the prompt.
+func importAllowed(path, dir, workingDir string) bool {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range []string{workingDir, dir} {
+		if root == "" {
+			continue
+		}
+		root, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, real); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NestedContext finds the context files of the subdirectories of the
+// working directory, e.g. per-package AGENTS.md files in a monorepo, as the
+// agent works on files under them. Each is loaded once per session.
+type NestedContext struct {
+	workingDir string
+	names      []string
+	sessions   *csync.Map[string, *nestedSession]
+}
+
+type nestedSession struct {
+	mu     sync.Mutex
+	loader *contextLoader
+	dirs   map[string]bool
+	files  []ContextFile
+}
+
+// NewNestedContext creates a [NestedContext] looking for the context files
+// in contextPaths that are plain file names, such as AGENTS.md.
+func NewNestedContext(workingDir string, contextPaths []string) *NestedContext {
+	var names []string
+	for _, p := range contextPaths {
+		if p == "" || strings.ContainsAny(p, `/\$~`) {
+			continue
+		}
+		names = append(names, p)
+	}
+	return &NestedContext{
+		workingDir: filepath.Clean(workingDir),
+		names:      names,
+		sessions:   csync.NewMap[string, *nestedSession](),
+	}
+}
+
+// Load loads the context files of the directories between the working
+// directory and path, and returns the ones that weren't loaded yet in the
+// session. The context files of the working directory itself are part of the
+// system prompt already.
+func (n *NestedContext) Load(sessionID, path string) []ContextFile {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(n.workingDir, path)
+	}
+	rel, err := filepath.Rel(n.workingDir, filepath.Dir(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	s := n.sessions.GetOrSet(sessionID, func() *nestedSession {
+		return &nestedSession{
+			loader: newContextLoader(n.workingDir, MaxContextTokens),
+			dirs:   make(map[string]bool),
+		}
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var loaded []ContextFile
+	dir := n.workingDir
+	for part := range strings.SplitSeq(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		if s.dirs[dir] {
+			continue
+		}
+		s.dirs[dir] = true
+		for _, name := range n.names {
+			loaded = append(loaded, s.loader.load(filepath.Join(dir, name))...)
+		}
+	}
+	s.files = append(s.files, loaded...)
+	return loaded
+}
+
+// Loaded returns the context files loaded in the session so far.
+func (n *NestedContext) Loaded(sessionID string) []ContextFile {
+	s, ok := n.sessions.Get(sessionID)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ContextFile(nil), s.files...)
+}
@@ -0,0 +1,98 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func contextPaths(files []ContextFile) []string {
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func TestContextLoaderImports(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "AGENTS.md"), "Read @docs/style.md and @missing.md.\n```\n@docs/ignored.md\n```\nNot `@docs/ignored.md` either, nor me@example.com.")
+	writeFile(t, filepath.Join(dir, "docs", "style.md"), "Style, see @../AGENTS.md and @testing.md")
+	writeFile(t, filepath.Join(dir, "docs", "testing.md"), "Testing")
+	writeFile(t, filepath.Join(dir, "docs", "ignored.md"), "Ignored")
+
+	files := newContextLoader(dir, MaxContextTokens).load(filepath.Join(dir, "AGENTS.md"))
+	require.Equal(t, []string{
+		filepath.Join(dir, "AGENTS.md"),
+		filepath.Join(dir, "docs", "style.md"),
+		filepath.Join(dir, "docs", "testing.md"),
+	}, contextPaths(files))
+}
+
+func TestContextLoaderImportsOutsideProject(t *testing.T) {
+	t.Parallel()
+
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "secret"), "secret")
+	global := filepath.Join(outside, "config")
+	writeFile(t, filepath.Join(global, "CRUSH.md"), "Global, see @rules.md")
+	writeFile(t, filepath.Join(global, "rules.md"), "Rules")
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "AGENTS.md"), "See @"+filepath.Join(outside, "secret")+" and @link.md")
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "link.md")))
+
+	loader := newContextLoader(dir, MaxContextTokens)
+	require.Equal(t, []string{filepath.Join(dir, "AGENTS.md")}, contextPaths(loader.load(filepath.Join(dir, "AGENTS.md"))))
+	require.Equal(t, []string{
+		filepath.Join(global, "CRUSH.md"),
+		filepath.Join(global, "rules.md"),
+	}, contextPaths(loader.load(filepath.Join(global, "CRUSH.md"))), "files can import the ones next to them")
+}
+
+func TestContextLoaderBudget(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "AGENTS.md"), "12345678 @other.md")
+	writeFile(t, filepath.Join(dir, "other.md"), "other")
+
+	files := newContextLoader(dir, 2).load(filepath.Join(dir, "AGENTS.md"))
+	require.Len(t, files, 1)
+	require.Contains(t, files[0].Content, "truncated")
+}
+
+func TestNestedContext(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "AGENTS.md"), "root")
+	writeFile(t, filepath.Join(dir, "pkg", "AGENTS.md"), "pkg")
+	writeFile(t, filepath.Join(dir, "pkg", "sub", "CRUSH.md"), "sub")
+	writeFile(t, filepath.Join(dir, "pkg", "sub", "main.go"), "package main")
+
+	nested := NewNestedContext(dir, []string{".cursor/rules/", "AGENTS.md", "CRUSH.md"})
+
+	require.Empty(t, nested.Load("s", filepath.Join(dir, "main.go")), "root context is in the system prompt")
+	require.Empty(t, nested.Load("s", filepath.Join(dir, "..", "elsewhere.go")))
+
+	loaded := nested.Load("s", filepath.Join("pkg", "sub", "main.go"))
+	require.Equal(t, []string{
+		filepath.Join(dir, "pkg", "AGENTS.md"),
+		filepath.Join(dir, "pkg", "sub", "CRUSH.md"),
+	}, contextPaths(loaded))
+
+	require.Empty(t, nested.Load("s", filepath.Join(dir, "pkg", "other.go")), "loaded once per session")
+	require.Len(t, nested.Loaded("s"), 2)
+	require.Len(t, nested.Load("other", filepath.Join(dir, "pkg", "other.go")), 1)
+}
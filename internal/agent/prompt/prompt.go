@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/repomap"
 	"github.com/charmbracelet/crush/internal/shell"
 	"github.com/charmbracelet/crush/internal/skills"
 )
@@ -24,6 +26,8 @@ type Prompt struct {
 	now        func() time.Time
 	platform   string
 	workingDir string
+	// allowedSkills limits the skills in the prompt, nil allows them all.
+	allowedSkills []string
 }
 
 type PromptDat struct {
@@ -37,6 +41,7 @@ type PromptDat struct {
 	GitStatus     string
 	ContextFiles  []ContextFile
 	AvailSkillXML string
+	RepoMap       string
 }
 
 type ContextFile struct {
@@ -64,6 +69,12 @@ func WithWorkingDir(workingDir string) Option {
 	}
 }
 
+func WithAllowedSkills(allowed []string) Option {
+	return func(p *Prompt) {
+		p.allowedSkills = allowed
+	}
+}
+
 func NewPrompt(name, promptTemplate string, opts ...Option) (*Prompt, error) {
 	p := &Prompt{
 		name:     name,
@@ -104,7 +115,7 @@ func processFile(filePath string) *ContextFile {
 	}
 }
 
-func processContextPath(p string, cfg config.Config) []ContextFile {
+func processContextPath(p string, cfg config.Config, loader *contextLoader) []ContextFile {
 	var contexts []ContextFile
 	fullPath := p
 	if !filepath.IsAbs(p) {
@@ -120,17 +131,12 @@ func processContextPath(p string, cfg config.Config) []ContextFile {
 				return err
 			}
 			if !d.IsDir() {
-				if result := processFile(path); result != nil {
-					contexts = append(contexts, *result)
-				}
+				contexts = append(contexts, loader.load(path)...)
 			}
 			return nil
 		})
 	} else {
-		result := processFile(fullPath)
-		if result != nil {
-			contexts = append(contexts, *result)
-		}
+		contexts = append(contexts, loader.load(fullPath)...)
 	}
 	return contexts
 }
@@ -152,16 +158,10 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 	workingDir := cmp.Or(p.workingDir, cfg.WorkingDir())
 	platform := cmp.Or(p.platform, runtime.GOOS)
 
-	files := map[string][]ContextFile{}
-
+	var contextFiles []ContextFile
+	loader := newContextLoader(workingDir, MaxContextTokens)
 	for _, pth := range cfg.Options.ContextPaths {
-		expanded := expandPath(pth, cfg)
-		pathKey := strings.ToLower(expanded)
-		if _, ok := files[pathKey]; ok {
-			continue
-		}
-		content := processContextPath(expanded, cfg)
-		files[pathKey] = content
+		contextFiles = append(contextFiles, processContextPath(expandPath(pth, cfg), cfg, loader)...)
 	}
 
 	// Discover and load skills metadata.
@@ -171,7 +171,7 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 		for _, pth := range cfg.Options.SkillsPaths {
 			expandedPaths = append(expandedPaths, expandPath(pth, cfg))
 		}
-		if discoveredSkills := skills.Discover(expandedPaths); len(discoveredSkills) > 0 {
+		if discoveredSkills := skills.Filter(skills.Discover(expandedPaths), p.allowedSkills); len(discoveredSkills) > 0 {
 			availSkillXML = skills.ToPromptXML(discoveredSkills)
 		}
 	}
@@ -195,9 +195,16 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 		}
 	}
 
-	for _, contextFiles := range files {
-		data.ContextFiles = append(data.ContextFiles, contextFiles...)
+	if cfg.Tools.RepoMap.SystemPrompt {
+		_, maxTokens := cfg.Tools.RepoMap.Limits()
+		repoMap, err := repomap.Build(ctx, workingDir, repomap.Options{MaxTokens: maxTokens})
+		if err != nil {
+			slog.Warn("Failed to build repository map", "error", err)
+		}
+		data.RepoMap = strings.TrimSpace(repoMap)
 	}
+
+	data.ContextFiles = contextFiles
 	return data, nil
 }
 
@@ -380,6 +380,14 @@ Skills are activated by reading their location path. Follow the skill's instruct
 If a skill mentions scripts, references, or assets, they are placed in the same folder as the skill itself (e.g., scripts/, references/, assets/ subdirectories within the skill's folder).
 </skills_usage>
 {{end}}
+{{- if .RepoMap}}
+
+<repo_map>
+Outline of the most used and recently edited files of the repository, with the line of each symbol. Use the repo_map tool to map a single directory in more detail.
+
+{{.RepoMap}}
+</repo_map>
+{{end}}
 
 {{if .ContextFiles}}
 <memory>
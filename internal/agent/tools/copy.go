@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type CopyParams struct {
+	SourcePath      string `json:"source_path" description:"The path of the file or directory to copy"`
+	DestinationPath string `json:"destination_path" description:"The path of the copy"`
+}
+
+type CopyPermissionsParams struct {
+	SourcePath      string `json:"source_path"`
+	DestinationPath string `json:"destination_path"`
+}
+
+type CopyResponseMetadata struct {
+	SourcePath      string `json:"source_path"`
+	DestinationPath string `json:"destination_path"`
+	Files           int    `json:"files"`
+}
+
+const CopyToolName = "copy"
+
+//go:embed copy.md
+var copyDescription []byte
+
+func NewCopyTool(
+	lspManager *lsp.Manager,
+	permissions permission.Service,
+	files history.Service,
+	filetracker filetracker.Service,
+	workingDir string,
+) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		CopyToolName,
+		string(copyDescription),
+		func(ctx context.Context, params CopyParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.SourcePath == "" {
+				return fantasy.NewTextErrorResponse("source_path is required"), nil
+			}
+			if params.DestinationPath == "" {
+				return fantasy.NewTextErrorResponse("destination_path is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for copying files")
+			}
+
+			source := filepathext.SmartJoin(workingDir, params.SourcePath)
+			destination := filepathext.SmartJoin(workingDir, params.DestinationPath)
+			if resp, ok := checkFileOpPaths(source, destination); !ok {
+				return resp, nil
+			}
+
+			paths, err := listFileOpFiles(source)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error listing files: %s", err)), nil
+			}
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        fsext.PathOrPrefix(destination, workingDir),
+					ToolCallID:  call.ID,
+					ToolName:    CopyToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("Copy %s to %s", source, destination),
+					Params: CopyPermissionsParams{
+						SourcePath:      source,
+						DestinationPath: destination,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to create parent directories: %w", err)
+			}
+			if err := copyPath(source, destination); err != nil {
+				_ = os.RemoveAll(destination)
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to copy %s: %s", source, err)), nil
+			}
+
+			created := make([]string, 0, len(paths))
+			for _, path := range paths {
+				target := filepath.Join(destination, mustRel(source, path))
+				if content, ok := readHistoryContent(target); ok {
+					recordFileVersion(ctx, files, sessionID, target, "", content)
+				}
+				if wasRead(ctx, filetracker, sessionID, path) {
+					filetracker.RecordRead(ctx, sessionID, target)
+				}
+				created = append(created, target)
+			}
+			notifyLSPsFileOperations(ctx, lspManager, created, nil)
+
+			result := fmt.Sprintf("<result>\nCopied %s to %s\n</result>\n", source, destination)
+			if len(created) == 1 && created[0] == destination {
+				notifyLSPs(ctx, lspManager, destination)
+				result += getDiagnostics(destination, lspManager.Clients())
+			}
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(result),
+				CopyResponseMetadata{
+					SourcePath:      source,
+					DestinationPath: destination,
+					Files:           len(created),
+				},
+			), nil
+		})
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type DeleteParams struct {
+	Path      string `json:"path" description:"The path of the file or directory to delete"`
+	Recursive bool   `json:"recursive,omitempty" description:"Delete a non-empty directory and all its contents (default false)"`
+}
+
+type DeletePermissionsParams struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive"`
+}
+
+type DeleteResponseMetadata struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+}
+
+const DeleteToolName = "delete"
+
+//go:embed delete.md
+var deleteDescription []byte
+
+func NewDeleteTool(
+	lspManager *lsp.Manager,
+	permissions permission.Service,
+	files history.Service,
+	workingDir string,
+) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		DeleteToolName,
+		string(deleteDescription),
+		func(ctx context.Context, params DeleteParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.Path == "" {
+				return fantasy.NewTextErrorResponse("path is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for deleting files")
+			}
+
+			path := filepathext.SmartJoin(workingDir, params.Path)
+			if isSubPath(path, workingDir) {
+				return fantasy.NewTextErrorResponse("cannot delete the working directory or one of its parents"), nil
+			}
+
+			info, err := os.Lstat(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("path not found: %s", path)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to access %s: %w", path, err)
+			}
+			if info.IsDir() && !params.Recursive {
+				entries, err := os.ReadDir(path)
+				if err != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("failed to read directory: %w", err)
+				}
+				if len(entries) > 0 {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("directory %s is not empty, set recursive to true to delete it with its contents", path)), nil
+				}
+			}
+
+			paths, err := listFileOpFiles(path)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error listing files: %s", err)), nil
+			}
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        fsext.PathOrPrefix(path, workingDir),
+					ToolCallID:  call.ID,
+					ToolName:    DeleteToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("Delete %s", path),
+					Params: DeletePermissionsParams{
+						Path:      path,
+						Recursive: params.Recursive,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			// Keep the content of the deleted files, so they can be restored
+			// from the history.
+			contents := make(map[string]string, len(paths))
+			for _, p := range paths {
+				if content, ok := readHistoryContent(p); ok {
+					contents[p] = content
+				}
+			}
+
+			if err := os.RemoveAll(path); err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to delete %s: %s", path, err)), nil
+			}
+
+			for p, content := range contents {
+				recordFileVersion(ctx, files, sessionID, p, content, "")
+			}
+			notifyLSPsFileOperations(ctx, lspManager, nil, paths)
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(fmt.Sprintf("<result>\nDeleted %s\n</result>\n", path)),
+				DeleteResponseMetadata{
+					Path:  path,
+					Files: len(paths),
+				},
+			), nil
+		})
+}
@@ -24,25 +24,27 @@ const DiagnosticsToolName = "lsp_diagnostics"
 //go:embed diagnostics.md
 var diagnosticsDescription []byte
 
-func NewDiagnosticsTool(lspClients *csync.Map[string, *lsp.Client]) fantasy.AgentTool {
+func NewDiagnosticsTool(lspManager *lsp.Manager) fantasy.AgentTool {
 	return fantasy.NewAgentTool(
 		DiagnosticsToolName,
 		string(diagnosticsDescription),
 		func(ctx context.Context, params DiagnosticsParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
-			if lspClients.Len() == 0 {
+			lspManager.Start(params.FilePath)
+			if lspManager.Clients().Len() == 0 {
 				return fantasy.NewTextErrorResponse("no LSP clients available"), nil
 			}
-			notifyLSPs(ctx, lspClients, params.FilePath)
-			output := getDiagnostics(params.FilePath, lspClients)
+			notifyLSPs(ctx, lspManager, params.FilePath)
+			output := getDiagnostics(params.FilePath, lspManager.Clients())
 			return fantasy.NewTextResponse(output), nil
 		})
 }
 
-func notifyLSPs(ctx context.Context, lsps *csync.Map[string, *lsp.Client], filepath string) {
+func notifyLSPs(ctx context.Context, lspManager *lsp.Manager, filepath string) {
 	if filepath == "" {
 		return
 	}
-	for client := range lsps.Seq() {
+	lspManager.Start(filepath)
+	for client := range lspManager.Clients().Seq() {
 		if !client.HandlesFile(filepath) {
 			continue
 		}
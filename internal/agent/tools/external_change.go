@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filetracker"
+)
+
+// maxExternalDiffLength is the maximum length of the diff shown when a file
+// was changed outside of the session.
+const maxExternalDiffLength = 10000
+
+// checkExternalChange refuses to modify a file that was changed on disk since
+// the session last read it, e.g. by the user in their editor, and shows the
+// agent what changed.
+func checkExternalChange(ctx context.Context, tracker filetracker.Service, sessionID, path, workingDir string) (fantasy.ToolResponse, bool) {
+	change, ok := tracker.ExternalChange(ctx, sessionID, path)
+	if !ok {
+		return fantasy.ToolResponse{}, true
+	}
+
+	var sb strings.Builder
+	if change.Deleted {
+		fmt.Fprintf(&sb, "file %s was deleted since it was last read.", path)
+		return fantasy.NewTextErrorResponse(sb.String()), false
+	}
+	fmt.Fprintf(&sb, "file %s was modified outside of the agent since it was last read.", path)
+	if change.Text {
+		d, _, _ := diff.GenerateDiff(change.Before, change.After, strings.TrimPrefix(path, workingDir))
+		if len(d) > maxExternalDiffLength {
+			d = d[:maxExternalDiffLength] + "\n... (diff truncated)\n"
+		}
+		sb.WriteString(" These are the changes:\n\n<diff>\n")
+		sb.WriteString(d)
+		sb.WriteString("</diff>\n\n")
+	} else {
+		sb.WriteString(" ")
+	}
+	sb.WriteString("Read the file again before modifying it, and keep these changes.")
+	return fantasy.NewTextErrorResponse(sb.String()), false
+}
@@ -3,11 +3,12 @@ package tools
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"charm.land/fantasy"
@@ -21,7 +22,7 @@ const FetchToolName = "fetch"
 //go:embed fetch.md
 var fetchDescription []byte
 
-func NewFetchTool(permissions permission.Service, workingDir string, client *http.Client) fantasy.AgentTool {
+func NewFetchTool(permissions permission.Service, workingDir string, client *http.Client, cache *FetchCache) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -94,79 +95,88 @@ func NewFetchTool(permissions permission.Service, workingDir string, client *htt
 
 			req.Header.Set("User-Agent", "crush/1.0")
 
-			resp, err := client.Do(req)
+			result, err := cache.Fetch(client, req, format, func(body []byte, contentType string) (string, error) {
+				return convertFetchedContent(body, contentType, format)
+			})
 			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("failed to fetch URL: %w", err)
+				if requestCtx.Err() != nil {
+					return fantasy.ToolResponse{}, err
+				}
+				return fantasy.NewTextErrorResponse(fetchErrorMessage(err)), nil
 			}
-			defer resp.Body.Close()
+			content := result.Content
 
-			if resp.StatusCode != http.StatusOK {
-				return fantasy.NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", resp.StatusCode)), nil
+			// truncate content if it exceeds max read size
+			if int64(len(content)) > MaxReadSize {
+				content = content[:MaxReadSize]
+				content += fmt.Sprintf("\n\n[Content truncated to %d bytes]", MaxReadSize)
 			}
 
-			// maxFetchResponseSizeBytes is the maximum size of response body to read (5MB)
-			const maxFetchResponseSizeBytes = int64(5 * 1024 * 1024)
+			return fantasy.NewTextResponse(content), nil
+		})
+}
 
-			maxSize := maxFetchResponseSizeBytes
-			body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+// convertFetchedContent converts a response body to the requested format.
+func convertFetchedContent(body []byte, contentType, format string) (string, error) {
+	content, isHTML, err := convertFetchedBody(body, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "text":
+		if isHTML {
+			text, err := extractTextFromHTML(content)
 			if err != nil {
-				return fantasy.NewTextErrorResponse("Failed to read response body: " + err.Error()), nil
+				return "", fmt.Errorf("failed to extract text from HTML: %w", err)
 			}
+			content = text
+		}
 
-			content := string(body)
-
-			validUTF8 := utf8.ValidString(content)
-			if !validUTF8 {
-				return fantasy.NewTextErrorResponse("Response content is not valid UTF-8"), nil
+	case "markdown":
+		if isHTML {
+			markdown, err := convertHTMLToMarkdown(content)
+			if err != nil {
+				return "", fmt.Errorf("failed to convert HTML to Markdown: %w", err)
 			}
-			contentType := resp.Header.Get("Content-Type")
-
-			switch format {
-			case "text":
-				if strings.Contains(contentType, "text/html") {
-					text, err := extractTextFromHTML(content)
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to extract text from HTML: " + err.Error()), nil
-					}
-					content = text
-				}
+			content = markdown
+		}
 
-			case "markdown":
-				if strings.Contains(contentType, "text/html") {
-					markdown, err := convertHTMLToMarkdown(content)
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to convert HTML to Markdown: " + err.Error()), nil
-					}
-					content = markdown
-				}
+		content = "```\n" + content + "\n```"
 
-				content = "```\n" + content + "\n```"
-
-			case "html":
-				// return only the body of the HTML document
-				if strings.Contains(contentType, "text/html") {
-					doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to parse HTML: " + err.Error()), nil
-					}
-					body, err := doc.Find("body").Html()
-					if err != nil {
-						return fantasy.NewTextErrorResponse("Failed to extract body from HTML: " + err.Error()), nil
-					}
-					if body == "" {
-						return fantasy.NewTextErrorResponse("No body content found in HTML"), nil
-					}
-					content = "<html>\n<body>\n" + body + "\n</body>\n</html>"
-				}
+	case "html":
+		// return only the body of the HTML document
+		if isHTML {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+			if err != nil {
+				return "", fmt.Errorf("failed to parse HTML: %w", err)
 			}
-			// truncate content if it exceeds max read size
-			if int64(len(content)) > MaxReadSize {
-				content = content[:MaxReadSize]
-				content += fmt.Sprintf("\n\n[Content truncated to %d bytes]", MaxReadSize)
+			body, err := doc.Find("body").Html()
+			if err != nil {
+				return "", fmt.Errorf("failed to extract body from HTML: %w", err)
 			}
+			if body == "" {
+				return "", errors.New("no body content found in HTML")
+			}
+			content = "<html>\n<body>\n" + body + "\n</body>\n</html>"
+		}
+	}
+	return content, nil
+}
 
-			return fantasy.NewTextResponse(content), nil
-		})
+// fetchErrorMessage returns a message suitable for the model for a failed
+// fetch.
+func fetchErrorMessage(err error) string {
+	var statusErr *fetchStatusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("Request failed with status code: %d", statusErr.StatusCode)
+	}
+	msg := err.Error()
+	if msg == "" {
+		return "Request failed"
+	}
+	r, size := utf8.DecodeRuneInString(msg)
+	return string(unicode.ToUpper(r)) + msg[size:]
 }
 
 func extractTextFromHTML(html string) (string, error) {
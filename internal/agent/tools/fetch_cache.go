@@ -0,0 +1,312 @@
+package tools
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFetchCacheTTL     = time.Hour
+	defaultFetchCacheMaxSize = 50 * 1024 * 1024
+)
+
+// FetchCache is an on-disk cache of fetched pages, shared by the fetch,
+// web_fetch and agentic_fetch tools.
+//
+// Entries are keyed by URL and output format and hold the converted content,
+// so repeated fetches of the same page skip both the request and the
+// conversion. Stale entries with an ETag or Last-Modified validator are
+// revalidated with a conditional request instead of being fetched again.
+//
+// A nil *FetchCache is valid and caches nothing.
+type FetchCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewFetchCache creates a cache in dir. Entries without caching headers are
+// considered fresh for ttl, and the oldest entries are evicted once the cache
+// grows past maxSize bytes.
+func NewFetchCache(dir string, ttl time.Duration, maxSize int64) *FetchCache {
+	if ttl <= 0 {
+		ttl = defaultFetchCacheTTL
+	}
+	if maxSize <= 0 {
+		maxSize = defaultFetchCacheMaxSize
+	}
+	return &FetchCache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+	}
+}
+
+// fetchCacheEntry is a cached, converted response.
+type fetchCacheEntry struct {
+	URL          string    `json:"url"`
+	Format       string    `json:"format"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Expires      time.Time `json:"expires"`
+	NoCache      bool      `json:"no_cache,omitempty"`
+	Content      string    `json:"content"`
+}
+
+func (e *fetchCacheEntry) canRevalidate() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// fetchedContent is the result of [FetchCache.Fetch].
+type fetchedContent struct {
+	Content     string
+	ContentType string
+	Cached      bool
+}
+
+// fetchStatusError is returned when the server responds with an unexpected
+// status code.
+type fetchStatusError struct {
+	StatusCode int
+}
+
+func (e *fetchStatusError) Error() string {
+	return fmt.Sprintf("request failed with status code: %d", e.StatusCode)
+}
+
+// Fetch executes req, converting the response body with convert. Fresh
+// cached content for the request URL and format is returned without any
+// request being made.
+func (c *FetchCache) Fetch(
+	client *http.Client,
+	req *http.Request,
+	format string,
+	convert func(body []byte, contentType string) (string, error),
+) (fetchedContent, error) {
+	key := c.key(req.URL.String(), format)
+	cached := c.get(key)
+	if cached != nil {
+		if !cached.NoCache && c.now().Before(cached.Expires) {
+			return fetchedContent{Content: cached.Content, ContentType: cached.ContentType, Cached: true}, nil
+		}
+		if cached.canRevalidate() {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fetchedContent{}, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.update(key, cached, resp.Header)
+		return fetchedContent{Content: cached.Content, ContentType: cached.ContentType, Cached: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fetchedContent{}, &fetchStatusError{StatusCode: resp.StatusCode}
+	}
+
+	// maxFetchResponseSizeBytes is the maximum size of response body to read (5MB)
+	const maxFetchResponseSizeBytes = int64(5 * 1024 * 1024)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchResponseSizeBytes))
+	if err != nil {
+		return fetchedContent{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	content, err := convert(body, contentType)
+	if err != nil {
+		return fetchedContent{}, err
+	}
+
+	c.update(key, &fetchCacheEntry{
+		URL:         req.URL.String(),
+		Format:      format,
+		ContentType: contentType,
+		Content:     content,
+	}, resp.Header)
+
+	return fetchedContent{Content: content, ContentType: contentType}, nil
+}
+
+// update stores entry, refreshing its validators and expiry from the given
+// response headers.
+func (c *FetchCache) update(key string, entry *fetchCacheEntry, header http.Header) {
+	if c == nil {
+		return
+	}
+
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		c.remove(key)
+		return
+	}
+	entry.ETag = cmp.Or(header.Get("ETag"), entry.ETag)
+	entry.LastModified = cmp.Or(header.Get("Last-Modified"), entry.LastModified)
+	_, entry.NoCache = directives["no-cache"]
+
+	now := c.now()
+	entry.Expires = now.Add(c.ttl)
+	if maxAge, ok := directives["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			entry.Expires = now.Add(time.Duration(seconds) * time.Second)
+		}
+	} else if expires := header.Get("Expires"); expires != "" {
+		if t, err := http.ParseTime(expires); err == nil {
+			entry.Expires = t
+		}
+	}
+
+	// Entries that expire right away are only worth keeping if they can be
+	// revalidated.
+	if (entry.NoCache || !now.Before(entry.Expires)) && !entry.canRevalidate() {
+		c.remove(key)
+		return
+	}
+
+	c.put(key, entry)
+}
+
+func (c *FetchCache) key(url, format string) string {
+	sum := sha256.Sum256([]byte(format + "\x00" + url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *FetchCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *FetchCache) get(key string) *fetchCacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry fetchCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		slog.Debug("Ignoring invalid fetch cache entry", "key", key, "error", err)
+		return nil
+	}
+	return &entry
+}
+
+func (c *FetchCache) put(key string, entry *fetchCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > c.maxSize {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		slog.Warn("Failed to create fetch cache directory", "error", err)
+		return
+	}
+	if err := os.WriteFile(c.path(key), data, 0o600); err != nil {
+		slog.Warn("Failed to write fetch cache entry", "error", err)
+		return
+	}
+	c.prune()
+}
+
+func (c *FetchCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = os.Remove(c.path(key))
+}
+
+// prune evicts the least recently written entries until the cache fits
+// within its size limit. It must be called with c.mu held.
+func (c *FetchCache) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		files []file
+		total int64
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{
+			path:    filepath.Join(c.dir, entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		total += info.Size()
+	}
+	if total <= c.maxSize {
+		return
+	}
+
+	slices.SortFunc(files, func(a, b file) int {
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+}
+
+// parseCacheControl parses a Cache-Control header into its directives.
+func parseCacheControl(header string) map[string]string {
+	directives := make(map[string]string)
+	for part := range strings.SplitSeq(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
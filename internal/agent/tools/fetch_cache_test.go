@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFetchCache(t *testing.T, maxSize int64) (*FetchCache, *time.Time) {
+	t.Helper()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewFetchCache(t.TempDir(), time.Minute, maxSize)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func fetchTestURL(t *testing.T, cache *FetchCache, url, format string) fetchedContent {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	result, err := cache.Fetch(http.DefaultClient, req, format, func(body []byte, contentType string) (string, error) {
+		return format + ":" + string(body), nil
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestFetchCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("serves fresh entries", func(t *testing.T) {
+		t.Parallel()
+
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			_, _ = io.WriteString(w, "hello")
+		}))
+		t.Cleanup(srv.Close)
+
+		cache, now := newTestFetchCache(t, 0)
+
+		first := fetchTestURL(t, cache, srv.URL, "text")
+		require.Equal(t, "text:hello", first.Content)
+		require.False(t, first.Cached)
+		second := fetchTestURL(t, cache, srv.URL, "text")
+		require.Equal(t, "text:hello", second.Content)
+		require.True(t, second.Cached)
+		require.Equal(t, int32(1), hits.Load())
+
+		// Formats are cached separately.
+		require.Equal(t, "html:hello", fetchTestURL(t, cache, srv.URL, "html").Content)
+		require.Equal(t, int32(2), hits.Load())
+
+		// Entries without validators are fetched again once expired.
+		*now = now.Add(2 * time.Minute)
+		require.False(t, fetchTestURL(t, cache, srv.URL, "text").Cached)
+		require.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("revalidates stale entries", func(t *testing.T) {
+		t.Parallel()
+
+		var hits, notModified atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "max-age=10")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = io.WriteString(w, "hello")
+		}))
+		t.Cleanup(srv.Close)
+
+		cache, now := newTestFetchCache(t, 0)
+
+		require.False(t, fetchTestURL(t, cache, srv.URL, "text").Cached)
+
+		// max-age takes precedence over the default TTL.
+		*now = now.Add(30 * time.Second)
+		result := fetchTestURL(t, cache, srv.URL, "text")
+		require.True(t, result.Cached)
+		require.Equal(t, "text:hello", result.Content)
+		require.Equal(t, int32(2), hits.Load())
+		require.Equal(t, int32(1), notModified.Load())
+
+		// The 304 refreshed the entry.
+		require.True(t, fetchTestURL(t, cache, srv.URL, "text").Cached)
+		require.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("respects no-store", func(t *testing.T) {
+		t.Parallel()
+
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			w.Header().Set("Cache-Control", "no-store")
+			_, _ = io.WriteString(w, "secret")
+		}))
+		t.Cleanup(srv.Close)
+
+		cache, _ := newTestFetchCache(t, 0)
+
+		require.False(t, fetchTestURL(t, cache, srv.URL, "text").Cached)
+		require.False(t, fetchTestURL(t, cache, srv.URL, "text").Cached)
+		require.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("evicts entries over the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.URL.Path)
+		}))
+		t.Cleanup(srv.Close)
+
+		cache, _ := newTestFetchCache(t, 400)
+		for _, path := range []string{"/a", "/b", "/c", "/d"} {
+			fetchTestURL(t, cache, srv.URL+path, "text")
+		}
+
+		entries, err := filepath.Glob(filepath.Join(cache.dir, "*.json"))
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		require.Less(t, len(entries), 4)
+		require.True(t, fetchTestURL(t, cache, srv.URL+"/d", "text").Cached)
+	})
+
+	t.Run("nil cache", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "hello")
+		}))
+		t.Cleanup(srv.Close)
+
+		var cache *FetchCache
+		for range 2 {
+			result := fetchTestURL(t, cache, srv.URL, "text")
+			require.Equal(t, "text:hello", result.Content)
+			require.False(t, result.Cached)
+		}
+	})
+}
+
+func TestConvertFetchedBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+		wantHTML    bool
+		wantErr     bool
+	}{
+		{name: "html", body: "<p>hi</p>", contentType: "text/html; charset=utf-8", want: "<p>hi</p>", wantHTML: true},
+		{name: "sniffed html", body: "<!DOCTYPE html><p>hi</p>", want: "<!DOCTYPE html><p>hi</p>", wantHTML: true},
+		{name: "json", body: `{"a":1}`, contentType: "application/json", want: "{\n  \"a\": 1\n}\n"},
+		{name: "json suffix", body: `[1]`, contentType: "application/vnd.api+json", want: "[\n  1\n]\n"},
+		{name: "invalid json", body: `{"a"`, contentType: "application/json", want: `{"a"`},
+		{name: "plain text", body: "just text", contentType: "text/plain", want: "just text"},
+		{name: "binary", body: "\xff\xfe\x00", contentType: "image/png", wantErr: true},
+		{name: "broken pdf", body: "%PDF-1.4 garbage", contentType: "application/octet-stream", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, isHTML, err := convertFetchedBody([]byte(tt.body), tt.contentType)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantHTML, isHTML)
+		})
+	}
+}
+
+func TestFetchErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Request failed with status code: 404", fetchErrorMessage(&fetchStatusError{StatusCode: 404}))
+	require.Equal(t, "Timeout", fetchErrorMessage(errors.New("timeout")))
+	require.Equal(t, "Échec", fetchErrorMessage(errors.New("échec")))
+	require.Equal(t, "Request failed", fetchErrorMessage(errors.New("")))
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -22,7 +21,8 @@ const BrowserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/
 var multipleNewlinesRe = regexp.MustCompile(`\n{3,}`)
 
 // FetchURLAndConvert fetches a URL and converts HTML content to markdown.
-func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (string, error) {
+// Responses are served from and stored in cache, which may be nil.
+func FetchURLAndConvert(ctx context.Context, client *http.Client, cache *FetchCache, url string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -33,49 +33,64 @@ func FetchURLAndConvert(ctx context.Context, client *http.Client, url string) (s
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("request failed with status code: %d", resp.StatusCode)
-	}
+	result, err := cache.Fetch(client, req, "readable", func(body []byte, contentType string) (string, error) {
+		content, isHTML, err := convertFetchedBody(body, contentType)
+		if err != nil || !isHTML {
+			return content, err
+		}
 
-	maxSize := int64(5 * 1024 * 1024) // 5MB
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+		// Convert HTML to markdown for better AI processing, removing noisy
+		// elements first.
+		markdown, err := ConvertHTMLToMarkdown(removeNoisyElements(content))
+		if err != nil {
+			return "", fmt.Errorf("failed to convert HTML to markdown: %w", err)
+		}
+		return cleanupMarkdown(markdown), nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", err
 	}
+	return result.Content, nil
+}
 
-	content := string(body)
-
-	if !utf8.ValidString(content) {
-		return "", errors.New("response content is not valid UTF-8")
+// convertFetchedBody converts a response body based on its content type:
+// PDFs are converted to text with page markers, JSON is formatted and other
+// text is returned as is. HTML is returned unchanged with isHTML set, so
+// callers can convert it as they see fit.
+func convertFetchedBody(body []byte, contentType string) (content string, isHTML bool, err error) {
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
 	}
 
-	contentType := resp.Header.Get("Content-Type")
-
-	// Convert HTML to markdown for better AI processing.
-	if strings.Contains(contentType, "text/html") {
-		// Remove noisy elements before conversion.
-		cleanedHTML := removeNoisyElements(content)
-		markdown, err := ConvertHTMLToMarkdown(cleanedHTML)
+	if isPDF(body, contentType) {
+		text, err := extractPDFText(body)
 		if err != nil {
-			return "", fmt.Errorf("failed to convert HTML to markdown: %w", err)
-		}
-		content = cleanupMarkdown(markdown)
-	} else if strings.Contains(contentType, "application/json") || strings.Contains(contentType, "text/json") {
-		// Format JSON for better readability.
-		formatted, err := FormatJSON(content)
-		if err == nil {
-			content = formatted
+			return "", false, err
 		}
-		// If formatting fails, keep original content.
+		return text, false, nil
 	}
 
-	return content, nil
+	if !utf8.Valid(body) {
+		if mediaType, _, _ := strings.Cut(contentType, ";"); mediaType != "" {
+			return "", false, fmt.Errorf("response content of type %s is not valid UTF-8 text", mediaType)
+		}
+		return "", false, errors.New("response content is not valid UTF-8")
+	}
+	content = string(body)
+
+	switch {
+	case strings.Contains(contentType, "text/html"), strings.Contains(contentType, "application/xhtml"):
+		return content, true, nil
+	case strings.Contains(contentType, "application/json"),
+		strings.Contains(contentType, "text/json"),
+		strings.Contains(contentType, "+json"):
+		// Format JSON for better readability, keeping the original content
+		// if formatting fails.
+		if formatted, err := FormatJSON(content); err == nil {
+			return formatted, false, nil
+		}
+	}
+	return content, false, nil
 }
 
 // removeNoisyElements removes script, style, nav, header, footer, and other
@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+)
+
+// maxFileOpFiles is the maximum number of files a single move, copy or
+// delete can affect.
+const maxFileOpFiles = 1000
+
+// listFileOpFiles returns the regular files at path, walking it if it's a
+// directory.
+func listFileOpFiles(path string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) == maxFileOpFiles {
+			return fmt.Errorf("too many files in %s, the limit is %d", path, maxFileOpFiles)
+		}
+		files = append(files, p)
+		return nil
+	})
+	return files, err
+}
+
+// readHistoryContent reads a file to be recorded in the history. Binary and
+// large files aren't tracked.
+func readHistoryContent(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > MaxReadSize {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !utf8.Valid(content) {
+		return "", false
+	}
+	return string(content), true
+}
+
+// recordFileVersion records newContent as the latest version of path. If the
+// history doesn't know the file yet, or it changed since its last version,
+// oldContent is recorded first. An empty newContent is a tombstone for a file
+// that was moved away or deleted.
+func recordFileVersion(ctx context.Context, files history.Service, sessionID, path, oldContent, newContent string) {
+	file, err := files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil {
+		if _, err := files.Create(ctx, sessionID, path, oldContent); err != nil {
+			slog.Error("Error creating file history", "error", err)
+			return
+		}
+	} else if file.Content != oldContent {
+		// User manually changed the content; store an intermediate version
+		if _, err := files.CreateVersion(ctx, sessionID, path, oldContent); err != nil {
+			slog.Error("Error creating file history version", "error", err)
+		}
+	}
+	if _, err := files.CreateVersion(ctx, sessionID, path, newContent); err != nil {
+		slog.Error("Error creating file history version", "error", err)
+	}
+}
+
+// wasRead reports whether the file at path was read in the session and
+// hasn't changed since.
+func wasRead(ctx context.Context, tracker filetracker.Service, sessionID, path string) bool {
+	lastRead := tracker.LastReadTime(ctx, sessionID, path)
+	if lastRead.IsZero() {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Truncate(time.Second).After(lastRead)
+}
+
+// notifyLSPsFileOperations tells the running language servers about created
+// and deleted files.
+func notifyLSPsFileOperations(ctx context.Context, lspManager *lsp.Manager, created, deleted []string) {
+	for client := range lspManager.Clients().Seq() {
+		handled := func(paths []string) []string {
+			var res []string
+			for _, path := range paths {
+				if client.HandlesFile(path) {
+					res = append(res, path)
+				}
+			}
+			return res
+		}
+		if err := client.NotifyFileOperations(ctx, handled(created), handled(deleted)); err != nil {
+			slog.Warn("Error notifying LSP about file operations", "lsp", client.GetName(), "error", err)
+		}
+	}
+}
+
+// checkFileOpPaths validates the source and destination of a move or copy.
+func checkFileOpPaths(source, destination string) (fantasy.ToolResponse, bool) {
+	info, err := os.Lstat(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fantasy.NewTextErrorResponse(fmt.Sprintf("path not found: %s", source)), false
+		}
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to access %s: %s", source, err)), false
+	}
+	if _, err := os.Lstat(destination); err == nil {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("destination already exists: %s", destination)), false
+	}
+	if info.IsDir() && isSubPath(source, destination) {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("cannot move or copy %s into itself", source)), false
+	}
+	return fantasy.ToolResponse{}, true
+}
+
+// mustRel returns path relative to base, which must contain it.
+func mustRel(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// copyPath copies the file or directory at src to dst, keeping file modes
+// and symbolic links.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type", path)
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// movePath moves the file or directory at src to dst, falling back to a copy
+// when they are on different devices.
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyPath(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// movePermissionPaths returns the paths permission is asked for to move
+// source to destination: both, since a move writes to each of them.
+func movePermissionPaths(source, destination, workingDir string) []string {
+	paths := []string{fsext.PathOrPrefix(source, workingDir)}
+	if dst := fsext.PathOrPrefix(destination, workingDir); dst != paths[0] {
+		paths = append(paths, dst)
+	}
+	return paths
+}
+
+// isSubPath reports whether path is parent or within it.
+func isSubPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHistoryService keeps the versions created for each path.
+type recordingHistoryService struct {
+	mockHistoryService
+	versions map[string][]string
+}
+
+func (r *recordingHistoryService) Create(ctx context.Context, sessionID, path, content string) (history.File, error) {
+	r.versions[path] = append(r.versions[path], content)
+	return history.File{Path: path, Content: content}, nil
+}
+
+func (r *recordingHistoryService) CreateVersion(ctx context.Context, sessionID, path, content string) (history.File, error) {
+	return r.Create(ctx, sessionID, path, content)
+}
+
+func (r *recordingHistoryService) GetByPathAndSession(ctx context.Context, path, sessionID string) (history.File, error) {
+	versions := r.versions[path]
+	if len(versions) == 0 {
+		return history.File{}, errors.New("not found")
+	}
+	return history.File{Path: path, Content: versions[len(versions)-1]}, nil
+}
+
+func TestRecordFileVersion(t *testing.T) {
+	t.Parallel()
+
+	files := &recordingHistoryService{versions: map[string][]string{}}
+	ctx := t.Context()
+
+	// A moved file: tombstone for the old path, new file for the new one.
+	recordFileVersion(ctx, files, "s", "/a.txt", "hello", "")
+	recordFileVersion(ctx, files, "s", "/b.txt", "", "hello")
+	require.Equal(t, []string{"hello", ""}, files.versions["/a.txt"])
+	require.Equal(t, []string{"", "hello"}, files.versions["/b.txt"])
+
+	// Changed outside of the tools since the last version.
+	recordFileVersion(ctx, files, "s", "/b.txt", "hello world", "")
+	require.Equal(t, []string{"", "hello", "hello world", ""}, files.versions["/b.txt"])
+}
+
+func TestCopyAndMovePath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "run.sh"), []byte("b"), 0o755))
+
+	files, err := listFileOpFiles(src)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(src, "a.txt"),
+		filepath.Join(src, "sub", "run.sh"),
+	}, files)
+
+	dst := filepath.Join(dir, "copy")
+	require.NoError(t, copyPath(src, dst))
+	content, err := os.ReadFile(filepath.Join(dst, "sub", "run.sh"))
+	require.NoError(t, err)
+	require.Equal(t, "b", string(content))
+	if info, err := os.Stat(filepath.Join(dst, "sub", "run.sh")); err == nil && os.PathSeparator == '/' {
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+
+	moved := filepath.Join(dir, "moved")
+	require.NoError(t, movePath(dst, moved))
+	require.NoDirExists(t, dst)
+	require.FileExists(t, filepath.Join(moved, "a.txt"))
+}
+
+func TestCheckFileOpPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), nil, 0o644))
+
+	for name, tc := range map[string]struct {
+		source, destination string
+		err                 string
+	}{
+		"ok":             {source: "src", destination: "dst"},
+		"missing source": {source: "missing", destination: "dst", err: "path not found"},
+		"existing":       {source: "src", destination: "file.txt", err: "destination already exists"},
+		"into itself":    {source: "src", destination: "src/dst", err: "into itself"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			resp, ok := checkFileOpPaths(filepath.Join(dir, tc.source), filepath.Join(dir, tc.destination))
+			if tc.err == "" {
+				require.True(t, ok)
+				return
+			}
+			require.False(t, ok)
+			require.Contains(t, resp.Content, tc.err)
+		})
+	}
+
+	require.True(t, isSubPath(dir, dir))
+	require.True(t, isSubPath(dir, filepath.Join(dir, "src")))
+	require.False(t, isSubPath(filepath.Join(dir, "src"), dir))
+	require.False(t, isSubPath(filepath.Join(dir, "src"), filepath.Join(dir, "src2")))
+}
+
+func TestMovePermissionPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	outside := t.TempDir()
+	require.Equal(t, []string{dir}, movePermissionPaths(filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), dir))
+	require.Equal(t, []string{dir, filepath.Join(outside, "a.txt")},
+		movePermissionPaths(filepath.Join(dir, "a.txt"), filepath.Join(outside, "a.txt"), dir),
+		"moving out of the working directory asks for the destination too")
+	require.Equal(t, []string{filepath.Join(outside, "a.txt"), dir},
+		movePermissionPaths(filepath.Join(outside, "a.txt"), filepath.Join(dir, "a.txt"), dir))
+}
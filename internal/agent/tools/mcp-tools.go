@@ -3,12 +3,26 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"charm.land/fantasy"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/permission"
 )
 
+// MCPResponseMetadata is the metadata of the responses of MCP tools.
+type MCPResponseMetadata struct {
+	// Media holds the media of the result after the first one, which is the
+	// data of the response.
+	Media []MCPMedia `json:"media,omitempty"`
+}
+
+// MCPMedia is an image, audio or binary resource returned by an MCP tool.
+type MCPMedia struct {
+	Data      []byte `json:"data"`
+	MediaType string `json:"media_type"`
+}
+
 // GetMCPTools gets all the currently available MCP tools.
 func GetMCPTools(permissions permission.Service, wd string) []*Tool {
 	var result []*Tool
@@ -32,6 +46,20 @@ type Tool struct {
 	permissions     permission.Service
 	workingDir      string
 	providerOptions fantasy.ProviderOptions
+	deferred        bool
+}
+
+var _ DeferredTool = (*Tool)(nil)
+
+// SetDeferred sets whether the tool is only sent to the model once it's
+// loaded with the tool_search tool.
+func (m *Tool) SetDeferred(deferred bool) {
+	m.deferred = deferred
+}
+
+// Deferred implements [DeferredTool].
+func (m *Tool) Deferred() bool {
+	return m.deferred
 }
 
 func (m *Tool) SetProviderOptions(opts fantasy.ProviderOptions) {
@@ -107,27 +135,39 @@ func (m *Tool) Run(ctx context.Context, params fantasy.ToolCall) (fantasy.ToolRe
 		return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	result, err := mcp.RunTool(ctx, m.mcpName, m.tool.Name, params.Input)
+	result, err := mcp.RunTool(ctx, sessionID, params.ID, m.mcpName, m.tool.Name, params.Input)
 	if err != nil {
 		return fantasy.NewTextErrorResponse(err.Error()), nil
 	}
+	if result.IsError {
+		return fantasy.NewTextErrorResponse(result.Content), nil
+	}
+	if len(result.Media) == 0 {
+		return fantasy.NewTextResponse(result.Content), nil
+	}
 
-	switch result.Type {
-	case "image", "media":
-		if !GetSupportsImagesFromContext(ctx) {
-			modelName := GetModelNameFromContext(ctx)
-			return fantasy.NewTextErrorResponse(fmt.Sprintf("This model (%s) does not support image data.", modelName)), nil
-		}
+	if !GetSupportsImagesFromContext(ctx) {
+		modelName := GetModelNameFromContext(ctx)
+		note := fmt.Sprintf("[%d media item(s) omitted, this model (%s) does not support image data.]", len(result.Media), modelName)
+		return fantasy.NewTextResponse(strings.TrimSpace(result.Content + "\n" + note)), nil
+	}
 
-		var response fantasy.ToolResponse
-		if result.Type == "image" {
-			response = fantasy.NewImageResponse(result.Data, result.MediaType)
-		} else {
-			response = fantasy.NewMediaResponse(result.Data, result.MediaType)
-		}
-		response.Content = result.Content
+	// Responses carry a single media item, the others go in the metadata
+	// and are sent to the model along with the result.
+	first := result.Media[0]
+	var response fantasy.ToolResponse
+	if strings.HasPrefix(first.MediaType, "image/") {
+		response = fantasy.NewImageResponse(first.Data, first.MediaType)
+	} else {
+		response = fantasy.NewMediaResponse(first.Data, first.MediaType)
+	}
+	response.Content = result.Content
+	if len(result.Media) == 1 {
 		return response, nil
-	default:
-		return fantasy.NewTextResponse(result.Content), nil
 	}
+	metadata := MCPResponseMetadata{}
+	for _, media := range result.Media[1:] {
+		metadata.Media = append(metadata.Media, MCPMedia{Data: media.Data, MediaType: media.MediaType})
+	}
+	return fantasy.WithResponseMetadata(response, metadata), nil
 }
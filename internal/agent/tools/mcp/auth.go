@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	mcpoauth "github.com/charmbracelet/crush/internal/oauth/mcp"
+)
+
+// ErrNeedsAuth is returned when an MCP server rejects Crush as
+// unauthorized.
+var ErrNeedsAuth = errors.New("authorization required")
+
+var (
+	// credentials holds the OAuth credentials of the MCP servers, newer
+	// than the config ones once refreshed or after a login.
+	credentials = csync.NewMap[string, *config.MCPOAuth]()
+	// refreshLocks serializes the token refreshes of each server.
+	refreshLocks = csync.NewMap[string, *sync.Mutex]()
+	// unauthorized holds the servers that answered the last request with
+	// 401 Unauthorized.
+	unauthorized = csync.NewMap[string, bool]()
+)
+
+// authRoundTripper sets the configured headers and the OAuth bearer token of
+// an HTTP or SSE MCP server on its requests, refreshing the token when it
+// expires or is rejected, and records whether the server answered with 401
+// Unauthorized.
+type authRoundTripper struct {
+	name    string
+	headers map[string]string
+}
+
+func newAuthRoundTripper(name string, m config.MCPConfig) *authRoundTripper {
+	if m.OAuth != nil {
+		credentials.GetOrSet(name, func() *config.MCPOAuth { return m.OAuth })
+	}
+	unauthorized.Del(name)
+	return &authRoundTripper{
+		name:    name,
+		headers: m.ResolvedHeaders(),
+	}
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range rt.headers {
+		req.Header.Set(k, v)
+	}
+	var sent string
+	if req.Header.Get("Authorization") == "" {
+		if sent = rt.accessToken(req.Context(), ""); sent != "" {
+			req.Header.Set("Authorization", "Bearer "+sent)
+		}
+	}
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// The token may have been revoked or have expired without the server
+	// telling when, so it's refreshed and the request sent once more.
+	if resp.StatusCode == http.StatusUnauthorized && sent != "" {
+		if token := rt.accessToken(req.Context(), sent); token != sent {
+			if retry, ok := rewind(req); ok {
+				resp.Body.Close()
+				retry.Header.Set("Authorization", "Bearer "+token)
+				if resp, err = http.DefaultTransport.RoundTrip(retry); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		unauthorized.Set(rt.name, true)
+	} else {
+		unauthorized.Del(rt.name)
+	}
+	return resp, nil
+}
+
+// rewind returns a copy of req that can be sent again, if its body can be
+// read again.
+func rewind(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+	return retry, true
+}
+
+// accessToken returns the access token of the server, refreshing and saving
+// it first when it has expired or when it's rejected, the token the server
+// answered with 401 Unauthorized.
+func (rt *authRoundTripper) accessToken(ctx context.Context, rejected string) string {
+	mu := refreshLocks.GetOrSet(rt.name, func() *sync.Mutex { return &sync.Mutex{} })
+	mu.Lock()
+	defer mu.Unlock()
+
+	auth, ok := credentials.Get(rt.name)
+	if !ok || auth.Token == nil {
+		return ""
+	}
+	if rejected != "" && auth.Token.AccessToken != rejected {
+		// Refreshed by another request in the meantime.
+		return auth.Token.AccessToken
+	}
+	// Tokens without an expiry are used until the server rejects them.
+	expired := rejected != "" || (auth.Token.ExpiresAt != 0 && auth.Token.IsExpired())
+	if !expired || auth.Token.RefreshToken == "" || auth.TokenURL == "" {
+		return auth.Token.AccessToken
+	}
+
+	token, err := mcpoauth.Refresh(ctx, auth.TokenURL, &mcpoauth.Client{
+		ID:     auth.ClientID,
+		Secret: auth.ClientSecret,
+	}, auth.Token)
+	if err != nil {
+		slog.Warn("Failed to refresh MCP OAuth token", "name", rt.name, "error", err)
+		return auth.Token.AccessToken
+	}
+	slog.Info("Successfully refreshed MCP OAuth token", "name", rt.name)
+
+	refreshed := *auth
+	refreshed.Token = token
+	credentials.Set(rt.name, &refreshed)
+	if cfg := config.Get(); cfg != nil {
+		if err := cfg.SetMCPOAuth(rt.name, &refreshed); err != nil {
+			slog.Warn("Failed to persist refreshed MCP OAuth token", "name", rt.name, "error", err)
+		}
+	}
+	return token.AccessToken
+}
+
+// needsAuth reports whether the HTTP or SSE MCP server name rejected the
+// last request as unauthorized, after trying to refresh the token.
+func needsAuth(name string) bool {
+	rejected, _ := unauthorized.Get(name)
+	return rejected
+}
+
+// requireAuth closes the session of the MCP server name, if any, and marks
+// it as needing authorization.
+func requireAuth(name string, counts Counts) error {
+	if session, ok := sessions.Take(name); ok {
+		_ = session.Close()
+	}
+	err := fmt.Errorf("%w, run crush login mcp %s", ErrNeedsAuth, name)
+	updateState(name, StateNeedsAuth, err, nil, counts)
+	slog.Warn("MCP client needs authorization", "name", name)
+	return err
+}
+
+// Login authorizes Crush with the HTTP or SSE MCP server name, discovering
+// its authorization server and registering a client with it when none is
+// configured. open is called with the URL the user has to visit. The
+// credentials are saved in the data config.
+func Login(ctx context.Context, cfg *config.Config, name string, open func(url string) error) error {
+	m, ok := cfg.MCP[name]
+	if !ok {
+		return fmt.Errorf("mcp %s not found", name)
+	}
+	if m.Type != config.MCPHttp && m.Type != config.MCPSSE {
+		return fmt.Errorf("mcp %s is not an http or sse server", name)
+	}
+
+	var auth config.MCPOAuth
+	if c, ok := credentials.Get(name); ok {
+		auth = *c
+	} else if m.OAuth != nil {
+		auth = *m.OAuth
+	}
+
+	md, err := mcpoauth.Discover(ctx, m.URL)
+	if err != nil {
+		return err
+	}
+	if len(auth.Scopes) > 0 {
+		md.Scopes = auth.Scopes
+	}
+
+	l, redirectURI, err := mcpoauth.Listen(auth.RedirectURI)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	client := &mcpoauth.Client{
+		ID:          auth.ClientID,
+		Secret:      auth.ClientSecret,
+		RedirectURI: redirectURI,
+	}
+	// A redirect URI is only saved for registered clients, which have to
+	// be registered again when it can't be listened on anymore.
+	if auth.ClientID == "" || (auth.RedirectURI != "" && auth.RedirectURI != redirectURI) {
+		if client, err = mcpoauth.Register(ctx, md, redirectURI); err != nil {
+			return err
+		}
+		auth.ClientID = client.ID
+		auth.ClientSecret = client.Secret
+		auth.RedirectURI = client.RedirectURI
+	}
+
+	token, err := mcpoauth.Authorize(ctx, md, client, l, open)
+	if err != nil {
+		return err
+	}
+	auth.TokenURL = md.TokenEndpoint
+	auth.Token = token
+
+	credentials.Set(name, &auth)
+	return cfg.SetMCPOAuth(name, &auth)
+}
+
+// Reconnect closes the session of the MCP server name, if any, and connects
+// to it again, e.g. after logging in.
+func Reconnect(ctx context.Context, name string) {
+	unlock := lock(name)
+	defer unlock()
+
+	cfg := config.Get()
+	m, ok := cfg.MCP[name]
+	if !ok || m.Disabled {
+		return
+	}
+	if session, ok := sessions.Take(name); ok {
+		_ = session.Close()
+	}
+	updateState(name, StateStarting, nil, nil, Counts{})
+	connect(ctx, name, m, cfg.Resolver())
+}
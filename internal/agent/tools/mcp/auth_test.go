@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/oauth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRoundTripperRefreshesRejectedToken(t *testing.T) {
+	t.Parallel()
+
+	var refreshes int
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "new", "token_type": "Bearer"}`)
+	}))
+	defer tokens.Close()
+
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	name := "refresh-" + t.Name()
+	// Tokens without an expiry are refreshed once the server rejects them.
+	rt := newAuthRoundTripper(name, config.MCPConfig{OAuth: &config.MCPOAuth{
+		TokenURL: tokens.URL,
+		Token:    &oauth.Token{AccessToken: "old", RefreshToken: "refresh"},
+	}})
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"Bearer old", "Bearer new"}, auths)
+	require.Equal(t, 1, refreshes)
+	require.False(t, needsAuth(name))
+}
+
+func TestAuthRoundTripperNeedsAuth(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	name := "needs-auth-" + t.Name()
+	rt := newAuthRoundTripper(name, config.MCPConfig{OAuth: &config.MCPOAuth{
+		Token: &oauth.Token{AccessToken: "old"},
+	}})
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.True(t, needsAuth(name))
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Elicitation actions, as the user answers an [ElicitationRequest].
+const (
+	ElicitationAccept  = "accept"
+	ElicitationDecline = "decline"
+	ElicitationCancel  = "cancel"
+)
+
+// ElicitationRequest is a request of an MCP server for input from the user.
+type ElicitationRequest struct {
+	ID      string
+	Name    string
+	Message string
+	Fields  []ElicitationField
+}
+
+// ElicitationField is a field of the form of an [ElicitationRequest].
+type ElicitationField struct {
+	Name        string
+	Title       string
+	Description string
+	// Type is string, number, integer or boolean.
+	Type     string
+	Enum     []string
+	Default  string
+	Required bool
+}
+
+type pendingElicitation struct {
+	request ElicitationRequest
+	respCh  chan *mcp.ElicitResult
+}
+
+var (
+	elicitations        = pubsub.NewBroker[ElicitationRequest]()
+	pendingElicitations = csync.NewMap[string, pendingElicitation]()
+)
+
+// SubscribeElicitations returns a channel for the elicitation requests of MCP
+// servers, each of them must be answered with [RespondElicitation].
+func SubscribeElicitations(ctx context.Context) <-chan pubsub.Event[ElicitationRequest] {
+	return elicitations.Subscribe(ctx)
+}
+
+// RespondElicitation answers the elicitation request id with action and, when
+// it's accepted, the values of its fields.
+func RespondElicitation(id, action string, values map[string]string) error {
+	pending, ok := pendingElicitations.Get(id)
+	if !ok {
+		return fmt.Errorf("elicitation request %s not found", id)
+	}
+	result := &mcp.ElicitResult{Action: action}
+	if action == ElicitationAccept {
+		content, err := pending.request.Content(values)
+		if err != nil {
+			return err
+		}
+		result.Content = content
+	}
+	select {
+	case pending.respCh <- result:
+	default:
+	}
+	return nil
+}
+
+// Content converts the values of the fields of the request as entered by the
+// user to the content of the response.
+func (r ElicitationRequest) Content(values map[string]string) (map[string]any, error) {
+	content := make(map[string]any)
+	for _, f := range r.Fields {
+		value := strings.TrimSpace(values[f.Name])
+		if value == "" {
+			if f.Required {
+				return nil, fmt.Errorf("%s is required", cmp.Or(f.Title, f.Name))
+			}
+			continue
+		}
+		if len(f.Enum) > 0 && !slices.Contains(f.Enum, value) {
+			return nil, fmt.Errorf("%s must be one of %s", cmp.Or(f.Title, f.Name), strings.Join(f.Enum, ", "))
+		}
+		switch f.Type {
+		case "boolean":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				switch strings.ToLower(value) {
+				case "y", "yes":
+					b = true
+				case "n", "no":
+					b = false
+				default:
+					return nil, fmt.Errorf("%s must be yes or no", cmp.Or(f.Title, f.Name))
+				}
+			}
+			content[f.Name] = b
+		case "integer":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be an integer", cmp.Or(f.Title, f.Name))
+			}
+			content[f.Name] = n
+		case "number":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", cmp.Or(f.Title, f.Name))
+			}
+			content[f.Name] = n
+		default:
+			content[f.Name] = value
+		}
+	}
+	return content, nil
+}
+
+// elicit handles the elicitation requests of the MCP server name, publishing
+// them and waiting for the user to answer.
+func elicit(name string) func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	return func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		if req.Params.Mode != "" && req.Params.Mode != "form" {
+			return &mcp.ElicitResult{Action: ElicitationDecline}, nil
+		}
+		fields, err := elicitationFields(req.Params.RequestedSchema)
+		if err != nil {
+			return nil, err
+		}
+
+		request := ElicitationRequest{
+			ID:      uuid.NewString(),
+			Name:    name,
+			Message: req.Params.Message,
+			Fields:  fields,
+		}
+		respCh := make(chan *mcp.ElicitResult, 1)
+		pendingElicitations.Set(request.ID, pendingElicitation{request: request, respCh: respCh})
+		defer pendingElicitations.Del(request.ID)
+
+		elicitations.Publish(pubsub.CreatedEvent, request)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-respCh:
+			return result, nil
+		}
+	}
+}
+
+// elicitationFields converts the flat object schema of an elicitation request
+// to form fields, sorted by name.
+func elicitationFields(schema any) ([]ElicitationField, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var object struct {
+		Properties map[string]struct {
+			Type        string   `json:"type"`
+			Title       string   `json:"title"`
+			Description string   `json:"description"`
+			Enum        []string `json:"enum"`
+			Default     any      `json:"default"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("invalid elicitation schema: %w", err)
+	}
+
+	fields := make([]ElicitationField, 0, len(object.Properties))
+	for _, name := range slices.Sorted(maps.Keys(object.Properties)) {
+		p := object.Properties[name]
+		field := ElicitationField{
+			Name:        name,
+			Title:       p.Title,
+			Description: p.Description,
+			Type:        cmp.Or(p.Type, "string"),
+			Enum:        p.Enum,
+			Required:    slices.Contains(object.Required, name),
+		}
+		if p.Default != nil {
+			field.Default = fmt.Sprint(p.Default)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
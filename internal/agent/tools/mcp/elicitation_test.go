@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElicit(t *testing.T) {
+	t.Parallel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "crush"}, &mcp.ClientOptions{
+		ElicitationHandler: elicit("test"),
+	})
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	events := SubscribeElicitations(t.Context())
+	go func() {
+		event := <-events
+		require.Equal(t, "test", event.Payload.Name)
+		require.Equal(t, "Where to deploy?", event.Payload.Message)
+		require.NoError(t, RespondElicitation(event.Payload.ID, ElicitationAccept, map[string]string{
+			"env":      "staging",
+			"replicas": "3",
+			"dry_run":  "yes",
+		}))
+	}()
+
+	result, err := serverSession.Elicit(t.Context(), &mcp.ElicitParams{
+		Message: "Where to deploy?",
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"env":      map[string]any{"type": "string", "enum": []string{"staging", "production"}},
+				"replicas": map[string]any{"type": "integer"},
+				"dry_run":  map[string]any{"type": "boolean"},
+			},
+			"required": []string{"env"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ElicitationAccept, result.Action)
+	require.Equal(t, map[string]any{"env": "staging", "replicas": float64(3), "dry_run": true}, result.Content)
+}
+
+func TestElicitationFields(t *testing.T) {
+	t.Parallel()
+
+	fields, err := elicitationFields(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "title": "Name", "default": "crush"},
+			"count": map[string]any{"type": "number", "description": "How many"},
+		},
+		"required": []any{"name"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ElicitationField{
+		{Name: "count", Description: "How many", Type: "number"},
+		{Name: "name", Title: "Name", Type: "string", Default: "crush", Required: true},
+	}, fields)
+}
+
+func TestElicitationContent(t *testing.T) {
+	t.Parallel()
+
+	req := ElicitationRequest{Fields: []ElicitationField{
+		{Name: "name", Type: "string", Required: true},
+		{Name: "age", Type: "integer"},
+		{Name: "color", Type: "string", Enum: []string{"red", "blue"}},
+	}}
+
+	content, err := req.Content(map[string]string{"name": "Charm", "age": "7"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "Charm", "age": int64(7)}, content)
+
+	_, err = req.Content(map[string]string{"age": "7"})
+	require.EqualError(t, err, "name is required")
+
+	_, err = req.Content(map[string]string{"name": "Charm", "age": "old"})
+	require.EqualError(t, err, "age must be an integer")
+
+	_, err = req.Content(map[string]string{"name": "Charm", "color": "green"})
+	require.EqualError(t, err, "color must be one of red, blue")
+}
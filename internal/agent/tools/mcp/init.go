@@ -10,8 +10,10 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -41,6 +43,7 @@ const (
 	StateStarting
 	StateConnected
 	StateError
+	StateNeedsAuth
 )
 
 func (s State) String() string {
@@ -53,6 +56,8 @@ func (s State) String() string {
 		return "connected"
 	case StateError:
 		return "error"
+	case StateNeedsAuth:
+		return "needs auth"
 	default:
 		return "unknown"
 	}
@@ -65,6 +70,7 @@ const (
 	EventStateChanged EventType = iota
 	EventToolsListChanged
 	EventPromptsListChanged
+	EventResourcesListChanged
 )
 
 // Event represents an event in the MCP system
@@ -78,8 +84,9 @@ type Event struct {
 
 // Counts number of available tools, prompts, etc.
 type Counts struct {
-	Tools   int
-	Prompts int
+	Tools     int
+	Prompts   int
+	Resources int
 }
 
 // ClientInfo holds information about an MCP client's state
@@ -109,6 +116,11 @@ func GetState(name string) (ClientInfo, bool) {
 
 // Close closes all MCP clients. This should be called during application shutdown.
 func Close() error {
+	closing.Store(true)
+	for name := range monitors.Seq2() {
+		stopMonitor(name)
+	}
+
 	var wg sync.WaitGroup
 	done := make(chan struct{}, 1)
 	go func() {
@@ -130,11 +142,15 @@ func Close() error {
 	case <-time.After(5 * time.Second):
 	}
 	broker.Shutdown()
+	elicitations.Shutdown()
+	progress.Shutdown()
 	return nil
 }
 
 // Initialize initializes MCP clients based on the provided configuration.
-func Initialize(ctx context.Context, permissions permission.Service, cfg *config.Config) {
+func Initialize(ctx context.Context, perms permission.Service, cfg *config.Config) {
+	permissions.Set(perms)
+
 	var wg sync.WaitGroup
 	// Initialize states for all configured MCPs
 	for name, m := range cfg.MCP {
@@ -166,42 +182,61 @@ func Initialize(ctx context.Context, permissions permission.Service, cfg *config
 				}
 			}()
 
-			// createSession handles its own timeout internally.
-			session, err := createSession(ctx, name, m, cfg.Resolver())
-			if err != nil {
-				return
-			}
-
-			tools, err := getTools(ctx, session)
-			if err != nil {
-				slog.Error("Error listing tools", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
-
-			prompts, err := getPrompts(ctx, session)
-			if err != nil {
-				slog.Error("Error listing prompts", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
-
-			toolCount := updateTools(name, tools)
-			updatePrompts(name, prompts)
-			sessions.Set(name, session)
-
-			updateState(name, StateConnected, nil, session, Counts{
-				Tools:   toolCount,
-				Prompts: len(prompts),
-			})
+			connect(ctx, name, m, cfg.Resolver())
+			startMonitor(ctx, name)
 		}(name, m)
 	}
 	wg.Wait()
 	initOnce.Do(func() { close(initDone) })
 }
 
+// connect creates a session with the MCP server name and loads its tools and
+// prompts.
+func connect(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) {
+	configs.Set(name, m)
+
+	// createSession handles its own timeout internally.
+	session, err := createSession(ctx, name, m, resolver)
+	if err != nil {
+		return
+	}
+
+	tools, err := getTools(ctx, session)
+	if err != nil {
+		slog.Error("Error listing tools", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
+
+	prompts, err := getPrompts(ctx, session)
+	if err != nil {
+		slog.Error("Error listing prompts", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
+
+	// Resources are optional, the tools and prompts are still usable
+	// without them.
+	resources, templates, err := getResources(ctx, session)
+	if err != nil {
+		slog.Warn("Error listing resources", "name", name, "error", err)
+	}
+
+	toolCount := updateTools(name, tools)
+	updatePrompts(name, prompts)
+	updateResources(name, resources, templates)
+	sessions.Set(name, session)
+	go watchSession(name, session)
+
+	updateState(name, StateConnected, nil, session, Counts{
+		Tools:     toolCount,
+		Prompts:   len(prompts),
+		Resources: len(resources) + len(templates),
+	})
+}
+
 // WaitForInit blocks until MCP initialization is complete.
 // If Initialize was never called, this returns immediately.
 func WaitForInit(ctx context.Context) error {
@@ -230,6 +265,9 @@ func getOrRenewClient(ctx context.Context, name string) (*mcp.ClientSession, err
 	if err == nil {
 		return sess, nil
 	}
+	if needsAuth(name) {
+		return nil, requireAuth(name, state.Counts)
+	}
 	updateState(name, StateError, maybeTimeoutErr(err, timeout), nil, state.Counts)
 
 	sess, err = createSession(ctx, name, m, cfg.Resolver())
@@ -239,6 +277,7 @@ func getOrRenewClient(ctx context.Context, name string) (*mcp.ClientSession, err
 
 	updateState(name, StateConnected, nil, sess, state.Counts)
 	sessions.Set(name, sess)
+	go watchSession(name, sess)
 	return sess, nil
 }
 
@@ -254,7 +293,7 @@ func updateState(name string, state State, err error, client *mcp.ClientSession,
 	switch state {
 	case StateConnected:
 		info.ConnectedAt = time.Now()
-	case StateError:
+	case StateError, StateNeedsAuth:
 		sessions.Del(name)
 	}
 	states.Set(name, info)
@@ -274,7 +313,7 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 	mcpCtx, cancel := context.WithCancel(ctx)
 	cancelTimer := time.AfterFunc(timeout, cancel)
 
-	transport, err := createTransport(mcpCtx, m, resolver)
+	transport, err := createTransport(mcpCtx, name, m, resolver)
 	if err != nil {
 		updateState(name, StateError, err, nil, Counts{})
 		slog.Error("Error creating MCP client", "error", err, "name", name)
@@ -302,14 +341,31 @@ func createSession(ctx context.Context, name string, m config.MCPConfig, resolve
 					Name: name,
 				})
 			},
+			ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+				broker.Publish(pubsub.UpdatedEvent, Event{
+					Type: EventResourcesListChanged,
+					Name: name,
+				})
+			},
 			LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
 				slog.Info("MCP log", "name", name, "data", req.Params.Data)
+				logFor(name).Printf("[%s] %v", req.Params.Level, req.Params.Data)
 			},
+			ProgressNotificationHandler: notifyProgress(name),
+			CreateMessageHandler:        createMessage(name, m),
+			ElicitationHandler:          elicit(name),
 		},
 	)
+	client.AddRoots(roots(m)...)
 
 	session, err := client.Connect(mcpCtx, transport, nil)
 	if err != nil {
+		if needsAuth(name) {
+			err = requireAuth(name, Counts{})
+			cancel()
+			cancelTimer.Stop()
+			return nil, err
+		}
 		err = maybeStdioErr(err, transport)
 		updateState(name, StateError, maybeTimeoutErr(err, timeout), nil, Counts{})
 		slog.Error("MCP client failed to initialize", "error", err, "name", name)
@@ -351,7 +407,7 @@ func maybeTimeoutErr(err error, timeout time.Duration) error {
 	return err
 }
 
-func createTransport(ctx context.Context, m config.MCPConfig, resolver config.VariableResolver) (mcp.Transport, error) {
+func createTransport(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) (mcp.Transport, error) {
 	switch m.Type {
 	case config.MCPStdio:
 		command, err := resolver.ResolveValue(m.Command)
@@ -363,6 +419,7 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 		}
 		cmd := exec.CommandContext(ctx, home.Long(command), m.Args...)
 		cmd.Env = append(os.Environ(), m.ResolvedEnv()...)
+		cmd.Stderr = logFor(name)
 		return &mcp.CommandTransport{
 			Command: cmd,
 		}, nil
@@ -371,9 +428,7 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 			return nil, fmt.Errorf("mcp http config requires a non-empty 'url' field")
 		}
 		client := &http.Client{
-			Transport: &headerRoundTripper{
-				headers: m.ResolvedHeaders(),
-			},
+			Transport: newAuthRoundTripper(name, m),
 		}
 		return &mcp.StreamableClientTransport{
 			Endpoint:   m.URL,
@@ -384,9 +439,7 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 			return nil, fmt.Errorf("mcp sse config requires a non-empty 'url' field")
 		}
 		client := &http.Client{
-			Transport: &headerRoundTripper{
-				headers: m.ResolvedHeaders(),
-			},
+			Transport: newAuthRoundTripper(name, m),
 		}
 		return &mcp.SSEClientTransport{
 			Endpoint:   m.URL,
@@ -397,15 +450,26 @@ func createTransport(ctx context.Context, m config.MCPConfig, resolver config.Va
 	}
 }
 
-type headerRoundTripper struct {
-	headers map[string]string
-}
-
-func (rt headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	for k, v := range rt.headers {
-		req.Header.Set(k, v)
+// roots returns the roots advertised to an MCP server: the working directory
+// and the extra directories configured for it.
+func roots(m config.MCPConfig) []*mcp.Root {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	dirs := append([]string{cfg.WorkingDir()}, m.Roots...)
+	result := make([]*mcp.Root, 0, len(dirs))
+	for _, dir := range dirs {
+		dir = home.Long(dir)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cfg.WorkingDir(), dir)
+		}
+		result = append(result, &mcp.Root{
+			URI:  (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String(),
+			Name: filepath.Base(dir),
+		})
 	}
-	return http.DefaultTransport.RoundTrip(req)
+	return result
 }
 
 func mcpTimeout(m config.MCPConfig) time.Duration {
@@ -0,0 +1,318 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	healthCheckInterval = 30 * time.Second
+	minReconnectDelay   = 2 * time.Second
+	maxReconnectDelay   = 2 * time.Minute
+	maxLogLines         = 500
+)
+
+// monitor checks the health of an MCP server and reconnects to it when it
+// goes down.
+type monitor struct {
+	cancel context.CancelFunc
+	wake   chan struct{}
+}
+
+var (
+	monitors = csync.NewMap[string, *monitor]()
+	// configs holds the config each server was last connected with, even
+	// when connecting failed.
+	configs = csync.NewMap[string, config.MCPConfig]()
+	logs    = csync.NewMap[string, *serverLog]()
+	locks   = csync.NewMap[string, *sync.Mutex]()
+	closing atomic.Bool
+)
+
+// Disable closes the session of the MCP server name and stops checking its
+// health, until it's enabled again.
+func Disable(name string) {
+	unlock := lock(name)
+	defer unlock()
+
+	stopMonitor(name)
+	if session, ok := sessions.Take(name); ok {
+		_ = session.Close()
+	}
+	clearServer(name)
+	updateState(name, StateDisabled, nil, nil, Counts{})
+}
+
+// Enable connects to the MCP server name and starts checking its health.
+func Enable(ctx context.Context, name string) {
+	Reconnect(ctx, name)
+	startMonitor(ctx, name)
+}
+
+// Reconcile brings the MCP servers in line with cfg after it changed: new
+// and changed servers are connected again, and removed or disabled ones are
+// closed.
+func Reconcile(ctx context.Context, cfg *config.Config) {
+	for name := range states.Copy() {
+		if _, ok := cfg.MCP[name]; !ok {
+			remove(name)
+		}
+	}
+	for name, m := range cfg.MCP {
+		info, known := states.Get(name)
+		switch {
+		case m.Disabled:
+			if !known || info.State != StateDisabled {
+				Disable(name)
+			}
+		case !known || info.State == StateDisabled:
+			Enable(ctx, name)
+		default:
+			if prev, ok := configs.Get(name); !ok || !sameConfig(prev, m) {
+				slog.Info("MCP config changed, reconnecting", "name", name)
+				Enable(ctx, name)
+			}
+		}
+	}
+}
+
+// Log returns the last lines the MCP server name wrote to stderr or logged.
+func Log(name string) []string {
+	l, ok := logs.Get(name)
+	if !ok {
+		return nil
+	}
+	return l.Lines()
+}
+
+// remove closes the session of the MCP server name and forgets about it, as
+// it's no longer configured.
+func remove(name string) {
+	unlock := lock(name)
+	defer unlock()
+
+	stopMonitor(name)
+	if session, ok := sessions.Take(name); ok {
+		_ = session.Close()
+	}
+	clearServer(name)
+	states.Del(name)
+	logs.Del(name)
+	broker.Publish(pubsub.DeletedEvent, Event{
+		Type:  EventStateChanged,
+		Name:  name,
+		State: StateDisabled,
+	})
+}
+
+// clearServer removes the tools, prompts and resources of the MCP server
+// name.
+func clearServer(name string) {
+	configs.Del(name)
+	updateTools(name, nil)
+	updatePrompts(name, nil)
+	updateResources(name, nil, nil)
+}
+
+// lock locks the MCP server name, so it's only connected or closed by one
+// goroutine at a time.
+func lock(name string) func() {
+	mu := locks.GetOrSet(name, func() *sync.Mutex { return &sync.Mutex{} })
+	mu.Lock()
+	return mu.Unlock
+}
+
+// sameConfig reports whether two configs of an MCP server connect to it the
+// same way. The OAuth token is left out, as it's refreshed in the background,
+// and so are the tool timeouts, which are read on each call.
+func sameConfig(a, b config.MCPConfig) bool {
+	a.OAuth, b.OAuth = nil, nil
+	a.ToolTimeouts, b.ToolTimeouts = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+func startMonitor(ctx context.Context, name string) {
+	if _, ok := monitors.Get(name); ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	mon := &monitor{cancel: cancel, wake: make(chan struct{}, 1)}
+	monitors.Set(name, mon)
+	go mon.run(ctx, name)
+}
+
+func stopMonitor(name string) {
+	if mon, ok := monitors.Take(name); ok {
+		mon.cancel()
+	}
+}
+
+// wakeMonitor makes the monitor of the MCP server name check it right away.
+func wakeMonitor(name string) {
+	mon, ok := monitors.Get(name)
+	if !ok {
+		return
+	}
+	select {
+	case mon.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run pings the MCP server name periodically, and reconnects to it with an
+// exponential backoff while it's down.
+func (mon *monitor) run(ctx context.Context, name string) {
+	delay := minReconnectDelay
+	timer := time.NewTimer(healthCheckInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-mon.wake:
+		case <-timer.C:
+		}
+
+		next := healthCheckInterval
+		if checkHealth(ctx, name) {
+			delay = minReconnectDelay
+		} else {
+			next = delay
+			delay = min(delay*2, maxReconnectDelay)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// checkHealth pings the MCP server name, reconnecting to it when it's down.
+// It reports whether the server is up, or doesn't need to be reconnected.
+func checkHealth(ctx context.Context, name string) bool {
+	unlock := lock(name)
+	defer unlock()
+
+	info, _ := states.Get(name)
+	switch info.State {
+	case StateConnected:
+		session, ok := sessions.Get(name)
+		if !ok {
+			break
+		}
+		m, _ := configs.Get(name)
+		timeout := mcpTimeout(m)
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := session.Ping(pingCtx, nil)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return true
+		}
+		slog.Warn("MCP health check failed", "name", name, "error", err)
+		sessions.Del(name)
+		_ = session.Close()
+		updateState(name, StateError, maybeTimeoutErr(err, timeout), nil, info.Counts)
+	case StateError:
+	default:
+		// Disabled, starting, or waiting for the user to log in.
+		return true
+	}
+
+	cfg := config.Get()
+	m, ok := cfg.MCP[name]
+	if !ok || m.Disabled {
+		return true
+	}
+	slog.Info("Reconnecting to MCP server", "name", name)
+	connect(ctx, name, m, cfg.Resolver())
+	info, _ = states.Get(name)
+	return info.State != StateError
+}
+
+// watchSession marks the MCP server name as down as soon as its session ends
+// on its own, e.g. when a stdio server crashes.
+func watchSession(name string, session *mcp.ClientSession) {
+	err := session.Wait()
+	if closing.Load() {
+		return
+	}
+	if current, ok := sessions.Get(name); !ok || current != session {
+		// Closed on purpose, or replaced by a new session.
+		return
+	}
+	sessions.Del(name)
+	if err == nil {
+		err = errors.New("connection closed")
+	}
+	if lines := Log(name); len(lines) > 0 {
+		err = fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+	}
+	info, _ := states.Get(name)
+	slog.Warn("MCP server disconnected", "name", name, "error", err)
+	updateState(name, StateError, err, nil, info.Counts)
+	wakeMonitor(name)
+}
+
+// serverLog keeps the last lines an MCP server wrote to stderr or logged.
+type serverLog struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string
+}
+
+func logFor(name string) *serverLog {
+	return logs.GetOrSet(name, func() *serverLog { return &serverLog{} })
+}
+
+// Write implements [io.Writer], for the stderr of stdio servers.
+func (l *serverLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	text := l.partial + string(p)
+	lines := strings.Split(text, "\n")
+	l.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		l.add(strings.TrimSuffix(line, "\r"))
+	}
+	return len(p), nil
+}
+
+// Printf adds a line to the log.
+func (l *serverLog) Printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.add(fmt.Sprintf(format, args...))
+}
+
+func (l *serverLog) add(line string) {
+	l.lines = append(l.lines, line)
+	if len(l.lines) > maxLogLines {
+		l.lines = l.lines[len(l.lines)-maxLogLines:]
+	}
+}
+
+// Lines returns the lines of the log, including an unfinished last one.
+func (l *serverLog) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines := append([]string(nil), l.lines...)
+	if l.partial != "" {
+		lines = append(lines, l.partial)
+	}
+	return lines
+}
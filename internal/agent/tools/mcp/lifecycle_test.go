@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("splits lines", func(t *testing.T) {
+		t.Parallel()
+		var l serverLog
+		_, _ = l.Write([]byte("starting\r\nlisten"))
+		_, _ = l.Write([]byte("ing on stdio\nready"))
+		l.Printf("[%s] %s", "info", "hello")
+		require.Equal(t, []string{"starting", "listening on stdio", "[info] hello", "ready"}, l.Lines())
+	})
+
+	t.Run("keeps the last lines", func(t *testing.T) {
+		t.Parallel()
+		var l serverLog
+		for i := range maxLogLines + 10 {
+			l.Printf("line %d", i)
+		}
+		lines := l.Lines()
+		require.Len(t, lines, maxLogLines)
+		require.Equal(t, "line 10", lines[0])
+		require.Equal(t, fmt.Sprintf("line %d", maxLogLines+9), lines[len(lines)-1])
+	})
+}
+
+func TestSameConfig(t *testing.T) {
+	t.Parallel()
+
+	a := config.MCPConfig{Type: config.MCPStdio, Command: "server", Args: []string{"--stdio"}}
+	b := a
+	b.OAuth = &config.MCPOAuth{ClientID: "crush"}
+	require.True(t, sameConfig(a, b))
+
+	b.Args = []string{"--verbose"}
+	require.False(t, sameConfig(a, b))
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolProgress is the progress an MCP server reported for a running tool.
+type ToolProgress struct {
+	// ToolCallID is the ID of the tool call the progress belongs to, it's
+	// used as the progress token.
+	ToolCallID string
+	Name       string
+	Progress   float64
+	// Total is the progress at which the tool is done, zero when unknown.
+	Total   float64
+	Message string
+}
+
+var progress = pubsub.NewBroker[ToolProgress]()
+
+// SubscribeProgress returns a channel with the progress of running MCP
+// tools.
+func SubscribeProgress(ctx context.Context) <-chan pubsub.Event[ToolProgress] {
+	return progress.Subscribe(ctx)
+}
+
+// notifyProgress returns the handler of the progress notifications of the
+// MCP server name.
+func notifyProgress(name string) func(context.Context, *mcp.ProgressNotificationClientRequest) {
+	return func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		toolCallID, ok := req.Params.ProgressToken.(string)
+		if !ok || toolCallID == "" {
+			return
+		}
+		progress.Publish(pubsub.UpdatedEvent, ToolProgress{
+			ToolCallID: toolCallID,
+			Name:       name,
+			Progress:   req.Params.Progress,
+			Total:      req.Params.Total,
+			Message:    req.Params.Message,
+		})
+	}
+}
+
+// toolTimeout returns how long the tool toolName of an MCP server may run,
+// zero meaning there's no limit.
+func toolTimeout(m config.MCPConfig, toolName string) time.Duration {
+	return time.Duration(m.ToolTimeouts[toolName]) * time.Second
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTool(t *testing.T) {
+	t.Parallel()
+
+	canceled := make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "build"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		for i := range 2 {
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(),
+				Progress:      float64(i + 1),
+				Total:         4,
+				Message:       "Compiling",
+			})
+		}
+		<-ctx.Done()
+		close(canceled)
+		return nil, nil, ctx.Err()
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "crush"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: notifyProgress("test"),
+	})
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	events := SubscribeProgress(t.Context())
+	_, err = callTool(t.Context(), session, "call_1", "build", map[string]any{}, time.Second)
+	require.EqualError(t, err, "tool build timed out after 1s")
+
+	for i := range 2 {
+		event := <-events
+		require.Equal(t, ToolProgress{
+			ToolCallID: "call_1",
+			Name:       "test",
+			Progress:   float64(i + 1),
+			Total:      4,
+			Message:    "Compiling",
+		}, event.Payload)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call not canceled on the server")
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type (
+	Resource         = mcp.Resource
+	ResourceTemplate = mcp.ResourceTemplate
+	ResourceContents = mcp.ResourceContents
+)
+
+var (
+	allResources         = csync.NewMap[string, []*Resource]()
+	allResourceTemplates = csync.NewMap[string, []*ResourceTemplate]()
+)
+
+// Resources returns all available MCP resources.
+func Resources() iter.Seq2[string, []*Resource] {
+	return allResources.Seq2()
+}
+
+// ResourceTemplates returns all available MCP resource templates.
+func ResourceTemplates() iter.Seq2[string, []*ResourceTemplate] {
+	return allResourceTemplates.Seq2()
+}
+
+// ReadResource reads the resource with the given URI from an MCP server.
+func ReadResource(ctx context.Context, name, uri string) ([]*ResourceContents, error) {
+	c, err := getOrRenewClient(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// ReadResourceAttachment reads the resource with the given URI from an MCP
+// server as a message attachment. Text contents are joined into a text
+// attachment, otherwise the first binary content is attached as is.
+func ReadResourceAttachment(ctx context.Context, name, uri string) (message.Attachment, error) {
+	contents, err := ReadResource(ctx, name, uri)
+	if err != nil {
+		return message.Attachment{}, err
+	}
+	return resourceAttachment(name, uri, contents)
+}
+
+func resourceAttachment(name, uri string, contents []*ResourceContents) (message.Attachment, error) {
+	if len(contents) == 0 {
+		return message.Attachment{}, fmt.Errorf("resource %s of %s is empty", uri, name)
+	}
+
+	attachment := message.Attachment{
+		FilePath: name + ":" + uri,
+		FileName: resourceFileName(uri),
+	}
+	var texts []string
+	for _, c := range contents {
+		if c.Blob == nil {
+			texts = append(texts, c.Text)
+		}
+	}
+	if len(texts) > 0 {
+		attachment.MimeType = "text/plain"
+		if len(texts) == 1 && strings.HasPrefix(contents[0].MIMEType, "text/") {
+			attachment.MimeType = contents[0].MIMEType
+		}
+		attachment.Content = []byte(strings.Join(texts, "\n"))
+		return attachment, nil
+	}
+
+	blob := contents[0]
+	attachment.MimeType = blob.MIMEType
+	if attachment.MimeType == "" {
+		attachment.MimeType = http.DetectContentType(blob.Blob)
+	}
+	attachment.Content = blob.Blob
+	return attachment, nil
+}
+
+// RefreshResources gets the updated list of resources from the MCP and
+// updates the global state.
+func RefreshResources(ctx context.Context, name string) {
+	session, ok := sessions.Get(name)
+	if !ok {
+		slog.Warn("Refresh resources: no session", "name", name)
+		return
+	}
+
+	// The server stays usable without its resources, the ones listed
+	// before are kept.
+	resources, templates, err := getResources(ctx, session)
+	if err != nil {
+		slog.Warn("Error refreshing resources", "name", name, "error", err)
+		return
+	}
+
+	updateResources(name, resources, templates)
+
+	prev, _ := states.Get(name)
+	prev.Counts.Resources = len(resources) + len(templates)
+	updateState(name, StateConnected, nil, session, prev.Counts)
+}
+
+func getResources(ctx context.Context, c *mcp.ClientSession) ([]*Resource, []*ResourceTemplate, error) {
+	if c.InitializeResult().Capabilities.Resources == nil {
+		return nil, nil, nil
+	}
+	var resources []*Resource
+	for r, err := range c.Resources(ctx, &mcp.ListResourcesParams{}) {
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, r)
+	}
+	// Templates are optional, servers without them may not implement the
+	// method at all.
+	var templates []*ResourceTemplate
+	for t, err := range c.ResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{}) {
+		if err != nil {
+			slog.Debug("Error listing resource templates", "error", err)
+			break
+		}
+		templates = append(templates, t)
+	}
+	return resources, templates, nil
+}
+
+// updateResources updates the global resources and resource templates maps.
+func updateResources(mcpName string, resources []*Resource, templates []*ResourceTemplate) {
+	if len(resources) == 0 {
+		allResources.Del(mcpName)
+	} else {
+		allResources.Set(mcpName, resources)
+	}
+	if len(templates) == 0 {
+		allResourceTemplates.Del(mcpName)
+	} else {
+		allResourceTemplates.Set(mcpName, templates)
+	}
+}
+
+// resourceFileName returns the last element of the path of a resource URI.
+func resourceFileName(uri string) string {
+	_, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		rest = uri
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+	if base := path.Base(strings.TrimSuffix(rest, "/")); base != "." && base != "/" {
+		return base
+	}
+	return uri
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddResource(&mcp.Resource{Name: "readme", URI: "file:///README.md", MIMEType: "text/markdown"},
+		func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{}, nil
+		})
+	server.AddResourceTemplate(&mcp.ResourceTemplate{Name: "issue", URITemplate: "issue://{id}"},
+		func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{}, nil
+		})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "crush"}, nil)
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	resources, templates, err := getResources(t.Context(), session)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	require.Equal(t, "file:///README.md", resources[0].URI)
+	require.Len(t, templates, 1)
+	require.Equal(t, "issue://{id}", templates[0].URITemplate)
+}
+
+func TestResourceAttachment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		got, err := resourceAttachment("docs", "file:///guide/intro.md", []*ResourceContents{
+			{URI: "file:///guide/intro.md", MIMEType: "text/markdown", Text: "# Intro"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, message.Attachment{
+			FilePath: "docs:file:///guide/intro.md",
+			FileName: "intro.md",
+			MimeType: "text/markdown",
+			Content:  []byte("# Intro"),
+		}, got)
+	})
+
+	t.Run("text without a text mime type", func(t *testing.T) {
+		t.Parallel()
+		got, err := resourceAttachment("db", "postgres://db/schema", []*ResourceContents{
+			{URI: "postgres://db/schema", MIMEType: "application/json", Text: "{}"},
+			{URI: "postgres://db/schema", Text: "[]"},
+		})
+		require.NoError(t, err)
+		require.True(t, got.IsText())
+		require.Equal(t, "{}\n[]", string(got.Content))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		t.Parallel()
+		png := []byte("\x89PNG\r\n\x1a\n")
+		got, err := resourceAttachment("images", "image://logo", []*ResourceContents{
+			{URI: "image://logo", Blob: png},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "logo", got.FileName)
+		require.Equal(t, "image/png", got.MimeType)
+		require.Equal(t, png, got.Content)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		_, err := resourceAttachment("docs", "file:///empty", nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Sampler returns the model the sampling requests of MCP servers are run
+// with.
+type Sampler func(ctx context.Context) (fantasy.LanguageModel, error)
+
+var (
+	sampler     = csync.NewValue[Sampler](nil)
+	permissions = csync.NewValue[permission.Service](nil)
+	// toolCalls holds the sessions of the tool calls running on each MCP
+	// server, by tool call ID. Requests a server makes while its tools run
+	// belong to one of them.
+	toolCalls = csync.NewMap[string, *csync.Map[string, string]]()
+)
+
+// trackToolCall records that the tool call toolCallID of sessionID runs on
+// the MCP server name, until the returned func is called.
+func trackToolCall(name, toolCallID, sessionID string) func() {
+	calls := toolCalls.GetOrSet(name, func() *csync.Map[string, string] {
+		return csync.NewMap[string, string]()
+	})
+	calls.Set(toolCallID, sessionID)
+	return func() { calls.Del(toolCallID) }
+}
+
+// requestSession returns the session a request of the MCP server name with
+// progressToken belongs to: the one of the tool call the token is the one of,
+// or else the only session with tool calls running on the server. It's empty
+// when that's ambiguous.
+func requestSession(name string, progressToken any) string {
+	calls, ok := toolCalls.Get(name)
+	if !ok {
+		return ""
+	}
+	if token, ok := progressToken.(string); ok {
+		if sessionID, ok := calls.Get(token); ok {
+			return sessionID
+		}
+	}
+	var session string
+	for _, sessionID := range calls.Seq2() {
+		if session != "" && session != sessionID {
+			return ""
+		}
+		session = sessionID
+	}
+	return session
+}
+
+// SetSampler sets the [Sampler] of MCP sampling requests. Servers can't
+// sample until it's set.
+func SetSampler(s Sampler) {
+	sampler.Set(s)
+}
+
+// createMessage handles the sampling requests of the MCP server name, asking
+// the user for approval unless the server is configured to auto approve them.
+func createMessage(name string, m config.MCPConfig) func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		sample := sampler.Get()
+		if sample == nil {
+			return nil, errors.New("sampling is not available")
+		}
+
+		if perms := permissions.Get(); perms != nil && !m.AutoApproveSampling {
+			granted, err := perms.Request(ctx, permission.CreatePermissionRequest{
+				SessionID:   requestSession(name, req.Params.GetProgressToken()),
+				ToolName:    "mcp_" + name + "_sampling",
+				Action:      "sample",
+				Description: fmt.Sprintf("MCP server %s wants to generate a completion with the following prompt:", name),
+				Params:      samplingPrompt(req.Params),
+				Path:        config.Get().WorkingDir(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if !granted {
+				return nil, errors.New("the user denied the sampling request")
+			}
+		}
+
+		model, err := sample(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := model.Generate(ctx, samplingCall(req.Params))
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.CreateMessageResult{
+			Content:    &mcp.TextContent{Text: resp.Content.Text()},
+			Model:      model.Model(),
+			Role:       "assistant",
+			StopReason: stopReason(resp.FinishReason),
+		}, nil
+	}
+}
+
+// samplingCall converts the parameters of a sampling request to a model call.
+func samplingCall(params *mcp.CreateMessageParams) fantasy.Call {
+	var call fantasy.Call
+	if params.SystemPrompt != "" {
+		call.Prompt = append(call.Prompt, fantasy.NewSystemMessage(params.SystemPrompt))
+	}
+	for _, msg := range params.Messages {
+		var part fantasy.MessagePart
+		switch content := msg.Content.(type) {
+		case *mcp.TextContent:
+			part = fantasy.TextPart{Text: content.Text}
+		case *mcp.ImageContent:
+			part = fantasy.FilePart{Data: content.Data, MediaType: content.MIMEType}
+		case *mcp.AudioContent:
+			part = fantasy.FilePart{Data: content.Data, MediaType: content.MIMEType}
+		default:
+			continue
+		}
+		role := fantasy.MessageRoleUser
+		if msg.Role == "assistant" {
+			role = fantasy.MessageRoleAssistant
+		}
+		call.Prompt = append(call.Prompt, fantasy.Message{
+			Role:    role,
+			Content: []fantasy.MessagePart{part},
+		})
+	}
+	if params.MaxTokens > 0 {
+		call.MaxOutputTokens = &params.MaxTokens
+	}
+	if params.Temperature > 0 {
+		call.Temperature = &params.Temperature
+	}
+	return call
+}
+
+// samplingPrompt describes the prompt of a sampling request for the user.
+func samplingPrompt(params *mcp.CreateMessageParams) string {
+	var sb strings.Builder
+	if params.SystemPrompt != "" {
+		fmt.Fprintf(&sb, "system: %s\n", params.SystemPrompt)
+	}
+	for _, msg := range params.Messages {
+		switch content := msg.Content.(type) {
+		case *mcp.TextContent:
+			fmt.Fprintf(&sb, "%s: %s\n", msg.Role, content.Text)
+		case *mcp.ImageContent:
+			fmt.Fprintf(&sb, "%s: [%s image]\n", msg.Role, content.MIMEType)
+		case *mcp.AudioContent:
+			fmt.Fprintf(&sb, "%s: [%s audio]\n", msg.Role, content.MIMEType)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func stopReason(reason fantasy.FinishReason) string {
+	switch reason {
+	case fantasy.FinishReasonStop:
+		return "endTurn"
+	case fantasy.FinishReasonLength:
+		return "maxTokens"
+	default:
+		return string(reason)
+	}
+}
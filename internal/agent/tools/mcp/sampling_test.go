@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingCall(t *testing.T) {
+	t.Parallel()
+
+	params := &mcp.CreateMessageParams{
+		SystemPrompt: "Be brief.",
+		MaxTokens:    100,
+		Messages: []*mcp.SamplingMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: "Hi"}},
+			{Role: "assistant", Content: &mcp.TextContent{Text: "Hello"}},
+			{Role: "user", Content: &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"}},
+		},
+	}
+
+	call := samplingCall(params)
+	require.Equal(t, fantasy.Prompt{
+		fantasy.NewSystemMessage("Be brief."),
+		{Role: fantasy.MessageRoleUser, Content: []fantasy.MessagePart{fantasy.TextPart{Text: "Hi"}}},
+		{Role: fantasy.MessageRoleAssistant, Content: []fantasy.MessagePart{fantasy.TextPart{Text: "Hello"}}},
+		{Role: fantasy.MessageRoleUser, Content: []fantasy.MessagePart{fantasy.FilePart{Data: []byte("png"), MediaType: "image/png"}}},
+	}, call.Prompt)
+	require.Equal(t, int64(100), *call.MaxOutputTokens)
+	require.Nil(t, call.Temperature)
+
+	require.Equal(t, "system: Be brief.\nuser: Hi\nassistant: Hello\nuser: [image/png image]", samplingPrompt(params))
+}
+
+func TestRequestSession(t *testing.T) {
+	t.Parallel()
+
+	name := "sessions-" + t.Name()
+	require.Empty(t, requestSession(name, nil))
+
+	doneA := trackToolCall(name, "call-a", "session-a")
+	require.Equal(t, "session-a", requestSession(name, nil))
+
+	doneB := trackToolCall(name, "call-b", "session-b")
+	require.Empty(t, requestSession(name, nil), "ambiguous without a progress token")
+	require.Equal(t, "session-b", requestSession(name, "call-b"))
+
+	doneA()
+	require.Equal(t, "session-b", requestSession(name, nil))
+	doneB()
+	require.Empty(t, requestSession(name, nil))
+}
@@ -1,13 +1,18 @@
 package mcp
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
+	"net/http"
+	"reflect"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
@@ -18,8 +23,17 @@ type Tool = mcp.Tool
 
 // ToolResult represents the result of running an MCP tool.
 type ToolResult struct {
-	Type      string
-	Content   string
+	// Content is the text of the result, including embedded text resources,
+	// resource links and structured content.
+	Content string
+	// Media holds the images, audio and binary resources of the result.
+	Media   []ToolMedia
+	IsError bool
+}
+
+// ToolMedia is an image, audio or binary resource in the result of an MCP
+// tool.
+type ToolMedia struct {
 	Data      []byte
 	MediaType string
 }
@@ -31,8 +45,11 @@ func Tools() iter.Seq2[string, []*Tool] {
 	return allTools.Seq2()
 }
 
-// RunTool runs an MCP tool with the given input parameters.
-func RunTool(ctx context.Context, name, toolName string, input string) (ToolResult, error) {
+// RunTool runs an MCP tool with the given input parameters. Requests the
+// server makes while the tool runs, like sampling, belong to sessionID, and
+// its progress is published for toolCallID. Canceling ctx cancels the call
+// on the server too.
+func RunTool(ctx context.Context, sessionID, toolCallID, name, toolName string, input string) (ToolResult, error) {
 	var args map[string]any
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
 		return ToolResult{}, fmt.Errorf("error parsing parameters: %s", err)
@@ -42,68 +59,118 @@ func RunTool(ctx context.Context, name, toolName string, input string) (ToolResu
 	if err != nil {
 		return ToolResult{}, err
 	}
-	result, err := c.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
-	})
+	defer trackToolCall(name, toolCallID, sessionID)()
+
+	timeout := toolTimeout(config.Get().MCP[name], toolName)
+	result, err := callTool(ctx, c, toolCallID, toolName, args, timeout)
 	if err != nil {
+		if needsAuth(name) {
+			state, _ := states.Get(name)
+			return ToolResult{}, requireAuth(name, state.Counts)
+		}
 		return ToolResult{}, err
 	}
 
-	if len(result.Content) == 0 {
-		return ToolResult{Type: "text", Content: ""}, nil
+	return toolResult(name, result), nil
+}
+
+// callTool calls the tool toolName, reporting its progress for toolCallID,
+// and stops it after timeout when it's not zero.
+func callTool(ctx context.Context, session *mcp.ClientSession, toolCallID, toolName string, args map[string]any, timeout time.Duration) (*mcp.CallToolResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	var textParts []string
-	var imageData []byte
-	var imageMimeType string
-	var audioData []byte
-	var audioMimeType string
+	params := &mcp.CallToolParams{
+		Name:      toolName,
+		Arguments: args,
+	}
+	if toolCallID != "" {
+		// SetProgressToken doesn't keep the token when there's no meta yet.
+		params.Meta = mcp.Meta{}
+		params.SetProgressToken(toolCallID)
+	}
+	result, err := session.CallTool(ctx, params)
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("tool %s timed out after %s", toolName, timeout)
+	}
+	return result, err
+}
 
+// toolResult converts the result of a tool of the MCP server name, keeping
+// every content item.
+func toolResult(name string, result *mcp.CallToolResult) ToolResult {
+	var textParts []string
+	var media []ToolMedia
 	for _, v := range result.Content {
 		switch content := v.(type) {
 		case *mcp.TextContent:
 			textParts = append(textParts, content.Text)
 		case *mcp.ImageContent:
-			if imageData == nil {
-				imageData = content.Data
-				imageMimeType = content.MIMEType
-			}
+			media = append(media, ToolMedia{Data: content.Data, MediaType: content.MIMEType})
 		case *mcp.AudioContent:
-			if audioData == nil {
-				audioData = content.Data
-				audioMimeType = content.MIMEType
+			media = append(media, ToolMedia{Data: content.Data, MediaType: content.MIMEType})
+		case *mcp.EmbeddedResource:
+			r := content.Resource
+			if r == nil {
+				continue
+			}
+			if r.Blob != nil {
+				media = append(media, ToolMedia{
+					Data:      r.Blob,
+					MediaType: cmp.Or(r.MIMEType, http.DetectContentType(r.Blob)),
+				})
+				continue
 			}
-		default:
-			textParts = append(textParts, fmt.Sprintf("%v", v))
+			textParts = append(textParts, fmt.Sprintf("<resource uri=%q>\n%s\n</resource>", r.URI, r.Text))
+		case *mcp.ResourceLink:
+			textParts = append(textParts, resourceLinkText(name, content))
 		}
 	}
 
-	textContent := strings.Join(textParts, "\n")
+	if result.StructuredContent != nil {
+		if structured, err := json.MarshalIndent(result.StructuredContent, "", "  "); err == nil {
+			// Servers usually repeat the structured content as text for older
+			// clients, keep only the pretty one.
+			textParts = slices.DeleteFunc(textParts, func(text string) bool {
+				return jsonEqual(text, structured)
+			})
+			textParts = append(textParts, string(structured))
+		}
+	}
 
-	// MCP SDK returns Data as already base64-encoded, so we use it directly.
-	if imageData != nil {
-		return ToolResult{
-			Type:      "image",
-			Content:   textContent,
-			Data:      imageData,
-			MediaType: imageMimeType,
-		}, nil
+	return ToolResult{
+		Content: strings.Join(textParts, "\n"),
+		Media:   media,
+		IsError: result.IsError,
 	}
+}
 
-	if audioData != nil {
-		return ToolResult{
-			Type:      "media",
-			Content:   textContent,
-			Data:      audioData,
-			MediaType: audioMimeType,
-		}, nil
+// resourceLinkText describes a resource link so the model can read it with
+// the read_mcp_resource tool.
+func resourceLinkText(name string, link *mcp.ResourceLink) string {
+	text := fmt.Sprintf("<resource_link mcp=%q uri=%q", name, link.URI)
+	if title := cmp.Or(link.Title, link.Name); title != "" {
+		text += fmt.Sprintf(" name=%q", title)
+	}
+	if link.MIMEType != "" {
+		text += fmt.Sprintf(" mime_type=%q", link.MIMEType)
+	}
+	text += ">"
+	if link.Description != "" {
+		text += strings.Join(strings.Fields(link.Description), " ")
 	}
+	return text + "</resource_link>"
+}
 
-	return ToolResult{
-		Type:    "text",
-		Content: textContent,
-	}, nil
+func jsonEqual(text string, data []byte) bool {
+	var a, b any
+	if json.Unmarshal([]byte(text), &a) != nil || json.Unmarshal(data, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
 }
 
 // RefreshTools gets the updated list of tools from the MCP and updates the
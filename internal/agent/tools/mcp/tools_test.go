@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mixed content", func(t *testing.T) {
+		t.Parallel()
+		got := toolResult("docs", &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "Found 2 pages"},
+				&mcp.ImageContent{Data: []byte("png1"), MIMEType: "image/png"},
+				&mcp.ImageContent{Data: []byte("png2"), MIMEType: "image/png"},
+				&mcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///a.md", Text: "# A"}},
+				&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///b.pdf", MIMEType: "application/pdf", Blob: []byte("%PDF")}},
+				&mcp.ResourceLink{URI: "file:///c.md", Name: "c", MIMEType: "text/markdown", Description: "The\nC page"},
+			},
+		})
+		require.Equal(t, ToolResult{
+			Content: "Found 2 pages\n" +
+				"<resource uri=\"file:///a.md\">\n# A\n</resource>\n" +
+				"<resource_link mcp=\"docs\" uri=\"file:///c.md\" name=\"c\" mime_type=\"text/markdown\">The C page</resource_link>",
+			Media: []ToolMedia{
+				{Data: []byte("png1"), MediaType: "image/png"},
+				{Data: []byte("png2"), MediaType: "image/png"},
+				{Data: []byte("wav"), MediaType: "audio/wav"},
+				{Data: []byte("%PDF"), MediaType: "application/pdf"},
+			},
+		}, got)
+	})
+
+	t.Run("structured content", func(t *testing.T) {
+		t.Parallel()
+		got := toolResult("weather", &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: `{"temp":21,"unit":"C"}`}},
+			StructuredContent: map[string]any{"temp": 21, "unit": "C"},
+		})
+		require.Equal(t, ToolResult{Content: "{\n  \"temp\": 21,\n  \"unit\": \"C\"\n}"}, got)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		got := toolResult("weather", &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "city not found"}},
+			IsError: true,
+		})
+		require.Equal(t, ToolResult{Content: "city not found", IsError: true}, got)
+	})
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/memory"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type MemoryParams struct {
+	Action   string `json:"action" description:"The action to perform: add, update or remove"`
+	Entry    string `json:"entry,omitempty" description:"The fact to add, or the new text of the updated entry"`
+	OldEntry string `json:"old_entry,omitempty" description:"The entry to update or remove, or a unique part of it"`
+	Scope    string `json:"scope,omitempty" description:"Where to save the entry: project (default), shared with everyone working on the project, or local, for personal notes that aren't committed"`
+}
+
+type MemoryResponseMetadata struct {
+	FilePath   string `json:"file_path"`
+	Additions  int    `json:"additions"`
+	Removals   int    `json:"removals"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+}
+
+const (
+	MemoryToolName = "memory"
+
+	MemoryActionAdd    = "add"
+	MemoryActionUpdate = "update"
+	MemoryActionRemove = "remove"
+)
+
+//go:embed memory.md
+var memoryDescription []byte
+
+func NewMemoryTool(
+	permissions permission.Service,
+	files history.Service,
+	filetracker filetracker.Service,
+	workingDir string,
+	projectFile string,
+) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		MemoryToolName,
+		string(memoryDescription),
+		func(ctx context.Context, params MemoryParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			params.Action = strings.ToLower(params.Action)
+			switch params.Action {
+			case MemoryActionAdd:
+				if params.Entry == "" {
+					return fantasy.NewTextErrorResponse("entry is required to add an entry"), nil
+				}
+			case MemoryActionUpdate:
+				if params.Entry == "" || params.OldEntry == "" {
+					return fantasy.NewTextErrorResponse("entry and old_entry are required to update an entry"), nil
+				}
+			case MemoryActionRemove:
+				if params.OldEntry == "" {
+					return fantasy.NewTextErrorResponse("old_entry is required to remove an entry"), nil
+				}
+			default:
+				return fantasy.NewTextErrorResponse("action must be one of: add, update, remove"), nil
+			}
+
+			scope := memory.Scope(strings.ToLower(params.Scope))
+			switch scope {
+			case "":
+				scope = memory.ScopeProject
+			case memory.ScopeProject, memory.ScopeLocal:
+			default:
+				return fantasy.NewTextErrorResponse("scope must be one of: project, local"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for updating memory")
+			}
+
+			filePath := memory.Path(workingDir, projectFile, scope)
+			content, err := os.ReadFile(filePath)
+			if err != nil && !os.IsNotExist(err) {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to read memory file: %w", err)
+			}
+			oldContent := string(content)
+
+			var newContent string
+			switch params.Action {
+			case MemoryActionAdd:
+				newContent, err = memory.Add(oldContent, params.Entry)
+			case MemoryActionUpdate:
+				newContent, err = memory.Update(oldContent, params.OldEntry, params.Entry)
+			case MemoryActionRemove:
+				newContent, err = memory.Remove(oldContent, params.OldEntry)
+			}
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to %s entry: %s", params.Action, err)), nil
+			}
+
+			_, additions, removals := diff.GenerateDiff(
+				oldContent,
+				newContent,
+				strings.TrimPrefix(filePath, workingDir),
+			)
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        fsext.PathOrPrefix(filePath, workingDir),
+					ToolCallID:  call.ID,
+					ToolName:    MemoryToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("%s memory entry in %s", memoryActionVerb(params.Action), filePath),
+					Params: EditPermissionsParams{
+						FilePath:   filePath,
+						OldContent: oldContent,
+						NewContent: newContent,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to create directory: %w", err)
+			}
+			if err := os.WriteFile(filePath, []byte(newContent), 0o644); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to write memory file: %w", err)
+			}
+
+			recordFileVersion(ctx, files, sessionID, filePath, oldContent, newContent)
+			filetracker.RecordRead(ctx, sessionID, filePath)
+
+			text := fmt.Sprintf("<result>\nMemory updated: %s\n</result>\n", filePath)
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(text),
+				MemoryResponseMetadata{
+					FilePath:   filePath,
+					OldContent: oldContent,
+					NewContent: newContent,
+					Additions:  additions,
+					Removals:   removals,
+				},
+			), nil
+		})
+}
+
+func memoryActionVerb(action string) string {
+	switch action {
+	case MemoryActionUpdate:
+		return "Update"
+	case MemoryActionRemove:
+		return "Remove"
+	default:
+		return "Add"
+	}
+}
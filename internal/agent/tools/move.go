@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type MoveParams struct {
+	SourcePath      string `json:"source_path" description:"The path of the file or directory to move"`
+	DestinationPath string `json:"destination_path" description:"The new path of the file or directory"`
+}
+
+type MovePermissionsParams struct {
+	SourcePath      string `json:"source_path"`
+	DestinationPath string `json:"destination_path"`
+}
+
+type MoveResponseMetadata struct {
+	SourcePath      string `json:"source_path"`
+	DestinationPath string `json:"destination_path"`
+	Files           int    `json:"files"`
+}
+
+const MoveToolName = "move"
+
+//go:embed move.md
+var moveDescription []byte
+
+func NewMoveTool(
+	lspManager *lsp.Manager,
+	permissions permission.Service,
+	files history.Service,
+	filetracker filetracker.Service,
+	workingDir string,
+) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		MoveToolName,
+		string(moveDescription),
+		func(ctx context.Context, params MoveParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.SourcePath == "" {
+				return fantasy.NewTextErrorResponse("source_path is required"), nil
+			}
+			if params.DestinationPath == "" {
+				return fantasy.NewTextErrorResponse("destination_path is required"), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for moving files")
+			}
+
+			source := filepathext.SmartJoin(workingDir, params.SourcePath)
+			destination := filepathext.SmartJoin(workingDir, params.DestinationPath)
+			if isSubPath(source, workingDir) {
+				return fantasy.NewTextErrorResponse("cannot move the working directory or one of its parents"), nil
+			}
+			if resp, ok := checkFileOpPaths(source, destination); !ok {
+				return resp, nil
+			}
+
+			paths, err := listFileOpFiles(source)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("error listing files: %s", err)), nil
+			}
+
+			for _, path := range movePermissionPaths(source, destination, workingDir) {
+				p, err := permissions.Request(ctx,
+					permission.CreatePermissionRequest{
+						SessionID:   sessionID,
+						Path:        path,
+						ToolCallID:  call.ID,
+						ToolName:    MoveToolName,
+						Action:      "write",
+						Description: fmt.Sprintf("Move %s to %s", source, destination),
+						Params: MovePermissionsParams{
+							SourcePath:      source,
+							DestinationPath: destination,
+						},
+					},
+				)
+				if err != nil {
+					return fantasy.ToolResponse{}, err
+				}
+				if !p {
+					return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+				}
+			}
+
+			// Read what the history needs before the files are gone.
+			type movedFile struct {
+				from, to string
+				content  string
+				tracked  bool
+				read     bool
+			}
+			moved := make([]movedFile, 0, len(paths))
+			for _, path := range paths {
+				content, tracked := readHistoryContent(path)
+				moved = append(moved, movedFile{
+					from:    path,
+					to:      filepath.Join(destination, mustRel(source, path)),
+					content: content,
+					tracked: tracked,
+					read:    wasRead(ctx, filetracker, sessionID, path),
+				})
+			}
+
+			if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to create parent directories: %w", err)
+			}
+			if err := movePath(source, destination); err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("failed to move %s: %s", source, err)), nil
+			}
+
+			var created, deleted []string
+			for _, f := range moved {
+				if f.tracked {
+					recordFileVersion(ctx, files, sessionID, f.from, f.content, "")
+					recordFileVersion(ctx, files, sessionID, f.to, "", f.content)
+				}
+				if f.read {
+					filetracker.RecordRead(ctx, sessionID, f.to)
+				}
+				created = append(created, f.to)
+				deleted = append(deleted, f.from)
+			}
+			notifyLSPsFileOperations(ctx, lspManager, created, deleted)
+
+			result := fmt.Sprintf("<result>\nMoved %s to %s\n</result>\n", source, destination)
+			if len(moved) == 1 && moved[0].to == destination {
+				notifyLSPs(ctx, lspManager, destination)
+				result += getDiagnostics(destination, lspManager.Clients())
+			}
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(result),
+				MoveResponseMetadata{
+					SourcePath:      source,
+					DestinationPath: destination,
+					Files:           len(moved),
+				},
+			), nil
+		})
+}
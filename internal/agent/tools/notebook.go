@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// notebook is a Jupyter notebook, as stored in .ipynb files.
+type notebook struct {
+	Cells         []notebookCell `json:"cells"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+type notebookCell struct {
+	CellType       string           `json:"cell_type"`
+	Source         notebookText     `json:"source"`
+	ExecutionCount *int             `json:"execution_count,omitempty"`
+	Outputs        []notebookOutput `json:"outputs,omitempty"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Name       string                  `json:"name,omitempty"`
+	Text       notebookText            `json:"text,omitempty"`
+	Data       map[string]notebookText `json:"data,omitempty"`
+	EName      string                  `json:"ename,omitempty"`
+	EValue     string                  `json:"evalue,omitempty"`
+	Traceback  []string                `json:"traceback,omitempty"`
+}
+
+// notebookText is a multiline string, which notebooks store either as a
+// string or as a list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+// notebookImage is an image output of a notebook cell.
+type notebookImage struct {
+	Cell      int
+	MediaType string
+	Data      string // base64
+}
+
+// notebookImageTypes are the image output types passed to the model, in
+// order of preference.
+var notebookImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// maxNotebookOutputLength is the maximum length of a text output of a cell.
+const maxNotebookOutputLength = 10000
+
+func isNotebook(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".ipynb")
+}
+
+func parseNotebook(data []byte) (*notebook, error) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+	return &nb, nil
+}
+
+// renderNotebook renders limit cells of nb starting at offset, with their
+// outputs. It returns the rendered cells and the image outputs found.
+func renderNotebook(nb *notebook, offset, limit int) (string, []notebookImage) {
+	var (
+		sb     strings.Builder
+		images []notebookImage
+	)
+	offset = max(offset, 0)
+	end := min(offset+limit, len(nb.Cells))
+	for i := offset; i < end; i++ {
+		cell := nb.Cells[i]
+		if i > offset {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "<cell index=\"%d\" type=%q", i, cell.CellType)
+		if cell.ExecutionCount != nil {
+			fmt.Fprintf(&sb, " execution_count=\"%d\"", *cell.ExecutionCount)
+		}
+		sb.WriteString(">\n")
+		if source := strings.TrimRight(string(cell.Source), "\n"); source != "" {
+			sb.WriteString(source)
+			sb.WriteString("\n")
+		}
+		for _, output := range cell.Outputs {
+			text, image := renderNotebookOutput(output)
+			if image != nil {
+				image.Cell = i
+				images = append(images, *image)
+			}
+			if text == "" {
+				continue
+			}
+			sb.WriteString("<output>\n")
+			sb.WriteString(text)
+			sb.WriteString("\n</output>\n")
+		}
+		sb.WriteString("</cell>\n")
+	}
+	return sb.String(), images
+}
+
+func renderNotebookOutput(output notebookOutput) (string, *notebookImage) {
+	var text string
+	switch output.OutputType {
+	case "stream":
+		text = string(output.Text)
+	case "error":
+		text = output.EName + ": " + output.EValue
+		if len(output.Traceback) > 0 {
+			text = stripANSI(strings.Join(output.Traceback, "\n"))
+		}
+	case "execute_result", "display_data":
+		for _, mediaType := range notebookImageTypes {
+			if data, ok := output.Data[mediaType]; ok {
+				image := &notebookImage{
+					MediaType: mediaType,
+					Data:      strings.ReplaceAll(string(data), "\n", ""),
+				}
+				return fmt.Sprintf("[%s output]", mediaType), image
+			}
+		}
+		text = string(output.Data["text/plain"])
+	}
+	text = strings.TrimRight(text, "\n")
+	if len(text) > maxNotebookOutputLength {
+		text = text[:maxNotebookOutputLength] + "\n... (output truncated)"
+	}
+	return text, nil
+}
+
+// stripANSI removes the color escape sequences of tracebacks.
+func stripANSI(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// Notebook edit modes.
+const (
+	NotebookEditReplace = "replace"
+	NotebookEditInsert  = "insert"
+	NotebookEditDelete  = "delete"
+)
+
+// editNotebook applies an edit to the raw notebook data, keeping all the
+// fields it doesn't know about. It returns the new notebook data, along with
+// the source of the edited cell before and after the edit.
+func editNotebook(data []byte, params NotebookEditParams) (newData []byte, oldSource, newSource string, err error) {
+	var nb map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as they are, e.g. execution counts.
+	dec.UseNumber()
+	if err := dec.Decode(&nb); err != nil {
+		return nil, "", "", fmt.Errorf("invalid notebook: %w", err)
+	}
+	rawCells, _ := nb["cells"].([]any)
+
+	index := params.CellIndex
+	maxIndex := len(rawCells) - 1
+	if params.EditMode == NotebookEditInsert {
+		maxIndex = len(rawCells)
+	}
+	if index < 0 || index > maxIndex {
+		return nil, "", "", fmt.Errorf("cell_index %d is out of range, the notebook has %d cells", index, len(rawCells))
+	}
+
+	switch params.EditMode {
+	case NotebookEditDelete:
+		cell, _ := rawCells[index].(map[string]any)
+		oldSource = cellSource(cell)
+		rawCells = append(rawCells[:index], rawCells[index+1:]...)
+	case NotebookEditInsert:
+		cell := map[string]any{
+			"metadata": map[string]any{},
+		}
+		if hasCellIDs(nb) {
+			cell["id"] = newCellID()
+		}
+		setCellType(cell, params.CellType)
+		setCellSource(cell, params.NewSource)
+		newSource = params.NewSource
+		rawCells = append(rawCells[:index], append([]any{cell}, rawCells[index:]...)...)
+	default:
+		cell, ok := rawCells[index].(map[string]any)
+		if !ok {
+			return nil, "", "", fmt.Errorf("cell %d is invalid", index)
+		}
+		oldSource = cellSource(cell)
+		if params.CellType != "" {
+			setCellType(cell, params.CellType)
+		} else if cell["cell_type"] == "code" {
+			// The outputs are stale once the source changes.
+			setCellType(cell, "code")
+		}
+		setCellSource(cell, params.NewSource)
+		newSource = params.NewSource
+	}
+	nb["cells"] = rawCells
+
+	// Write the notebook the way Jupyter does, so edits don't cause
+	// unrelated diffs.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(nb); err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode notebook: %w", err)
+	}
+	return buf.Bytes(), oldSource, newSource, nil
+}
+
+func cellSource(cell map[string]any) string {
+	switch source := cell["source"].(type) {
+	case string:
+		return source
+	case []any:
+		var sb strings.Builder
+		for _, line := range source {
+			s, _ := line.(string)
+			sb.WriteString(s)
+		}
+		return sb.String()
+	}
+	return ""
+}
+
+// setCellSource sets the source of a cell as a list of lines, like Jupyter.
+func setCellSource(cell map[string]any, source string) {
+	lines := []any{}
+	for line := range strings.SplitAfterSeq(source, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	cell["source"] = lines
+}
+
+// setCellType sets the type of a cell, adding or removing the fields that
+// only code cells have. Outputs of code cells are cleared.
+func setCellType(cell map[string]any, cellType string) {
+	if cellType == "" {
+		cellType = "code"
+	}
+	cell["cell_type"] = cellType
+	if cellType == "code" {
+		cell["execution_count"] = nil
+		cell["outputs"] = []any{}
+		return
+	}
+	delete(cell, "execution_count")
+	delete(cell, "outputs")
+}
+
+// hasCellIDs reports whether the notebook format requires cell IDs, which
+// were introduced in nbformat 4.5.
+func hasCellIDs(nb map[string]any) bool {
+	major, minor := notebookInt(nb["nbformat"]), notebookInt(nb["nbformat_minor"])
+	return major > 4 || (major == 4 && minor >= 5)
+}
+
+func notebookInt(v any) int64 {
+	n, _ := v.(json.Number)
+	i, _ := n.Int64()
+	return i
+}
+
+func newCellID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/filetracker"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type NotebookEditParams struct {
+	FilePath  string `json:"file_path" description:"The absolute path to the Jupyter notebook (.ipynb) to modify"`
+	CellIndex int    `json:"cell_index" description:"The 0-based index of the cell to edit. For insert, the new cell is inserted at this index"`
+	NewSource string `json:"new_source,omitempty" description:"The new source of the cell (not needed for delete)"`
+	CellType  string `json:"cell_type,omitempty" description:"The type of the cell: code or markdown. Required for insert, defaults to the current type for replace"`
+	EditMode  string `json:"edit_mode,omitempty" description:"The kind of edit: replace, insert or delete (default replace)"`
+}
+
+const NotebookEditToolName = "notebook_edit"
+
+//go:embed notebook_edit.md
+var notebookEditDescription []byte
+
+func NewNotebookEditTool(
+	lspManager *lsp.Manager,
+	permissions permission.Service,
+	files history.Service,
+	filetracker filetracker.Service,
+	workingDir string,
+) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		NotebookEditToolName,
+		string(notebookEditDescription),
+		func(ctx context.Context, params NotebookEditParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.FilePath == "" {
+				return fantasy.NewTextErrorResponse("file_path is required"), nil
+			}
+			if !isNotebook(params.FilePath) {
+				return fantasy.NewTextErrorResponse("file_path must be a Jupyter notebook (.ipynb). Use the Edit tool for other files"), nil
+			}
+
+			params.EditMode = strings.ToLower(params.EditMode)
+			switch params.EditMode {
+			case "":
+				params.EditMode = NotebookEditReplace
+			case NotebookEditReplace, NotebookEditInsert, NotebookEditDelete:
+			default:
+				return fantasy.NewTextErrorResponse("edit_mode must be one of: replace, insert, delete"), nil
+			}
+			switch params.CellType {
+			case "", "code", "markdown", "raw":
+			default:
+				return fantasy.NewTextErrorResponse("cell_type must be one of: code, markdown"), nil
+			}
+			if params.EditMode == NotebookEditInsert && params.CellType == "" {
+				return fantasy.NewTextErrorResponse("cell_type is required when inserting a cell"), nil
+			}
+
+			filePath := filepathext.SmartJoin(workingDir, params.FilePath)
+
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+				}
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+			}
+			if fileInfo.IsDir() {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+			}
+
+			sessionID := GetSessionFromContext(ctx)
+			if sessionID == "" {
+				return fantasy.ToolResponse{}, fmt.Errorf("session ID is required for editing a notebook")
+			}
+
+			lastRead := filetracker.LastReadTime(ctx, sessionID, filePath)
+			if lastRead.IsZero() {
+				return fantasy.NewTextErrorResponse("you must read the notebook before editing it. Use the View tool first"), nil
+			}
+
+			if resp, ok := checkExternalChange(ctx, filetracker, sessionID, filePath, workingDir); !ok {
+				return resp, nil
+			}
+
+			modTime := fileInfo.ModTime().Truncate(time.Second)
+			if modTime.After(lastRead) {
+				return fantasy.NewTextErrorResponse(
+					fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+						filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+					)), nil
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+			}
+			oldContent := string(content)
+
+			newData, oldSource, newSource, err := editNotebook(content, params)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			newContent := string(newData)
+
+			verb, pastVerb := notebookEditVerbs(params.EditMode)
+			_, additions, removals := diff.GenerateDiff(
+				oldSource,
+				newSource,
+				strings.TrimPrefix(filePath, workingDir),
+			)
+
+			p, err := permissions.Request(ctx,
+				permission.CreatePermissionRequest{
+					SessionID:   sessionID,
+					Path:        fsext.PathOrPrefix(filePath, workingDir),
+					ToolCallID:  call.ID,
+					ToolName:    NotebookEditToolName,
+					Action:      "write",
+					Description: fmt.Sprintf("%s cell %d of notebook %s", verb, params.CellIndex, filePath),
+					Params: EditPermissionsParams{
+						FilePath:   filePath,
+						OldContent: oldSource,
+						NewContent: newSource,
+					},
+				},
+			)
+			if err != nil {
+				return fantasy.ToolResponse{}, err
+			}
+			if !p {
+				return fantasy.ToolResponse{}, permission.ErrorPermissionDenied
+			}
+
+			if err := os.WriteFile(filePath, newData, 0o644); err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+			}
+
+			// Check if file exists in history
+			file, err := files.GetByPathAndSession(ctx, filePath, sessionID)
+			if err != nil {
+				_, err = files.Create(ctx, sessionID, filePath, oldContent)
+				if err != nil {
+					return fantasy.ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
+				}
+			}
+			if file.Content != oldContent {
+				// User manually changed the content; store an intermediate version
+				_, err = files.CreateVersion(ctx, sessionID, filePath, oldContent)
+				if err != nil {
+					slog.Error("Error creating file history version", "error", err)
+				}
+			}
+			// Store the new version
+			_, err = files.CreateVersion(ctx, sessionID, filePath, newContent)
+			if err != nil {
+				slog.Error("Error creating file history version", "error", err)
+			}
+
+			filetracker.RecordRead(ctx, sessionID, filePath)
+			notifyLSPs(ctx, lspManager, filePath)
+
+			text := fmt.Sprintf("<result>\n%s cell %d of notebook: %s\n</result>\n", pastVerb, params.CellIndex, filePath)
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(text),
+				EditResponseMetadata{
+					OldContent: oldSource,
+					NewContent: newSource,
+					Additions:  additions,
+					Removals:   removals,
+				},
+			), nil
+		})
+}
+
+// notebookEditVerbs returns the verb describing an edit mode, in the present
+// and past tenses.
+func notebookEditVerbs(mode string) (present, past string) {
+	switch mode {
+	case NotebookEditInsert:
+		return "Insert", "Inserted"
+	case NotebookEditDelete:
+		return "Delete", "Deleted"
+	default:
+		return "Replace", "Replaced"
+	}
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "a1",
+   "metadata": {},
+   "source": [
+    "# Title\n",
+    "Some <b>text</b>"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "id": "a2",
+   "metadata": {
+    "tags": ["x"]
+   },
+   "outputs": [
+    {
+     "name": "stdout",
+     "output_type": "stream",
+     "text": ["hello\n"]
+    },
+    {
+     "data": {
+      "image/png": "aGVsbG8=\n",
+      "text/plain": ["<Figure>"]
+     },
+     "metadata": {},
+     "output_type": "display_data"
+    }
+   ],
+   "source": "print('hello')"
+  }
+ ],
+ "metadata": {
+  "kernelspec": {
+   "name": "python3"
+  }
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestRenderNotebook(t *testing.T) {
+	t.Parallel()
+
+	nb, err := parseNotebook([]byte(testNotebook))
+	require.NoError(t, err)
+
+	content, images := renderNotebook(nb, 0, 10)
+	require.Equal(t, `<cell index="0" type="markdown">
+# Title
+Some <b>text</b>
+</cell>
+
+<cell index="1" type="code" execution_count="3">
+print('hello')
+<output>
+hello
+</output>
+<output>
+[image/png output]
+</output>
+</cell>
+`, content)
+	require.Equal(t, []notebookImage{{Cell: 1, MediaType: "image/png", Data: "aGVsbG8="}}, images)
+
+	content, images = renderNotebook(nb, 1, 1)
+	require.True(t, strings.HasPrefix(content, `<cell index="1"`))
+	require.Len(t, images, 1)
+
+	content, _ = renderNotebook(nb, -1, 2000)
+	require.True(t, strings.HasPrefix(content, `<cell index="0"`), "negative offsets start at the first cell")
+}
+
+func TestEditNotebook(t *testing.T) {
+	t.Parallel()
+
+	cells := func(t *testing.T, data []byte) []map[string]any {
+		var nb struct {
+			Cells []map[string]any `json:"cells"`
+		}
+		require.NoError(t, json.Unmarshal(data, &nb))
+		return nb.Cells
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		data, oldSource, newSource, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 1,
+			NewSource: "x = 1\nx",
+			EditMode:  NotebookEditReplace,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "print('hello')", oldSource)
+		require.Equal(t, "x = 1\nx", newSource)
+
+		cell := cells(t, data)[1]
+		require.Equal(t, []any{"x = 1\n", "x"}, cell["source"])
+		require.Equal(t, []any{}, cell["outputs"])
+		require.Nil(t, cell["execution_count"])
+		require.Equal(t, "a2", cell["id"])
+		require.Equal(t, map[string]any{"tags": []any{"x"}}, cell["metadata"])
+
+		// Unrelated content is kept as is.
+		require.Contains(t, string(data), `"Some <b>text</b>"`)
+		require.Contains(t, string(data), "\n  \"kernelspec\": {\n   \"name\": \"python3\"\n  }")
+	})
+
+	t.Run("replace with another type", func(t *testing.T) {
+		t.Parallel()
+		data, _, _, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 1,
+			NewSource: "Now text",
+			CellType:  "markdown",
+		})
+		require.NoError(t, err)
+
+		cell := cells(t, data)[1]
+		require.Equal(t, "markdown", cell["cell_type"])
+		require.NotContains(t, cell, "outputs")
+		require.NotContains(t, cell, "execution_count")
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+		data, oldSource, newSource, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 2,
+			NewSource: "print(2)",
+			CellType:  "code",
+			EditMode:  NotebookEditInsert,
+		})
+		require.NoError(t, err)
+		require.Empty(t, oldSource)
+		require.Equal(t, "print(2)", newSource)
+
+		all := cells(t, data)
+		require.Len(t, all, 3)
+		require.Equal(t, "code", all[2]["cell_type"])
+		require.Equal(t, []any{"print(2)"}, all[2]["source"])
+		require.Len(t, all[2]["id"], 8)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		data, oldSource, _, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 0,
+			EditMode:  NotebookEditDelete,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "# Title\nSome <b>text</b>", oldSource)
+
+		all := cells(t, data)
+		require.Len(t, all, 1)
+		require.Equal(t, "a2", all[0]["id"])
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Parallel()
+		_, _, _, err := editNotebook([]byte(testNotebook), NotebookEditParams{
+			CellIndex: 2,
+			EditMode:  NotebookEditReplace,
+		})
+		require.EqualError(t, err, "cell_index 2 is out of range, the notebook has 2 cells")
+	})
+}
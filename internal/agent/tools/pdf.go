@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// isPDF reports whether data is a PDF document, based on its content type
+// or, failing that, its magic number.
+func isPDF(data []byte, contentType string) bool {
+	return strings.Contains(contentType, "application/pdf") || bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// readPDFPages extracts the plain text of every page of a PDF document.
+func readPDFPages(data []byte) (pages []string, err error) {
+	// The PDF parser panics on some malformed documents.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		text, err := reader.Page(i).GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract text from page %d: %w", i, err)
+		}
+		pages = append(pages, strings.TrimSpace(text))
+	}
+	return pages, nil
+}
+
+// formatPDFPages joins pages with page markers. first is the page number of
+// pages[0], starting at 1.
+func formatPDFPages(pages []string, first int) string {
+	var sb strings.Builder
+	for i, page := range pages {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "--- Page %d ---\n", first+i)
+		if page == "" {
+			sb.WriteString("[No extractable text]")
+			continue
+		}
+		sb.WriteString(page)
+	}
+	return sb.String()
+}
+
+// extractPDFText extracts the text of a whole PDF document, with page
+// markers.
+func extractPDFText(data []byte) (string, error) {
+	pages, err := readPDFPages(data)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", fmt.Errorf("PDF has no pages")
+	}
+	return formatPDFPages(pages, 1), nil
+}
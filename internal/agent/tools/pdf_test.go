@@ -0,0 +1,23 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFPageEnd(t *testing.T) {
+	t.Parallel()
+
+	pages := make([]string, 100)
+	require.Equal(t, 3, pdfPageEnd(pages, 0, 3))
+	require.Equal(t, MaxPDFPages, pdfPageEnd(pages, 0, DefaultReadLimit))
+	require.Equal(t, 100, pdfPageEnd(pages, 90, DefaultReadLimit))
+
+	large := strings.Repeat("x", MaxPDFViewSize/2+1)
+	pages = []string{large, large, large}
+	require.Equal(t, 1, pdfPageEnd(pages, 0, DefaultReadLimit), "stops before the text gets too large")
+	pages = []string{large + large}
+	require.Equal(t, 1, pdfPageEnd(pages, 0, DefaultReadLimit), "always shows one page")
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+)
+
+type ReadMCPResourceParams struct {
+	MCP string `json:"mcp,omitempty" description:"The name of the MCP server the resource belongs to"`
+	URI string `json:"uri,omitempty" description:"The URI of the resource to read, omit it to list the available resources and resource templates"`
+}
+
+const ReadMCPResourceToolName = "read_mcp_resource"
+
+//go:embed read_mcp_resource.md
+var readMCPResourceDescription []byte
+
+// NewReadMCPResourceTool returns a tool that lists and reads the resources of
+// the MCP servers in allowedMCP, or of every server when it's nil.
+func NewReadMCPResourceTool(allowedMCP map[string][]string) fantasy.AgentTool {
+	allowed := func(name string) bool {
+		if allowedMCP == nil {
+			return true
+		}
+		_, ok := allowedMCP[name]
+		return ok
+	}
+
+	return fantasy.NewAgentTool(
+		ReadMCPResourceToolName,
+		string(readMCPResourceDescription),
+		func(ctx context.Context, params ReadMCPResourceParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if params.MCP != "" && !allowed(params.MCP) {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("MCP server %q is not available", params.MCP)), nil
+			}
+			if params.URI == "" {
+				return fantasy.NewTextResponse(listMCPResources(params.MCP, allowed)), nil
+			}
+			if params.MCP == "" {
+				return fantasy.NewTextErrorResponse("mcp is required to read a resource"), nil
+			}
+
+			contents, err := mcp.ReadResource(ctx, params.MCP, params.URI)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(err.Error()), nil
+			}
+			if len(contents) == 0 {
+				return fantasy.NewTextResponse("The resource is empty."), nil
+			}
+
+			var texts []string
+			for _, c := range contents {
+				if c.Blob == nil {
+					texts = append(texts, c.Text)
+				}
+			}
+			if len(texts) > 0 {
+				return fantasy.NewTextResponse(strings.Join(texts, "\n")), nil
+			}
+
+			blob := contents[0]
+			mediaType := cmp.Or(blob.MIMEType, "application/octet-stream")
+			if !GetSupportsImagesFromContext(ctx) {
+				modelName := GetModelNameFromContext(ctx)
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("The resource is %s data, which this model (%s) does not support.", mediaType, modelName)), nil
+			}
+			if strings.HasPrefix(mediaType, "image/") {
+				return fantasy.NewImageResponse(blob.Blob, mediaType), nil
+			}
+			return fantasy.NewMediaResponse(blob.Blob, mediaType), nil
+		})
+}
+
+// listMCPResources lists the resources and resource templates of the MCP
+// server name, or of all the allowed servers when it's empty.
+func listMCPResources(name string, allowed func(string) bool) string {
+	resources := make(map[string][]*mcp.Resource)
+	for server, rs := range mcp.Resources() {
+		resources[server] = rs
+	}
+	templates := make(map[string][]*mcp.ResourceTemplate)
+	for server, ts := range mcp.ResourceTemplates() {
+		templates[server] = ts
+	}
+
+	servers := slices.Sorted(maps.Keys(resources))
+	for server := range templates {
+		if _, ok := resources[server]; !ok {
+			servers = append(servers, server)
+		}
+	}
+	slices.Sort(servers)
+
+	var sb strings.Builder
+	for _, server := range servers {
+		if (name != "" && server != name) || !allowed(server) {
+			continue
+		}
+		fmt.Fprintf(&sb, "<mcp name=%q>\n", server)
+		for _, r := range resources[server] {
+			fmt.Fprintf(&sb, "- %s", r.URI)
+			writeResourceDetails(&sb, cmp.Or(r.Title, r.Name), r.MIMEType, r.Description)
+		}
+		for _, t := range templates[server] {
+			fmt.Fprintf(&sb, "- %s (template)", t.URITemplate)
+			writeResourceDetails(&sb, cmp.Or(t.Title, t.Name), t.MIMEType, t.Description)
+		}
+		sb.WriteString("</mcp>\n")
+	}
+	if sb.Len() == 0 {
+		return "No MCP resources available."
+	}
+	return sb.String()
+}
+
+func writeResourceDetails(sb *strings.Builder, title, mimeType, description string) {
+	if title != "" {
+		fmt.Fprintf(sb, ": %s", title)
+	}
+	if mimeType != "" {
+		fmt.Fprintf(sb, " [%s]", mimeType)
+	}
+	if description != "" {
+		fmt.Fprintf(sb, " - %s", strings.Join(strings.Fields(description), " "))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/filepathext"
+	"github.com/charmbracelet/crush/internal/repomap"
+)
+
+const RepoMapToolName = "repo_map"
+
+//go:embed repo_map.md
+var repoMapDescription []byte
+
+type RepoMapParams struct {
+	Path      string `json:"path,omitempty" description:"The directory to map. Defaults to the current working directory."`
+	MaxTokens int    `json:"max_tokens,omitempty" description:"Optional token budget of the map (default: 4096, max: 16384)"`
+}
+
+type RepoMapResponseMetadata struct {
+	NumberOfFiles int `json:"number_of_files"`
+}
+
+const maxRepoMapTokens = 16384
+
+func NewRepoMapTool(workingDir string, cfg config.ToolRepoMap) fantasy.AgentTool {
+	defaultTokens, _ := cfg.Limits()
+	return fantasy.NewAgentTool(
+		RepoMapToolName,
+		string(repoMapDescription),
+		func(ctx context.Context, params RepoMapParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			searchPath := filepathext.SmartJoin(workingDir, cmp.Or(params.Path, "."))
+			rel, err := filepath.Rel(workingDir, searchPath)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return fantasy.NewTextErrorResponse("path must be inside the working directory"), nil
+			}
+
+			maxTokens := min(cmp.Or(params.MaxTokens, defaultTokens), maxRepoMapTokens)
+
+			files, err := repomap.Rank(ctx, workingDir, searchPath)
+			if err != nil {
+				return fantasy.ToolResponse{}, fmt.Errorf("error building repository map: %w", err)
+			}
+			if len(files) == 0 {
+				return fantasy.NewTextResponse("No symbols found"), nil
+			}
+
+			return fantasy.WithResponseMetadata(
+				fantasy.NewTextResponse(repomap.Render(files, maxTokens)),
+				RepoMapResponseMetadata{NumberOfFiles: len(files)},
+			), nil
+		})
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	_ "embed"
 	"encoding/json"
@@ -9,9 +10,14 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
 )
 
 type SourcegraphParams struct {
@@ -28,13 +34,173 @@ type SourcegraphResponseMetadata struct {
 
 const SourcegraphToolName = "sourcegraph"
 
-//go:embed sourcegraph.md
-var sourcegraphDescription []byte
+const (
+	publicSourcegraphURL      = "https://sourcegraph.com"
+	defaultSourcegraphTimeout = 30 * time.Second
+)
+
+//go:embed sourcegraph.tpl
+var sourcegraphDescriptionTmpl []byte
+
+var sourcegraphDescriptionTpl = template.Must(
+	template.New("sourcegraphDescription").
+		Parse(string(sourcegraphDescriptionTmpl)),
+)
+
+// SourcegraphInstance is the Sourcegraph instance searched by the
+// sourcegraph tool.
+type SourcegraphInstance struct {
+	URL         string
+	AccessToken string
+	RepoFilters []string
+	Timeout     time.Duration
+}
+
+// NewSourcegraphInstance creates an instance from the config, resolving its
+// access token with the given resolver. It defaults to the public
+// sourcegraph.com instance.
+func NewSourcegraphInstance(cfg config.ToolSourcegraph, resolver config.VariableResolver) (SourcegraphInstance, error) {
+	instance := SourcegraphInstance{
+		URL:         strings.TrimSuffix(cmp.Or(cfg.URL, publicSourcegraphURL), "/"),
+		RepoFilters: cfg.RepoFilters,
+		Timeout:     defaultSourcegraphTimeout,
+	}
+	if cfg.Timeout != nil && *cfg.Timeout > 0 {
+		instance.Timeout = time.Duration(*cfg.Timeout) * time.Second
+	}
+	if cfg.AccessToken != "" {
+		if resolver == nil {
+			return SourcegraphInstance{}, fmt.Errorf("no variable resolver configured for sourcegraph access token")
+		}
+		token, err := resolver.ResolveValue(cfg.AccessToken)
+		if err != nil {
+			return SourcegraphInstance{}, fmt.Errorf("failed to resolve sourcegraph access token: %w", err)
+		}
+		instance.AccessToken = token
+	}
+	return instance, nil
+}
+
+// IsPublic reports whether this is the public sourcegraph.com instance.
+func (s SourcegraphInstance) IsPublic() bool {
+	return s.URL == "" || s.URL == publicSourcegraphURL
+}
+
+func (s SourcegraphInstance) url() string {
+	return cmp.Or(s.URL, publicSourcegraphURL)
+}
+
+// query adds the default repo filters to query, unless it already filters
+// by repository.
+func (s SourcegraphInstance) query(query string) string {
+	if len(s.RepoFilters) == 0 {
+		return query
+	}
+	for field := range strings.FieldsSeq(query) {
+		field = strings.TrimLeft(field, "-(")
+		if strings.HasPrefix(field, "repo:") || strings.HasPrefix(field, "r:") {
+			return query
+		}
+	}
+	if len(s.RepoFilters) == 1 {
+		return "repo:" + s.RepoFilters[0] + " " + query
+	}
+	return "repo:(" + strings.Join(s.RepoFilters, "|") + ") " + query
+}
+
+// do sends a GraphQL request to the instance.
+func (s SourcegraphInstance) do(ctx context.Context, client *http.Client, query string, variables any) (*http.Response, error) {
+	graphqlQueryBytes, err := json.Marshal(struct {
+		Query     string `json:"query"`
+		Variables any    `json:"variables,omitempty"`
+	}{query, variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		s.url()+"/.api/graphql",
+		bytes.NewBuffer(graphqlQueryBytes),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "crush/1.0")
+	if s.AccessToken != "" {
+		req.Header.Set("Authorization", "token "+s.AccessToken)
+	}
 
-func NewSourcegraphTool(client *http.Client) fantasy.AgentTool {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	return resp, nil
+}
+
+func sourcegraphDescription(instance SourcegraphInstance) string {
+	var out bytes.Buffer
+	if err := sourcegraphDescriptionTpl.Execute(&out, struct {
+		URL         string
+		Public      bool
+		RepoFilters string
+	}{
+		URL:         instance.url(),
+		Public:      instance.IsPublic(),
+		RepoFilters: strings.Join(instance.RepoFilters, ", "),
+	}); err != nil {
+		// this should never happen.
+		panic("failed to execute sourcegraph description template: " + err.Error())
+	}
+	return out.String()
+}
+
+// sourcegraphChecks holds whether the instances could be reached the first
+// time they were searched, by URL, so each one is only checked once per
+// process.
+var sourcegraphChecks = csync.NewMap[string, *sourcegraphCheck]()
+
+type sourcegraphCheck struct {
+	once sync.Once
+	done atomic.Bool
+	err  error
+}
+
+// recordSourcegraphCheck records whether the instance at url could be
+// reached, unless it was already checked. Requests cut short by ctx don't
+// count.
+func recordSourcegraphCheck(ctx context.Context, url string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	check := sourcegraphChecks.GetOrSet(url, func() *sourcegraphCheck {
+		return &sourcegraphCheck{}
+	})
+	check.once.Do(func() {
+		check.err = err
+		check.done.Store(true)
+	})
+}
+
+// SourcegraphUnreachable reports whether the instance in cfg was found to be
+// unreachable, in which case the sourcegraph tool should be left out.
+// Instances that weren't checked yet are assumed to be reachable.
+func SourcegraphUnreachable(cfg config.ToolSourcegraph) bool {
+	check, ok := sourcegraphChecks.Get(strings.TrimSuffix(cmp.Or(cfg.URL, publicSourcegraphURL), "/"))
+	return ok && check.done.Load() && check.err != nil
+}
+
+// NewSourcegraphTool creates the sourcegraph tool for the instance in cfg.
+// The instance is only resolved when the tool is called, so nothing is sent
+// over the network until then. When the first search can't reach it,
+// onUnreachable is called so the tool can be left out.
+func NewSourcegraphTool(client *http.Client, cfg config.ToolSourcegraph, resolver config.VariableResolver, onUnreachable func()) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: 2 * time.Minute,
 			Transport: &http.Transport{
 				MaxIdleConns:        100,
 				MaxIdleConnsPerHost: 10,
@@ -44,12 +210,20 @@ func NewSourcegraphTool(client *http.Client) fantasy.AgentTool {
 	}
 	return fantasy.NewParallelAgentTool(
 		SourcegraphToolName,
-		string(sourcegraphDescription),
+		sourcegraphDescription(SourcegraphInstance{
+			URL:         strings.TrimSuffix(cmp.Or(cfg.URL, publicSourcegraphURL), "/"),
+			RepoFilters: cfg.RepoFilters,
+		}),
 		func(ctx context.Context, params SourcegraphParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
 			if params.Query == "" {
 				return fantasy.NewTextErrorResponse("Query parameter is required"), nil
 			}
 
+			instance, err := NewSourcegraphInstance(cfg, resolver)
+			if err != nil {
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Sourcegraph is misconfigured: %s", err)), nil
+			}
+
 			if params.Count <= 0 {
 				params.Count = 10
 			} else if params.Count > 20 {
@@ -61,51 +235,30 @@ func NewSourcegraphTool(client *http.Client) fantasy.AgentTool {
 			}
 
 			// Handle timeout with context
-			requestCtx := ctx
+			timeout := cmp.Or(instance.Timeout, defaultSourcegraphTimeout)
 			if params.Timeout > 0 {
 				maxTimeout := 120 // 2 minutes
 				if params.Timeout > maxTimeout {
 					params.Timeout = maxTimeout
 				}
-				var cancel context.CancelFunc
-				requestCtx, cancel = context.WithTimeout(ctx, time.Duration(params.Timeout)*time.Second)
-				defer cancel()
-			}
-
-			type graphqlRequest struct {
-				Query     string `json:"query"`
-				Variables struct {
-					Query string `json:"query"`
-				} `json:"variables"`
-			}
-
-			request := graphqlRequest{
-				Query: "query Search($query: String!) { search(query: $query, version: V2, patternType: keyword ) { results { matchCount, limitHit, resultCount, approximateResultCount, missing { name }, timedout { name }, indexUnavailable, results { __typename, ... on FileMatch { repository { name }, file { path, url, content }, lineMatches { preview, lineNumber, offsetAndLengths } } } } } }",
-			}
-			request.Variables.Query = params.Query
-
-			graphqlQueryBytes, err := json.Marshal(request)
-			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("failed to marshal GraphQL request: %w", err)
+				timeout = time.Duration(params.Timeout) * time.Second
 			}
-			graphqlQuery := string(graphqlQueryBytes)
+			requestCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
 
-			req, err := http.NewRequestWithContext(
+			resp, err := instance.do(
 				requestCtx,
-				"POST",
-				"https://sourcegraph.com/.api/graphql",
-				bytes.NewBuffer([]byte(graphqlQuery)),
+				client,
+				"query Search($query: String!) { search(query: $query, version: V2, patternType: keyword ) { results { matchCount, limitHit, resultCount, approximateResultCount, missing { name }, timedout { name }, indexUnavailable, results { __typename, ... on FileMatch { repository { name }, file { path, url, content }, lineMatches { preview, lineNumber, offsetAndLengths } } } } } }",
+				map[string]string{"query": instance.query(params.Query)},
 			)
+			recordSourcegraphCheck(ctx, instance.url(), err)
 			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("failed to create request: %w", err)
-			}
-
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("User-Agent", "crush/1.0")
-
-			resp, err := client.Do(req)
-			if err != nil {
-				return fantasy.ToolResponse{}, fmt.Errorf("failed to fetch URL: %w", err)
+				if SourcegraphUnreachable(cfg) && onUnreachable != nil {
+					onUnreachable()
+					return fantasy.NewTextErrorResponse(fmt.Sprintf("Sourcegraph at %s can't be reached, the sourcegraph tool is disabled: %s", instance.url(), err)), nil
+				}
+				return fantasy.NewTextErrorResponse(fmt.Sprintf("Failed to reach Sourcegraph at %s: %s", instance.url(), err)), nil
 			}
 			defer resp.Body.Close()
 
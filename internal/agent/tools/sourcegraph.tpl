@@ -1,4 +1,12 @@
+{{- if .Public -}}
 Search code across public repositories using Sourcegraph's GraphQL API.
+{{- else -}}
+Search code across the repositories of the Sourcegraph instance at {{ .URL }} using its GraphQL API.
+{{- end }}
+{{- if .RepoFilters }}
+
+Queries without a repo: filter are limited to repositories matching: {{ .RepoFilters }}
+{{- end }}
 
 <usage>
 - Provide search query using Sourcegraph syntax
@@ -41,7 +49,9 @@ Result: select:repo, select:file, select:content, count:100, timeout:30s
 </boolean_operators>
 
 <limitations>
+{{- if .Public }}
 - Only searches public repositories
+{{- end }}
 - Rate limits may apply
 - Complex queries take longer
 - Max 20 results per query
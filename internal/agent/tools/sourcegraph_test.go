@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSourcegraphInstance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults to sourcegraph.com", func(t *testing.T) {
+		t.Parallel()
+		instance, err := NewSourcegraphInstance(config.ToolSourcegraph{}, nil)
+		require.NoError(t, err)
+		require.True(t, instance.IsPublic())
+		require.Equal(t, defaultSourcegraphTimeout, instance.Timeout)
+	})
+
+	t.Run("self-hosted", func(t *testing.T) {
+		t.Parallel()
+		timeout := 60
+		resolver := config.NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{
+			"SRC_ACCESS_TOKEN": "sgp_secret",
+		}))
+		instance, err := NewSourcegraphInstance(config.ToolSourcegraph{
+			URL:         "https://sourcegraph.example.com/",
+			AccessToken: "$SRC_ACCESS_TOKEN",
+			Timeout:     &timeout,
+		}, resolver)
+		require.NoError(t, err)
+		require.False(t, instance.IsPublic())
+		require.Equal(t, "https://sourcegraph.example.com", instance.URL)
+		require.Equal(t, "sgp_secret", instance.AccessToken)
+		require.Equal(t, time.Minute, instance.Timeout)
+		require.Contains(t, sourcegraphDescription(instance), "https://sourcegraph.example.com")
+	})
+}
+
+func TestSourcegraphInstanceQuery(t *testing.T) {
+	t.Parallel()
+
+	instance := SourcegraphInstance{RepoFilters: []string{"^acme/", "^tools/"}}
+	require.Equal(t, "repo:(^acme/|^tools/) fmt.Println", instance.query("fmt.Println"))
+	require.Equal(t, "repo:^other$ fmt.Println", instance.query("repo:^other$ fmt.Println"))
+	require.Equal(t, "-repo:forks fmt.Println", instance.query("-repo:forks fmt.Println"))
+
+	single := SourcegraphInstance{RepoFilters: []string{"^acme/"}}
+	require.Equal(t, "repo:^acme/ lang:go", single.query("lang:go"))
+
+	require.Equal(t, "lang:go", SourcegraphInstance{}.query("lang:go"))
+}
+
+func TestSourcegraphTool(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"search":{"results":{"matchCount":0,"results":[]}}}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	run := func(token string) fantasy.ToolResponse {
+		resolver := config.NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{"SRC_ACCESS_TOKEN": token}))
+		tool := NewSourcegraphTool(srv.Client(), config.ToolSourcegraph{URL: srv.URL, AccessToken: "$SRC_ACCESS_TOKEN"}, resolver, nil)
+		resp, err := tool.Run(t.Context(), fantasy.ToolCall{ID: "call_1", Name: SourcegraphToolName, Input: `{"query": "fmt.Println"}`})
+		require.NoError(t, err)
+		return resp
+	}
+
+	require.False(t, run("good").IsError)
+	resp := run("bad")
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "401")
+
+	srv.Close()
+	resp = run("good")
+	require.True(t, resp.IsError, "unreachable instances are reported in the response")
+	require.Contains(t, resp.Content, "Failed to reach Sourcegraph")
+	require.False(t, SourcegraphUnreachable(config.ToolSourcegraph{URL: srv.URL}), "the instance is only checked once")
+}
+
+func TestSourcegraphToolUnreachable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	cfg := config.ToolSourcegraph{URL: srv.URL + "/"}
+	require.False(t, SourcegraphUnreachable(cfg), "unchecked instances are assumed reachable")
+
+	var unreachable int
+	tool := NewSourcegraphTool(srv.Client(), cfg, nil, func() { unreachable++ })
+	resp, err := tool.Run(t.Context(), fantasy.ToolCall{ID: "call_1", Name: SourcegraphToolName, Input: `{"query": "fmt.Println"}`})
+	require.NoError(t, err)
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "can't be reached")
+	require.Equal(t, 1, unreachable)
+	require.True(t, SourcegraphUnreachable(cfg))
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+type ToolSearchParams struct {
+	Query string `json:"query" description:"Words describing the tools to load, or their exact names"`
+	Limit int    `json:"limit,omitempty" description:"Maximum number of tools to load (default: 5, max: 20)"`
+}
+
+// ToolSearchResponseMetadata lists the tools a tool_search call loaded.
+type ToolSearchResponseMetadata struct {
+	Tools []string `json:"tools"`
+}
+
+// DeferredTool is a tool that is only sent to the model once it's loaded
+// with the tool_search tool.
+type DeferredTool interface {
+	fantasy.AgentTool
+	Deferred() bool
+}
+
+const (
+	ToolSearchToolName     = "tool_search"
+	defaultToolSearchLimit = 5
+	maxToolSearchLimit     = 20
+)
+
+//go:embed tool_search.md
+var toolSearchDescription []byte
+
+// NewToolSearchTool returns a tool that searches the deferred tools and loads
+// the matching ones. Loading is up to the agent, which reads the names from
+// the metadata of the response.
+func NewToolSearchTool(deferred []fantasy.AgentTool) fantasy.AgentTool {
+	return fantasy.NewAgentTool(
+		ToolSearchToolName,
+		string(toolSearchDescription),
+		func(ctx context.Context, params ToolSearchParams, call fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			if strings.TrimSpace(params.Query) == "" {
+				return fantasy.NewTextErrorResponse("query is required"), nil
+			}
+			limit := min(cmp.Or(params.Limit, defaultToolSearchLimit), maxToolSearchLimit)
+
+			found := searchTools(deferred, params.Query, limit)
+			if len(found) == 0 {
+				return fantasy.NewTextResponse(fmt.Sprintf(
+					"No tools match %q. Try other words, the tools that can be loaded come from these MCP servers: %s.",
+					params.Query, strings.Join(toolServers(deferred), ", "),
+				)), nil
+			}
+
+			var metadata ToolSearchResponseMetadata
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Loaded %d tool(s), they can be called now:\n", len(found))
+			for _, tool := range found {
+				info := tool.Info()
+				metadata.Tools = append(metadata.Tools, info.Name)
+				description, _, _ := strings.Cut(strings.TrimSpace(info.Description), "\n")
+				fmt.Fprintf(&sb, "- %s: %s\n", info.Name, description)
+			}
+			return fantasy.WithResponseMetadata(fantasy.NewTextResponse(strings.TrimSpace(sb.String())), metadata), nil
+		})
+}
+
+// searchTools returns up to limit tools matching the words of query, the
+// best matches first. Exact names score highest, then words in names, then
+// words in descriptions.
+func searchTools(tools []fantasy.AgentTool, query string, limit int) []fantasy.AgentTool {
+	words := strings.Fields(strings.ToLower(query))
+	type match struct {
+		tool  fantasy.AgentTool
+		score int
+	}
+	var matches []match
+	for _, tool := range tools {
+		info := tool.Info()
+		name := strings.ToLower(info.Name)
+		description := strings.ToLower(info.Description)
+		score := 0
+		for _, word := range words {
+			switch {
+			case name == word:
+				score += 10
+			case strings.Contains(name, word):
+				score += 3
+			}
+			if strings.Contains(description, word) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{tool, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), strings.Compare(a.tool.Info().Name, b.tool.Info().Name))
+	})
+
+	found := make([]fantasy.AgentTool, 0, min(len(matches), limit))
+	for _, m := range matches[:min(len(matches), limit)] {
+		found = append(found, m.tool)
+	}
+	return found
+}
+
+// toolServers returns the names of the MCP servers of tools.
+func toolServers(tools []fantasy.AgentTool) []string {
+	var servers []string
+	for _, tool := range tools {
+		if t, ok := tool.(interface{ MCP() string }); ok && !slices.Contains(servers, t.MCP()) {
+			servers = append(servers, t.MCP())
+		}
+	}
+	slices.Sort(servers)
+	return servers
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"charm.land/fantasy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSearch(t *testing.T) {
+	t.Parallel()
+
+	newTool := func(name, description string) fantasy.AgentTool {
+		return fantasy.NewAgentTool(name, description, func(context.Context, struct{}, fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse(""), nil
+		})
+	}
+	tool := NewToolSearchTool([]fantasy.AgentTool{
+		newTool("mcp_github_create_issue", "Create a new issue in a repository.\nRequires a title."),
+		newTool("mcp_github_list_issues", "List the issues of a repository."),
+		newTool("mcp_github_create_pull_request", "Open a pull request."),
+		newTool("mcp_db_query", "Run a SQL query against the database."),
+	})
+
+	run := func(input string) fantasy.ToolResponse {
+		resp, err := tool.Run(t.Context(), fantasy.ToolCall{ID: "call_1", Name: ToolSearchToolName, Input: input})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("best matches first", func(t *testing.T) {
+		t.Parallel()
+		resp := run(`{"query": "create issue", "limit": 2}`)
+		require.False(t, resp.IsError)
+		require.Equal(t, "Loaded 2 tool(s), they can be called now:\n"+
+			"- mcp_github_create_issue: Create a new issue in a repository.\n"+
+			"- mcp_github_list_issues: List the issues of a repository.", resp.Content)
+		require.JSONEq(t, `{"tools": ["mcp_github_create_issue", "mcp_github_list_issues"]}`, resp.Metadata)
+	})
+
+	t.Run("exact name", func(t *testing.T) {
+		t.Parallel()
+		resp := run(`{"query": "mcp_db_query"}`)
+		require.JSONEq(t, `{"tools": ["mcp_db_query"]}`, resp.Metadata)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		t.Parallel()
+		resp := run(`{"query": "weather"}`)
+		require.False(t, resp.IsError)
+		require.Empty(t, resp.Metadata)
+		require.Contains(t, resp.Content, `No tools match "weather"`)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		t.Parallel()
+		resp := run(`{"query": " "}`)
+		require.True(t, resp.IsError)
+	})
+}
@@ -13,7 +13,6 @@ import (
 	"unicode/utf8"
 
 	"charm.land/fantasy"
-	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/filepathext"
 	"github.com/charmbracelet/crush/internal/filetracker"
 	"github.com/charmbracelet/crush/internal/lsp"
@@ -45,10 +44,12 @@ const (
 	MaxReadSize      = 5 * 1024 * 1024 // 5MB
 	DefaultReadLimit = 2000
 	MaxLineLength    = 2000
+	MaxPDFPages      = 50
+	MaxPDFViewSize   = 256 * 1024 // 256KB
 )
 
 func NewViewTool(
-	lspClients *csync.Map[string, *lsp.Client],
+	lspManager *lsp.Manager,
 	permissions permission.Service,
 	filetracker filetracker.Service,
 	workingDir string,
@@ -158,6 +159,15 @@ func NewViewTool(
 				}
 			}
 
+			params.Offset = max(params.Offset, 0)
+
+			if isNotebook(filePath) {
+				return viewNotebook(ctx, filetracker, sessionID, filePath, params)
+			}
+			if strings.EqualFold(filepath.Ext(filePath), ".pdf") {
+				return viewPDF(ctx, filetracker, sessionID, filePath, params)
+			}
+
 			isSupportedImage, mimeType := getImageMimeType(filePath)
 			if isSupportedImage {
 				if !GetSupportsImagesFromContext(ctx) {
@@ -184,7 +194,7 @@ func NewViewTool(
 				return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", err)
 			}
 
-			notifyLSPs(ctx, lspClients, filePath)
+			notifyLSPs(ctx, lspManager, filePath)
 			output := "<file>\n"
 			// Format the output with line numbers
 			output += addLineNumbers(content, params.Offset+1)
@@ -195,7 +205,7 @@ func NewViewTool(
 					params.Offset+len(strings.Split(content, "\n")))
 			}
 			output += "\n</file>\n"
-			output += getDiagnostics(filePath, lspClients)
+			output += getDiagnostics(filePath, lspManager.Clients())
 			filetracker.RecordRead(ctx, sessionID, filePath)
 			return fantasy.WithResponseMetadata(
 				fantasy.NewTextResponse(output),
@@ -207,6 +217,104 @@ func NewViewTool(
 		})
 }
 
+// viewNotebook renders the cells of a Jupyter notebook with their outputs,
+// using offset and limit as cell indexes. When the model supports images, the
+// first image output is passed along.
+func viewNotebook(ctx context.Context, filetracker filetracker.Service, sessionID, filePath string, params ViewParams) (fantasy.ToolResponse, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", err)
+	}
+	nb, err := parseNotebook(data)
+	if err != nil {
+		return fantasy.NewTextErrorResponse(err.Error()), nil
+	}
+	if params.Offset >= len(nb.Cells) && len(nb.Cells) > 0 {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("Offset %d is out of range, the notebook has %d cells", params.Offset, len(nb.Cells))), nil
+	}
+
+	content, images := renderNotebook(nb, params.Offset, params.Limit)
+	output := "<notebook>\n" + content
+	if end := params.Offset + params.Limit; end < len(nb.Cells) {
+		output += fmt.Sprintf("\n(Notebook has %d cells. Use 'offset' parameter to read beyond cell %d)\n", len(nb.Cells), end-1)
+	}
+	output += "</notebook>\n"
+
+	response := fantasy.NewTextResponse(output)
+	if len(images) > 0 && GetSupportsImagesFromContext(ctx) {
+		image := images[0]
+		output += fmt.Sprintf("\nThe %s output of cell %d is attached.", image.MediaType, image.Cell)
+		if len(images) > 1 {
+			output += " To see the other image outputs, view their cells one at a time using 'offset' and 'limit'."
+		}
+		response = fantasy.NewImageResponse([]byte(image.Data), image.MediaType)
+		response.Content = output
+	}
+
+	filetracker.RecordRead(ctx, sessionID, filePath)
+	return fantasy.WithResponseMetadata(
+		response,
+		ViewResponseMetadata{
+			FilePath: filePath,
+			Content:  content,
+		},
+	), nil
+}
+
+// viewPDF extracts the text of a PDF document with page markers, using offset
+// and limit as page numbers.
+func viewPDF(ctx context.Context, filetracker filetracker.Service, sessionID, filePath string, params ViewParams) (fantasy.ToolResponse, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fantasy.ToolResponse{}, fmt.Errorf("error reading file: %w", err)
+	}
+	if !isPDF(data, "") {
+		return fantasy.NewTextErrorResponse("File is not a valid PDF document"), nil
+	}
+	pages, err := readPDFPages(data)
+	if err != nil {
+		return fantasy.NewTextErrorResponse(err.Error()), nil
+	}
+	if params.Offset >= len(pages) && len(pages) > 0 {
+		return fantasy.NewTextErrorResponse(fmt.Sprintf("Offset %d is out of range, the document has %d pages", params.Offset, len(pages))), nil
+	}
+
+	end := pdfPageEnd(pages, params.Offset, params.Limit)
+	content := formatPDFPages(pages[params.Offset:end], params.Offset+1)
+	if len(content) > MaxPDFViewSize {
+		content = strings.ToValidUTF8(content[:MaxPDFViewSize], "") + "..."
+	}
+	output := "<file>\n" + content
+	if end < len(pages) {
+		output += fmt.Sprintf("\n\n(Document has %d pages. Use 'offset' parameter to read beyond page %d)", len(pages), end)
+	}
+	output += "\n</file>\n"
+
+	filetracker.RecordRead(ctx, sessionID, filePath)
+	return fantasy.WithResponseMetadata(
+		fantasy.NewTextResponse(output),
+		ViewResponseMetadata{
+			FilePath: filePath,
+			Content:  content,
+		},
+	), nil
+}
+
+// pdfPageEnd returns the end of the pages to show from offset: at most limit
+// and [MaxPDFPages] pages, stopping before the text grows past
+// [MaxPDFViewSize] unless it's the first page.
+func pdfPageEnd(pages []string, offset, limit int) int {
+	end := min(offset+min(limit, MaxPDFPages), len(pages))
+	var size int
+	for i := offset; i < end; i++ {
+		size += len(pages[i])
+		if size > MaxPDFViewSize && i > offset {
+			return i
+		}
+	}
+	return end
+}
+
 func addLineNumbers(content string, startLine int) string {
 	if content == "" {
 		return ""
@@ -13,8 +13,9 @@ import (
 //go:embed web_search.md
 var webSearchToolDescription []byte
 
-// NewWebSearchTool creates a web search tool for sub-agents (no permissions needed).
-func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
+// NewWebSearchTool creates a web search tool for sub-agents (no permissions
+// needed). If backend is nil, DuckDuckGo is used.
+func NewWebSearchTool(client *http.Client, backend SearchBackend) fantasy.AgentTool {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
@@ -26,6 +27,10 @@ func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
 		}
 	}
 
+	if backend == nil {
+		backend = &duckDuckGoBackend{searchSettings{client: client}}
+	}
+
 	return fantasy.NewParallelAgentTool(
 		WebSearchToolName,
 		string(webSearchToolDescription),
@@ -36,15 +41,14 @@ func NewWebSearchTool(client *http.Client) fantasy.AgentTool {
 
 			maxResults := params.MaxResults
 			if maxResults <= 0 {
-				maxResults = 10
+				maxResults = backend.MaxResults()
 			}
-			if maxResults > 20 {
-				maxResults = 20
+			if maxResults > maxSearchResults {
+				maxResults = maxSearchResults
 			}
 
-			maybeDelaySearch()
-			results, err := searchDuckDuckGo(ctx, client, params.Query, maxResults)
-			slog.Debug("Web search completed", "query", params.Query, "results", len(results), "err", err)
+			results, err := backend.Search(ctx, params.Query, maxResults)
+			slog.Debug("Web search completed", "backend", backend.Name(), "query", params.Query, "results", len(results), "err", err)
 			if err != nil {
 				return fantasy.NewTextErrorResponse("Failed to search: " + err.Error()), nil
 			}
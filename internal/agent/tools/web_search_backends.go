@@ -0,0 +1,364 @@
+package tools
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultSearchResults = 10
+	maxSearchResults     = 20
+)
+
+// SearchBackend is a web search provider used by the web_search tool.
+//
+// To add a new backend, implement this interface and hook it up in
+// [NewSearchBackend].
+type SearchBackend interface {
+	// Name returns a human-readable name of the backend.
+	Name() string
+	// MaxResults returns the default number of results to return.
+	MaxResults() int
+	// Search runs query and returns at most maxResults results.
+	Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
+}
+
+// NewSearchBackend creates the search backend selected in the config. API keys
+// are resolved using the given resolver.
+func NewSearchBackend(cfg config.ToolWebSearch, resolver config.VariableResolver, client *http.Client) (SearchBackend, error) {
+	settings := searchSettings{client: client}
+	if backend := cfg.Backend(); backend != nil {
+		settings.url = backend.URL
+		settings.maxResults = backend.MaxResults
+		settings.safeSearch = backend.SafeSearch
+		settings.headers = backend.Headers
+		if backend.APIKey != "" {
+			if resolver == nil {
+				return nil, fmt.Errorf("no variable resolver configured for %s api key", cfg.Provider)
+			}
+			apiKey, err := resolver.ResolveValue(backend.APIKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s api key: %w", cfg.Provider, err)
+			}
+			settings.apiKey = apiKey
+		}
+	}
+
+	switch cfg.Provider {
+	case "", config.WebSearchDuckDuckGo:
+		return &duckDuckGoBackend{settings}, nil
+	case config.WebSearchSearXNG:
+		if settings.url == "" {
+			return nil, fmt.Errorf("searxng requires a url")
+		}
+		return &searXNGBackend{settings}, nil
+	case config.WebSearchBrave:
+		if settings.apiKey == "" {
+			return nil, fmt.Errorf("brave requires an api_key")
+		}
+		settings.url = cmp.Or(settings.url, "https://api.search.brave.com/res/v1/web/search")
+		return &braveBackend{settings}, nil
+	case config.WebSearchTavily:
+		if settings.apiKey == "" {
+			return nil, fmt.Errorf("tavily requires an api_key")
+		}
+		settings.url = cmp.Or(settings.url, "https://api.tavily.com/search")
+		return &tavilyBackend{settings}, nil
+	case config.WebSearchJSON:
+		if cfg.JSON == nil || settings.url == "" {
+			return nil, fmt.Errorf("json search endpoint requires a url")
+		}
+		return &jsonEndpointBackend{searchSettings: settings, endpoint: *cfg.JSON}, nil
+	default:
+		return nil, fmt.Errorf("unknown web search provider: %s", cfg.Provider)
+	}
+}
+
+// searchSettings holds the settings shared by all search backends.
+type searchSettings struct {
+	client     *http.Client
+	url        string
+	apiKey     string
+	maxResults int
+	safeSearch string
+	headers    map[string]string
+}
+
+// MaxResults returns the configured default number of results.
+func (s searchSettings) MaxResults() int {
+	if s.maxResults <= 0 {
+		return defaultSearchResults
+	}
+	return min(s.maxResults, maxSearchResults)
+}
+
+// safeSearchLevel returns the normalized safe search level: off, moderate or
+// strict.
+func (s searchSettings) safeSearchLevel() string {
+	switch strings.ToLower(s.safeSearch) {
+	case "off", "strict":
+		return strings.ToLower(s.safeSearch)
+	default:
+		return "moderate"
+	}
+}
+
+// do executes req with the configured headers and decodes a JSON response
+// into v.
+func (s searchSettings) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "crush/1.0")
+	for k, val := range s.headers {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("search failed with status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxReadSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if raw, ok := v.(*[]byte); ok {
+		*raw = body
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// numbered assigns positions to results and truncates them to maxResults.
+func numbered(results []SearchResult, maxResults int) []SearchResult {
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	for i := range results {
+		results[i].Position = i + 1
+	}
+	return results
+}
+
+type duckDuckGoBackend struct {
+	searchSettings
+}
+
+func (b *duckDuckGoBackend) Name() string { return "DuckDuckGo" }
+
+func (b *duckDuckGoBackend) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	// DuckDuckGo is scraped, so be gentle with it.
+	maybeDelaySearch()
+	return searchDuckDuckGo(ctx, b.client, query, maxResults)
+}
+
+type searXNGBackend struct {
+	searchSettings
+}
+
+func (b *searXNGBackend) Name() string { return "SearXNG" }
+
+func (b *searXNGBackend) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	levels := map[string]string{"off": "0", "moderate": "1", "strict": "2"}
+	params := url.Values{
+		"q":          {query},
+		"format":     {"json"},
+		"safesearch": {levels[b.safeSearchLevel()]},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(b.url, "/")+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if b.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := b.do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, Link: r.URL, Snippet: r.Content})
+	}
+	return numbered(results, maxResults), nil
+}
+
+type braveBackend struct {
+	searchSettings
+}
+
+func (b *braveBackend) Name() string { return "Brave" }
+
+func (b *braveBackend) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{
+		"q":          {query},
+		"count":      {strconv.Itoa(maxResults)},
+		"safesearch": {b.safeSearchLevel()},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := b.do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		results = append(results, SearchResult{Title: r.Title, Link: r.URL, Snippet: r.Description})
+	}
+	return numbered(results, maxResults), nil
+}
+
+type tavilyBackend struct {
+	searchSettings
+}
+
+func (b *tavilyBackend) Name() string { return "Tavily" }
+
+func (b *tavilyBackend) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	// Tavily has no safe search setting, so it's ignored.
+	body, err := json.Marshal(map[string]any{
+		"query":        query,
+		"max_results":  maxResults,
+		"search_depth": "basic",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.apiKey)
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := b.do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, Link: r.URL, Snippet: r.Content})
+	}
+	return numbered(results, maxResults), nil
+}
+
+type jsonEndpointBackend struct {
+	searchSettings
+	endpoint config.WebSearchJSONEndpoint
+}
+
+func (b *jsonEndpointBackend) Name() string { return "JSON endpoint" }
+
+func (b *jsonEndpointBackend) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	method := strings.ToUpper(cmp.Or(b.endpoint.Method, http.MethodGet))
+
+	// Values are escaped according to where they end up: the URL gets URL
+	// escaped values, the body gets JSON escaped ones.
+	expand := func(tmpl string, escape func(string) string) string {
+		return strings.NewReplacer(
+			"{query}", escape(query),
+			"{max_results}", strconv.Itoa(maxResults),
+			"{safe_search}", escape(b.safeSearchLevel()),
+			"{api_key}", escape(b.apiKey),
+		).Replace(tmpl)
+	}
+	jsonEscape := func(s string) string {
+		bts, _ := json.Marshal(s)
+		return string(bts[1 : len(bts)-1])
+	}
+
+	var body io.Reader
+	if b.endpoint.Body != "" {
+		body = strings.NewReader(expand(b.endpoint.Body, jsonEscape))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, expand(b.url, url.QueryEscape), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	settings := b.searchSettings
+	settings.headers = make(map[string]string, len(b.headers))
+	for k, v := range b.headers {
+		settings.headers[k] = expand(v, func(s string) string { return s })
+	}
+
+	var raw []byte
+	if err := settings.do(req, &raw); err != nil {
+		return nil, err
+	}
+
+	items := gjson.ParseBytes(raw)
+	if b.endpoint.ResultsPath != "" {
+		items = items.Get(b.endpoint.ResultsPath)
+	}
+	if !items.IsArray() {
+		return nil, fmt.Errorf("no results array found at %q", b.endpoint.ResultsPath)
+	}
+
+	titleField := cmp.Or(b.endpoint.TitleField, "title")
+	urlField := cmp.Or(b.endpoint.URLField, "url")
+	snippetField := cmp.Or(b.endpoint.SnippetField, "snippet")
+
+	var results []SearchResult
+	for _, item := range items.Array() {
+		link := item.Get(urlField).String()
+		if link == "" {
+			continue
+		}
+		results = append(results, SearchResult{
+			Title:   item.Get(titleField).String(),
+			Link:    link,
+			Snippet: item.Get(snippetField).String(),
+		})
+	}
+	return numbered(results, maxResults), nil
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSearchBackend(t *testing.T, cfg config.ToolWebSearch) SearchBackend {
+	t.Helper()
+	resolver := config.NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{
+		"SEARCH_KEY": "secret",
+	}))
+	backend, err := NewSearchBackend(cfg, resolver, http.DefaultClient)
+	require.NoError(t, err)
+	return backend
+}
+
+func TestSearXNGBackend(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "2", r.URL.Query().Get("safesearch"))
+		_, _ = io.WriteString(w, `{"results":[
+			{"title":"Go","url":"https://go.dev","content":"The Go language"},
+			{"title":"Tour","url":"https://go.dev/tour","content":"A tour of Go"},
+			{"title":"Blog","url":"https://go.dev/blog","content":"The Go blog"}
+		]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	backend := newTestSearchBackend(t, config.ToolWebSearch{
+		Provider: config.WebSearchSearXNG,
+		SearXNG:  &config.WebSearchBackend{URL: srv.URL + "/", SafeSearch: "strict", MaxResults: 5},
+	})
+	require.Equal(t, 5, backend.MaxResults())
+
+	results, err := backend.Search(t.Context(), "golang", 2)
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{
+		{Title: "Go", Link: "https://go.dev", Snippet: "The Go language", Position: 1},
+		{Title: "Tour", Link: "https://go.dev/tour", Snippet: "A tour of Go", Position: 2},
+	}, results)
+}
+
+func TestBraveBackend(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Subscription-Token"))
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "3", r.URL.Query().Get("count"))
+		assert.Equal(t, "moderate", r.URL.Query().Get("safesearch"))
+		_, _ = io.WriteString(w, `{"web":{"results":[{"title":"Go","url":"https://go.dev","description":"The Go language"}]}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	backend := newTestSearchBackend(t, config.ToolWebSearch{
+		Provider: config.WebSearchBrave,
+		Brave:    &config.WebSearchBackend{URL: srv.URL, APIKey: "$SEARCH_KEY"},
+	})
+	require.Equal(t, defaultSearchResults, backend.MaxResults())
+
+	results, err := backend.Search(t.Context(), "golang", 3)
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{
+		{Title: "Go", Link: "https://go.dev", Snippet: "The Go language", Position: 1},
+	}, results)
+}
+
+func TestTavilyBackend(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "golang", body["query"])
+		assert.EqualValues(t, 4, body["max_results"])
+		_, _ = io.WriteString(w, `{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	backend := newTestSearchBackend(t, config.ToolWebSearch{
+		Provider: config.WebSearchTavily,
+		Tavily:   &config.WebSearchBackend{URL: srv.URL, APIKey: "$SEARCH_KEY"},
+	})
+
+	results, err := backend.Search(t.Context(), "golang", 4)
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{
+		{Title: "Go", Link: "https://go.dev", Snippet: "The Go language", Position: 1},
+	}, results)
+}
+
+func TestJSONEndpointBackend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			assert.Equal(t, "go & rust", r.URL.Query().Get("q"))
+			assert.Equal(t, "5", r.URL.Query().Get("n"))
+			_, _ = io.WriteString(w, `{"data":{"items":[
+				{"name":"Go","link":"https://go.dev","summary":"The Go language"},
+				{"name":"No link"}
+			]}}`)
+		}))
+		t.Cleanup(srv.Close)
+
+		backend := newTestSearchBackend(t, config.ToolWebSearch{
+			Provider: config.WebSearchJSON,
+			JSON: &config.WebSearchJSONEndpoint{
+				WebSearchBackend: config.WebSearchBackend{
+					URL:     srv.URL + "?q={query}&n={max_results}",
+					APIKey:  "$SEARCH_KEY",
+					Headers: map[string]string{"Authorization": "Bearer {api_key}"},
+				},
+				ResultsPath:  "data.items",
+				TitleField:   "name",
+				URLField:     "link",
+				SnippetField: "summary",
+			},
+		})
+
+		results, err := backend.Search(t.Context(), "go & rust", 5)
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{
+			{Title: "Go", Link: "https://go.dev", Snippet: "The Go language", Position: 1},
+		}, results)
+	})
+
+	t.Run("post", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			var body map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, `say "hi"`, body["q"])
+			assert.Equal(t, "off", body["safe"])
+			_, _ = io.WriteString(w, `[{"title":"Hi","url":"https://example.com","snippet":"hello"}]`)
+		}))
+		t.Cleanup(srv.Close)
+
+		backend := newTestSearchBackend(t, config.ToolWebSearch{
+			Provider: config.WebSearchJSON,
+			JSON: &config.WebSearchJSONEndpoint{
+				WebSearchBackend: config.WebSearchBackend{URL: srv.URL, SafeSearch: "off"},
+				Method:           "post",
+				Body:             `{"q":"{query}","safe":"{safe_search}"}`,
+			},
+		})
+
+		results, err := backend.Search(t.Context(), `say "hi"`, 5)
+		require.NoError(t, err)
+		require.Equal(t, []SearchResult{
+			{Title: "Hi", Link: "https://example.com", Snippet: "hello", Position: 1},
+		}, results)
+	})
+}
+
+func TestNewSearchBackend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults to duckduckgo", func(t *testing.T) {
+		t.Parallel()
+		backend, err := NewSearchBackend(config.ToolWebSearch{}, nil, http.DefaultClient)
+		require.NoError(t, err)
+		require.Equal(t, "DuckDuckGo", backend.Name())
+	})
+
+	t.Run("missing settings", func(t *testing.T) {
+		t.Parallel()
+		for _, provider := range []config.WebSearchProvider{
+			config.WebSearchSearXNG,
+			config.WebSearchBrave,
+			config.WebSearchTavily,
+			config.WebSearchJSON,
+		} {
+			_, err := NewSearchBackend(config.ToolWebSearch{Provider: provider}, nil, http.DefaultClient)
+			require.Error(t, err, provider)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewSearchBackend(config.ToolWebSearch{Provider: "bing"}, nil, http.DefaultClient)
+		require.Error(t, err)
+	})
+}
@@ -60,7 +60,11 @@ type App struct {
 
 	LSPClients *csync.Map[string, *lsp.Client]
 
-	config *config.Config
+	lspManager         *lsp.Manager
+	userConfiguredLSPs *csync.Map[string, bool]
+
+	// reloadMu serializes the reloads of the config.
+	reloadMu sync.Mutex
 
 	serviceEventsWG *sync.WaitGroup
 	eventsCtx       context.Context
@@ -90,17 +94,21 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		History:     files,
 		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools),
 		FileTracker: filetracker.NewService(q),
-		LSPClients:  csync.NewMap[string, *lsp.Client](),
 
-		globalCtx: ctx,
+		userConfiguredLSPs: csync.NewMap[string, bool](),
 
-		config: cfg,
+		globalCtx: ctx,
 
 		events:          make(chan tea.Msg, 100),
 		serviceEventsWG: &sync.WaitGroup{},
 		tuiWG:           &sync.WaitGroup{},
 	}
 
+	app.lspManager = lsp.NewManager(cfg.WorkingDir(), func(key, name string, lspCfg config.LSPConfig, root, path string) {
+		app.createAndStartLSPClient(ctx, key, name, lspCfg, root, path)
+	})
+	app.LSPClients = app.lspManager.Clients()
+
 	app.setupEvents()
 
 	// Initialize LSP clients in the background.
@@ -115,7 +123,7 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	}()
 
 	// cleanup database upon app shutdown
-	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close)
+	app.cleanupFuncs = append(app.cleanupFuncs, conn.Close, mcp.Close, app.FileTracker.Close)
 
 	// TODO: remove the concept of agent config, most likely.
 	if !cfg.IsConfigured() {
@@ -125,17 +133,27 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	if err := app.InitCoderAgent(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize coder agent: %w", err)
 	}
+
+	// Discover models in the background, the cached ones are used meanwhile.
+	if cfg.DiscoversModels() {
+		go func() {
+			if _, err := app.DiscoverModels(ctx); err != nil {
+				slog.Warn("Failed to discover models", "error", err)
+			}
+		}()
+	}
 	return app, nil
 }
 
-// Config returns the application configuration.
+// Config returns the application configuration. It's replaced when the
+// config is reloaded, so it shouldn't be held on to.
 func (app *App) Config() *config.Config {
-	return app.config
+	return config.Get()
 }
 
 // RunNonInteractive runs the application in non-interactive mode with the
 // given prompt, printing to stdout.
-func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt, largeModel, smallModel string, hideSpinner bool) error {
+func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt, largeModel, smallModel string, opts agent.RunOptions, hideSpinner bool) error {
 	slog.Info("Running in non-interactive mode")
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -160,7 +178,7 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt,
 	}
 	stderrTTY = term.IsTerminal(os.Stderr.Fd())
 	stdinTTY = term.IsTerminal(os.Stdin.Fd())
-	progress = app.config.Options.Progress == nil || *app.config.Options.Progress
+	progress = app.Config().Options.Progress == nil || *app.Config().Options.Progress
 
 	if !hideSpinner && stderrTTY {
 		t := styles.CurrentTheme()
@@ -224,6 +242,13 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt,
 	// session.
 	app.Permissions.AutoApproveSession(sess.ID)
 
+	// There's no one to fill in the forms of MCP servers, decline them.
+	go func() {
+		for event := range mcp.SubscribeElicitations(ctx) {
+			_ = mcp.RespondElicitation(event.Payload.ID, mcp.ElicitationDecline, nil)
+		}
+	}()
+
 	type response struct {
 		result *fantasy.AgentResult
 		err    error
@@ -231,7 +256,7 @@ func (app *App) RunNonInteractive(ctx context.Context, output io.Writer, prompt,
 	done := make(chan response, 1)
 
 	go func(ctx context.Context, sessionID, prompt string) {
-		result, err := app.AgentCoordinator.Run(ctx, sess.ID, prompt)
+		result, err := app.AgentCoordinator.RunWithOptions(ctx, sess.ID, prompt, opts)
 		if err != nil {
 			done <- response{
 				err: fmt.Errorf("failed to start agent processing stream: %w", err),
@@ -316,6 +341,17 @@ func (app *App) UpdateAgentModel(ctx context.Context) error {
 	return app.AgentCoordinator.UpdateModels(ctx)
 }
 
+// LoginMCP authorizes Crush with the MCP server name, calling open with the
+// URL the user has to visit, then reconnects to the server and makes its
+// tools available to the agent.
+func (app *App) LoginMCP(ctx context.Context, name string, open func(url string) error) error {
+	if err := mcp.Login(ctx, app.Config(), name, open); err != nil {
+		return err
+	}
+	mcp.Reconnect(app.globalCtx, name)
+	return app.updateMCPTools(ctx)
+}
+
 // overrideModelsForNonInteractive parses the model strings and temporarily
 // overrides the model configurations, then rebuilds the agent.
 // Format: "model-name" (searches all providers) or "provider/model-name".
@@ -323,7 +359,7 @@ func (app *App) UpdateAgentModel(ctx context.Context) error {
 // If largeModel is provided but smallModel is not, the small model defaults to
 // the provider's default small model.
 func (app *App) overrideModelsForNonInteractive(ctx context.Context, largeModel, smallModel string) error {
-	providers := app.config.Providers.Copy()
+	providers := app.Config().Providers.Copy()
 
 	largeMatches, smallMatches, err := findModels(providers, largeModel, smallModel)
 	if err != nil {
@@ -340,7 +376,7 @@ func (app *App) overrideModelsForNonInteractive(ctx context.Context, largeModel,
 		}
 		largeProviderID = found.provider
 		slog.Info("Overriding large model for non-interactive run", "provider", found.provider, "model", found.modelID)
-		app.config.Models[config.SelectedModelTypeLarge] = config.SelectedModel{
+		app.Config().Models[config.SelectedModelTypeLarge] = config.SelectedModel{
 			Provider: found.provider,
 			Model:    found.modelID,
 		}
@@ -354,7 +390,7 @@ func (app *App) overrideModelsForNonInteractive(ctx context.Context, largeModel,
 			return err
 		}
 		slog.Info("Overriding small model for non-interactive run", "provider", found.provider, "model", found.modelID)
-		app.config.Models[config.SelectedModelTypeSmall] = config.SelectedModel{
+		app.Config().Models[config.SelectedModelTypeSmall] = config.SelectedModel{
 			Provider: found.provider,
 			Model:    found.modelID,
 		}
@@ -362,7 +398,7 @@ func (app *App) overrideModelsForNonInteractive(ctx context.Context, largeModel,
 	case largeModel != "":
 		// No small model specified, but large model was - use provider's default.
 		smallCfg := app.GetDefaultSmallModel(largeProviderID)
-		app.config.Models[config.SelectedModelTypeSmall] = smallCfg
+		app.Config().Models[config.SelectedModelTypeSmall] = smallCfg
 	}
 
 	return app.AgentCoordinator.UpdateModels(ctx)
@@ -371,7 +407,7 @@ func (app *App) overrideModelsForNonInteractive(ctx context.Context, largeModel,
 // GetDefaultSmallModel returns the default small model for the given
 // provider. Falls back to the large model if no default is found.
 func (app *App) GetDefaultSmallModel(providerID string) config.SelectedModel {
-	cfg := app.config
+	cfg := app.Config()
 	largeModelCfg := cfg.Models[config.SelectedModelTypeLarge]
 
 	// Find the provider in the known providers list to get its default small model.
@@ -415,6 +451,8 @@ func (app *App) setupEvents() {
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions-notifications", app.Permissions.SubscribeNotifications, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "history", app.History.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "mcp", mcp.SubscribeEvents, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "mcp-elicitations", mcp.SubscribeElicitations, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "mcp-progress", mcp.SubscribeProgress, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "lsp", SubscribeLSPEvents, app.events)
 	cleanupFunc := func() error {
 		cancel()
@@ -458,20 +496,20 @@ func setupSubscriber[T any](
 }
 
 func (app *App) InitCoderAgent(ctx context.Context) error {
-	coderAgentCfg := app.config.Agents[config.AgentCoder]
+	coderAgentCfg := app.Config().Agents[config.AgentCoder]
 	if coderAgentCfg.ID == "" {
 		return fmt.Errorf("coder agent configuration is missing")
 	}
 	var err error
 	app.AgentCoordinator, err = agent.NewCoordinator(
 		ctx,
-		app.config,
+		app.Config(),
 		app.Sessions,
 		app.Messages,
 		app.Permissions,
 		app.History,
 		app.FileTracker,
-		app.LSPClients,
+		app.lspManager,
 	)
 	if err != nil {
 		slog.Error("Failed to create coder agent", "err", err)
@@ -497,6 +535,10 @@ func (app *App) Subscribe(program *tea.Program) {
 	})
 	defer app.tuiWG.Done()
 
+	// Reload the config when its files change. It's only done while the
+	// TUI runs, a reload would undo the models crush run was given.
+	app.watchConfig(tuiCtx)
+
 	for {
 		select {
 		case <-tuiCtx.Done():
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/uiutil"
+)
+
+// watchConfig reloads the config whenever its files change.
+func (app *App) watchConfig(ctx context.Context) {
+	err := config.Watch(ctx, app.Config().WorkingDir(), func() {
+		changed, err := app.reloadConfig(ctx)
+		switch {
+		case err != nil:
+			slog.Warn("Failed to reload config", "error", err)
+			app.sendEvent(uiutil.InfoMsg{Type: uiutil.InfoTypeError, Msg: "Config not reloaded: " + err.Error()})
+		case changed:
+			app.sendEvent(uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: "Config reloaded"})
+		}
+	})
+	if err != nil {
+		slog.Warn("Failed to watch config files", "error", err)
+	}
+}
+
+// DiscoverModels lists the models of the providers with discover_models set
+// again and makes them available to the agent. It returns how many models
+// were discovered.
+func (app *App) DiscoverModels(ctx context.Context) (int, error) {
+	if !app.Config().DiscoversModels() {
+		return 0, nil
+	}
+	n, changed, err := app.Config().DiscoverModels(ctx)
+	if changed && app.AgentCoordinator != nil {
+		if err := app.AgentCoordinator.UpdateModels(ctx); err != nil {
+			slog.Warn("Failed to update agent after discovering models", "error", err)
+		}
+	}
+	return n, err
+}
+
+// SwitchProfile reloads the config with the profile name, or with no profile
+// when name is empty. The current profile is kept when the config can't be
+// loaded with the new one.
+func (app *App) SwitchProfile(ctx context.Context, name string) error {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+	_, err := app.loadConfig(ctx, name)
+	return err
+}
+
+// reloadConfig loads the config files again with the current profile, see
+// [App.loadConfig].
+func (app *App) reloadConfig(ctx context.Context) (bool, error) {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+	return app.loadConfig(ctx, app.Config().Profile())
+}
+
+// loadConfig loads the config files with profile and applies what changed to
+// the running app, reporting whether anything did. An invalid config is
+// returned as an error and the current one is kept. reloadMu must be held.
+func (app *App) loadConfig(ctx context.Context, profile string) (bool, error) {
+	current := app.Config()
+	cfg, err := config.Load(current.WorkingDir(), current.Options.DataDirectory, profile, current.Options.Debug)
+	if err != nil {
+		return false, err
+	}
+	if !cfg.IsConfigured() && current.IsConfigured() {
+		return false, errors.New("no providers configured")
+	}
+
+	current.KeepRuntime(cfg)
+	changes := current.Diff(cfg)
+	if !changes.Any() {
+		return false, nil
+	}
+	slog.Info("Config changed, reloading", "changes", changes)
+	current.Apply(cfg)
+
+	if changes.MCP {
+		mcp.Reconcile(app.globalCtx, cfg)
+	}
+	if len(changes.LSP) > 0 {
+		app.reloadLSPClients(ctx, changes.LSP)
+	}
+	if changes.Permissions && cfg.Permissions != nil {
+		app.Permissions.SetAllowedTools(cfg.Permissions.AllowedTools)
+	}
+	if err := app.updateMCPTools(ctx); err != nil {
+		slog.Warn("Failed to update agent after reloading config", "error", err)
+	}
+	return true, nil
+}
+
+// reloadLSPClients stops the clients of the LSP servers whose config changed,
+// and starts the ones that should run with the current config.
+func (app *App) reloadLSPClients(ctx context.Context, names []string) {
+	for _, name := range names {
+		for key, client := range app.lspManager.Remove(name) {
+			if err := client.Close(ctx); err != nil {
+				slog.Warn("Failed to stop LSP client", "name", key, "error", err)
+			}
+			info, _ := GetLSPState(key)
+			updateLSPState(key, info.Root, lsp.StateDisabled, nil, nil, 0)
+		}
+		app.userConfiguredLSPs.Del(name)
+	}
+	app.initLSPClients(ctx)
+}
+
+// sendEvent sends msg to the TUI, dropping it when nothing is reading the
+// events.
+func (app *App) sendEvent(msg tea.Msg) {
+	select {
+	case app.events <- msg:
+	default:
+		slog.Debug("Dropping event, the events channel is full")
+	}
+}
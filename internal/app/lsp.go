@@ -4,7 +4,9 @@ import (
 	"context"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
@@ -20,7 +22,7 @@ func (app *App) initLSPClients(ctx context.Context) {
 	manager.LoadDefaults()
 
 	var userConfiguredLSPs []string
-	for name, clientConfig := range app.config.LSP {
+	for name, clientConfig := range app.Config().LSP {
 		if clientConfig.Disabled {
 			slog.Info("Skipping disabled LSP client", "name", name)
 			manager.RemoveServer(name)
@@ -51,24 +53,45 @@ func (app *App) initLSPClients(ctx context.Context) {
 	}
 
 	servers := manager.GetServers()
-	filtered := lsp.FilterMatching(app.config.WorkingDir(), servers)
+	filtered := lsp.FilterMatching(app.Config().WorkingDir(), servers)
 
 	for _, name := range userConfiguredLSPs {
 		if _, ok := filtered[name]; !ok {
-			updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+			updateLSPState(name, "", lsp.StateDisabled, nil, nil, 0)
 		}
 	}
 	for name, server := range filtered {
-		if app.config.Options.AutoLSP != nil && !*app.config.Options.AutoLSP && !slices.Contains(userConfiguredLSPs, name) {
+		if app.Config().Options.AutoLSP != nil && !*app.Config().Options.AutoLSP && !slices.Contains(userConfiguredLSPs, name) {
 			slog.Debug("Ignoring non user-define LSP client due to AutoLSP being disabled", "name", name)
 			continue
 		}
-		go app.createAndStartLSPClient(
-			ctx, name,
-			toOurConfig(server),
-			slices.Contains(userConfiguredLSPs, name),
-		)
+		if slices.Contains(userConfiguredLSPs, name) {
+			app.userConfiguredLSPs.Set(name, true)
+		}
+		cfg := toOurConfig(server)
+		app.lspManager.Register(name, cfg)
+
+		// Servers with a root marker in the working directory are started
+		// right away. Servers that only match nested project roots are
+		// started once a file under one of those roots is opened.
+		workDir := app.Config().WorkingDir()
+		if root := lsp.FindRoot(workDir, workDir, cfg.RootMarkers); root == workDir || !hasLiteralMarker(cfg.RootMarkers) {
+			app.lspManager.StartRoot(name, workDir, "")
+		} else {
+			slog.Debug("Deferring LSP client start until a file in a nested root is opened", "name", name)
+		}
+	}
+}
+
+// hasLiteralMarker reports whether any of the root markers is a plain file
+// name rather than a glob pattern.
+func hasLiteralMarker(markers []string) bool {
+	for _, marker := range markers {
+		if !strings.ContainsAny(marker, "*?[{") {
+			return true
+		}
 	}
+	return false
 }
 
 func toOurConfig(in *powernapconfig.ServerConfig) config.LSPConfig {
@@ -83,8 +106,11 @@ func toOurConfig(in *powernapconfig.ServerConfig) config.LSPConfig {
 	}
 }
 
-// createAndStartLSPClient creates a new LSP client, initializes it, and starts its workspace watcher.
-func (app *App) createAndStartLSPClient(ctx context.Context, name string, config config.LSPConfig, userConfigured bool) {
+// createAndStartLSPClient creates a new LSP client rooted at root, initializes
+// it, and registers it under key. If path is not empty, the file is opened
+// once the client is ready.
+func (app *App) createAndStartLSPClient(ctx context.Context, key, name string, config config.LSPConfig, root, path string) {
+	userConfigured, _ := app.userConfiguredLSPs.Get(name)
 	if !userConfigured {
 		if _, err := exec.LookPath(config.Command); err != nil {
 			slog.Warn("Default LSP config skipped: server not installed", "name", name, "error", err)
@@ -92,36 +118,43 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 		}
 	}
 
-	slog.Debug("Creating LSP client", "name", name, "command", config.Command, "fileTypes", config.FileTypes, "args", config.Args)
+	relRoot, err := filepath.Rel(app.Config().WorkingDir(), root)
+	if err != nil {
+		relRoot = root
+	}
+
+	slog.Debug("Creating LSP client", "name", name, "root", relRoot, "command", config.Command, "fileTypes", config.FileTypes, "args", config.Args)
 
 	// Update state to starting.
-	updateLSPState(name, lsp.StateStarting, nil, nil, 0)
+	updateLSPState(key, relRoot, lsp.StateStarting, nil, nil, 0)
 
 	// Create LSP client.
-	lspClient, err := lsp.New(ctx, name, config, app.config.Resolver())
+	lspClient, err := lsp.New(ctx, name, config, app.Config().Resolver(), root)
 	if err != nil {
 		if !userConfigured {
 			slog.Warn("Default LSP config skipped due to error", "name", name, "error", err)
-			updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+			updateLSPState(key, relRoot, lsp.StateDisabled, nil, nil, 0)
 			return
 		}
 		slog.Error("Failed to create LSP client for", "name", name, "error", err)
-		updateLSPState(name, lsp.StateError, err, nil, 0)
+		updateLSPState(key, relRoot, lsp.StateError, err, nil, 0)
 		return
 	}
 
 	// Set diagnostics callback
-	lspClient.SetDiagnosticsCallback(updateLSPDiagnostics)
+	lspClient.SetDiagnosticsCallback(func(_ string, count int) {
+		updateLSPDiagnostics(key, count)
+	})
 
 	// Increase initialization timeout as some servers take more time to start.
 	initCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Initialize LSP client.
-	_, err = lspClient.Initialize(initCtx, app.config.WorkingDir())
+	_, err = lspClient.Initialize(initCtx, root)
 	if err != nil {
 		slog.Error("LSP client initialization failed", "name", name, "error", err)
-		updateLSPState(name, lsp.StateError, err, lspClient, 0)
+		updateLSPState(key, relRoot, lsp.StateError, err, lspClient, 0)
 		lspClient.Close(ctx)
 		return
 	}
@@ -132,16 +165,22 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 		// Server never reached a ready state, but let's continue anyway, as
 		// some functionality might still work.
 		lspClient.SetServerState(lsp.StateError)
-		updateLSPState(name, lsp.StateError, err, lspClient, 0)
+		updateLSPState(key, relRoot, lsp.StateError, err, lspClient, 0)
 	} else {
 		// Server reached a ready state successfully.
 		slog.Debug("LSP server is ready", "name", name)
 		lspClient.SetServerState(lsp.StateReady)
-		updateLSPState(name, lsp.StateReady, nil, lspClient, 0)
+		updateLSPState(key, relRoot, lsp.StateReady, nil, lspClient, 0)
 	}
 
-	slog.Debug("LSP client initialized", "name", name)
+	slog.Debug("LSP client initialized", "name", name, "root", relRoot)
 
 	// Add to map with mutex protection before starting goroutine
-	app.LSPClients.Set(name, lspClient)
+	app.LSPClients.Set(key, lspClient)
+
+	if path != "" {
+		if err := lspClient.OpenFileOnDemand(ctx, path); err != nil {
+			slog.Warn("Failed to open file in new LSP client", "name", name, "file", path, "error", err)
+		}
+	}
 }
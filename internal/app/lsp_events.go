@@ -28,7 +28,11 @@ type LSPEvent struct {
 
 // LSPClientInfo holds information about an LSP client's state
 type LSPClientInfo struct {
-	Name            string
+	// Name is the key the client is registered under, see [lsp.ClientKey].
+	Name string
+	// Root is the project root of the client, relative to the working
+	// directory.
+	Root            string
 	State           lsp.ServerState
 	Error           error
 	Client          *lsp.Client
@@ -57,9 +61,10 @@ func GetLSPState(name string) (LSPClientInfo, bool) {
 }
 
 // updateLSPState updates the state of an LSP client and publishes an event
-func updateLSPState(name string, state lsp.ServerState, err error, client *lsp.Client, diagnosticCount int) {
+func updateLSPState(name, root string, state lsp.ServerState, err error, client *lsp.Client, diagnosticCount int) {
 	info := LSPClientInfo{
 		Name:            name,
+		Root:            root,
 		State:           state,
 		Error:           err,
 		Client:          client,
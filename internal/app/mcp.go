@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+)
+
+// RestartMCP reconnects to the MCP server name and makes its tools available
+// to the agent again.
+func (app *App) RestartMCP(ctx context.Context, name string) error {
+	mcp.Enable(app.globalCtx, name)
+	return app.updateMCPTools(ctx)
+}
+
+// SetMCPDisabled disables or enables the MCP server name, saving the choice
+// in the config.
+func (app *App) SetMCPDisabled(ctx context.Context, name string, disabled bool) error {
+	if err := app.Config().SetMCPDisabled(name, disabled); err != nil {
+		return err
+	}
+	if disabled {
+		mcp.Disable(name)
+	} else {
+		mcp.Enable(app.globalCtx, name)
+	}
+	return app.updateMCPTools(ctx)
+}
+
+// updateMCPTools rebuilds the agent so it uses the current MCP tools.
+func (app *App) updateMCPTools(ctx context.Context) error {
+	if app.AgentCoordinator == nil {
+		return nil
+	}
+	return app.AgentCoordinator.UpdateModels(ctx)
+}
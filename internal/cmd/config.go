@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/tidwall/gjson"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and change the configuration",
+	Long:  "Show the configuration merged from all config files, and get, set or validate its values",
+	Example: `
+# Show the merged configuration
+crush config show
+
+# Show where each value comes from
+crush config show --origin
+
+# Show the API keys, tokens and headers too
+crush config show --show-secrets
+
+# Get a single value
+crush config get options.tui.compact_mode
+
+# Set a value in the global config
+crush config set --global options.tui.compact_mode true
+
+# Remove a value from the project config
+crush config unset --project options.tui.compact_mode
+
+# Check the config files for mistakes
+crush config validate
+  `,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the merged configuration",
+	Long:  "Show the configuration merged from all config files and defaults, without resolving the providers",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		showOrigin, _ := cmd.Flags().GetBool("origin")
+
+		merged, settings, err := inspectConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case jsonOutput && showOrigin:
+			data, err := json.MarshalIndent(settings, "", "  ")
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(data))
+		case jsonOutput:
+			cmd.Println(string(merged))
+		default:
+			for _, s := range settings {
+				if showOrigin {
+					cmd.Printf("%s = %s  # %s\n", s.Key, s.Value, s.Origin)
+					continue
+				}
+				cmd.Printf("%s = %s\n", s.Key, s.Value)
+			}
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a value of the merged configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merged, _, err := inspectConfig(cmd)
+		if err != nil {
+			return err
+		}
+		value := gjson.GetBytes(merged, args[0])
+		if !value.Exists() {
+			return fmt.Errorf("key %s is not set", args[0])
+		}
+		if value.Type == gjson.String {
+			cmd.Println(value.Str)
+			return nil
+		}
+		cmd.Println(value.Raw)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a configuration value. Values that are valid JSON, like true, 42 or
+["a", "b"], are saved as such, anything else is saved as a string.
+
+The value is saved in the data config unless --global or --project is given.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+		var value any = args[1]
+		if json.Valid([]byte(args[1])) {
+			value = json.RawMessage(args[1])
+		}
+		if err := config.SetField(path, args[0], value); err != nil {
+			return err
+		}
+		cmd.Printf("Set %s in %s\n", args[0], path)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long:  "Remove a configuration value from the data config, or from the global or project config with --global or --project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+		if err := config.RemoveField(path, args[0]); err != nil {
+			return err
+		}
+		cmd.Printf("Removed %s from %s\n", args[0], path)
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config files for mistakes",
+	Long:  "Check every config file against the configuration schema, reporting invalid JSON, values of the wrong type and unknown keys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := ResolveCwd(cmd)
+		if err != nil {
+			return err
+		}
+		problems, err := config.Validate(cwd)
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			cmd.Println("Configuration is valid.")
+			return nil
+		}
+		for _, p := range problems {
+			cmd.Println(p)
+		}
+		return fmt.Errorf("found %d problem(s) in the configuration", len(problems))
+	},
+}
+
+func init() {
+	configShowCmd.Flags().Bool("json", false, "Output as JSON")
+	configShowCmd.Flags().Bool("origin", false, "Show where each value comes from")
+	for _, c := range []*cobra.Command{configShowCmd, configGetCmd} {
+		c.Flags().Bool("show-secrets", false, "Show API keys, tokens and headers instead of redacting them")
+	}
+
+	for _, c := range []*cobra.Command{configSetCmd, configUnsetCmd} {
+		c.Flags().Bool("global", false, "Use the global config")
+		c.Flags().Bool("project", false, "Use the config of the project")
+		c.MarkFlagsMutuallyExclusive("global", "project")
+	}
+
+	configCmd.AddCommand(
+		configShowCmd,
+		configGetCmd,
+		configSetCmd,
+		configUnsetCmd,
+		configValidateCmd,
+	)
+}
+
+func inspectConfig(cmd *cobra.Command) ([]byte, []config.Setting, error) {
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	return config.Inspect(cwd, dataDir, ResolveProfile(cmd), showSecrets)
+}
+
+// configScope returns the config file set and unset change.
+func configScope(cmd *cobra.Command) (string, error) {
+	global, _ := cmd.Flags().GetBool("global")
+	project, _ := cmd.Flags().GetBool("project")
+	switch {
+	case global:
+		return config.GlobalConfig(), nil
+	case project:
+		cwd, err := ResolveCwd(cmd)
+		if err != nil {
+			return "", err
+		}
+		return config.ProjectConfig(cwd), nil
+	default:
+		return config.GlobalConfigData(), nil
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/atotto/clipboard"
 	hyperp "github.com/charmbracelet/crush/internal/agent/hyper"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/oauth"
 	"github.com/charmbracelet/crush/internal/oauth/copilot"
@@ -20,25 +21,31 @@ import (
 
 var loginCmd = &cobra.Command{
 	Aliases: []string{"auth"},
-	Use:     "login [platform]",
+	Use:     "login [platform] [mcp name]",
 	Short:   "Login Crush to a platform",
 	Long: `Login Crush to a specified platform.
 The platform should be provided as an argument.
-Available platforms are: hyper, copilot.`,
+Available platforms are: hyper, copilot, mcp.
+The mcp platform authorizes Crush with an HTTP or SSE MCP server using OAuth,
+and takes the name of the server as a second argument.`,
 	Example: `
 # Authenticate with Charm Hyper
 crush login
 
 # Authenticate with GitHub Copilot
 crush login copilot
+
+# Authorize Crush with an MCP server
+crush login mcp linear
   `,
 	ValidArgs: []cobra.Completion{
 		"hyper",
 		"copilot",
 		"github",
 		"github-copilot",
+		"mcp",
 	},
-	Args: cobra.MaximumNArgs(1),
+	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app, err := setupAppWithProgressBar(cmd)
 		if err != nil {
@@ -55,6 +62,11 @@ crush login copilot
 			return loginHyper()
 		case "copilot", "github", "github-copilot":
 			return loginCopilot()
+		case "mcp":
+			if len(args) < 2 {
+				return fmt.Errorf("missing mcp name, usage: crush login mcp <name>")
+			}
+			return loginMCP(args[1])
 		default:
 			return fmt.Errorf("unknown platform: %s", args[0])
 		}
@@ -190,6 +202,32 @@ func loginCopilot() error {
 	return nil
 }
 
+func loginMCP(name string) error {
+	ctx := getLoginContext()
+	cfg := config.Get()
+
+	fmt.Printf("Discovering the authorization server of %s...\n", name)
+	err := mcp.Login(ctx, cfg, name, func(url string) error {
+		fmt.Println()
+		fmt.Println("Open the following URL to authorize Crush:")
+		fmt.Println()
+		fmt.Println(lipgloss.NewStyle().Hyperlink(url, "id=mcp").Render(url))
+		fmt.Println()
+		if err := browser.OpenURL(url); err != nil {
+			fmt.Println("Could not open the URL. You'll need to manually open the URL in your browser.")
+		}
+		fmt.Println("Waiting for authorization...")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("You're now authenticated with %s!\n", name)
+	return nil
+}
+
 func getLoginContext() context.Context {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	go func() {
@@ -33,7 +33,7 @@ crush models gpt5`,
 		dataDir, _ := cmd.Flags().GetString("data-dir")
 		debug, _ := cmd.Flags().GetBool("debug")
 
-		cfg, err := config.Init(cwd, dataDir, debug)
+		cfg, err := config.Init(cwd, dataDir, ResolveProfile(cmd), debug)
 		if err != nil {
 			return err
 		}
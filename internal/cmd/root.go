@@ -40,6 +40,7 @@ func init() {
 	rootCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
 	rootCmd.PersistentFlags().StringP("data-dir", "D", "", "Custom crush data directory")
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "Debug")
+	rootCmd.PersistentFlags().StringP("profile", "P", "", "Config profile to use, defaults to $CRUSH_PROFILE")
 	rootCmd.Flags().BoolP("help", "h", false, "Help")
 	rootCmd.Flags().BoolP("yolo", "y", false, "Automatically accept all permissions (dangerous mode)")
 
@@ -50,8 +51,10 @@ func init() {
 		updateProvidersCmd,
 		logsCmd,
 		schemaCmd,
+		configCmd,
 		loginCmd,
 		statsCmd,
+		skillsCmd,
 	)
 }
 
@@ -80,6 +83,9 @@ crush run "Explain the use of context in Go"
 
 # Run in dangerous mode (auto-accept all permissions)
 crush -y
+
+# Run with the settings of the work profile
+crush --profile work
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app, err := setupAppWithProgressBar(cmd)
@@ -207,7 +213,7 @@ func setupApp(cmd *cobra.Command) (*app.App, error) {
 		return nil, err
 	}
 
-	cfg, err := config.Init(cwd, dataDir, debug)
+	cfg, err := config.Init(cwd, dataDir, ResolveProfile(cmd), debug)
 	if err != nil {
 		return nil, err
 	}
@@ -278,6 +284,15 @@ func MaybePrependStdin(prompt string) (string, error) {
 	return string(bts) + "\n\n" + prompt, nil
 }
 
+// ResolveProfile returns the profile the config is loaded with: the one
+// given with --profile, or else $CRUSH_PROFILE.
+func ResolveProfile(cmd *cobra.Command) string {
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+		return profile
+	}
+	return os.Getenv("CRUSH_PROFILE")
+}
+
 func ResolveCwd(cmd *cobra.Command) (string, error) {
 	cwd, _ := cmd.Flags().GetString("cwd")
 	if cwd != "" {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,6 +10,10 @@ import (
 	"strings"
 
 	"charm.land/log/v2"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/commands"
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/event"
 	"github.com/spf13/cobra"
 )
@@ -33,6 +38,15 @@ crush run --quiet "Generate a README for this project"
 
 # Run in verbose mode
 crush run --verbose "Generate a README for this project"
+
+# Run a custom command with arguments
+crush run /project:review-pr --arg PR=123
+
+# Run a custom command with shell blocks
+crush run /project:review-pr --allow-shell --arg PR=123
+
+# Run an MCP prompt
+crush run --mcp-prompt github:summarize-issue --arg issue=42
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		quiet, _ := cmd.Flags().GetBool("quiet")
@@ -58,7 +72,26 @@ crush run --verbose "Generate a README for this project"
 			slog.SetDefault(slog.New(log.New(os.Stderr)))
 		}
 
+		argValues, err := parseRunArgs(cmd)
+		if err != nil {
+			return err
+		}
+		mcpPrompt, _ := cmd.Flags().GetString("mcp-prompt")
+		allowShell, _ := cmd.Flags().GetBool("allow-shell")
+
+		var opts agent.RunOptions
 		prompt := strings.Join(args, " ")
+		switch {
+		case mcpPrompt != "":
+			prompt, err = expandMCPPrompt(ctx, mcpPrompt, argValues, prompt)
+		case len(args) > 0 && isCustomCommand(args[0]):
+			prompt, opts, err = expandCustomCommand(ctx, app.Config(), args[0], argValues, strings.Join(args[1:], " "), allowShell)
+		case len(argValues) > 0:
+			err = errors.New("--arg requires a custom command or --mcp-prompt")
+		}
+		if err != nil {
+			return err
+		}
 
 		prompt, err = MaybePrependStdin(prompt)
 		if err != nil {
@@ -73,7 +106,7 @@ crush run --verbose "Generate a README for this project"
 		event.SetNonInteractive(true)
 		event.AppInitialized()
 
-		return app.RunNonInteractive(ctx, os.Stdout, prompt, largeModel, smallModel, quiet || verbose)
+		return app.RunNonInteractive(ctx, os.Stdout, prompt, largeModel, smallModel, opts, quiet || verbose)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		event.AppExited()
@@ -85,4 +118,97 @@ func init() {
 	runCmd.Flags().BoolP("verbose", "v", false, "Show logs")
 	runCmd.Flags().StringP("model", "m", "", "Model to use. Accepts 'model' or 'provider/model' to disambiguate models with the same name across providers")
 	runCmd.Flags().String("small-model", "", "Small model to use. If not provided, uses the default small model for the provider")
+	runCmd.Flags().String("mcp-prompt", "", "MCP prompt to run, as 'server:prompt'")
+	runCmd.Flags().StringArray("arg", nil, "Argument of the custom command or MCP prompt, as 'NAME=value'. Can be repeated")
+	runCmd.Flags().Bool("allow-shell", false, "Run the shell blocks of the custom command without asking")
+}
+
+// parseRunArgs returns the values of the --arg flags.
+func parseRunArgs(cmd *cobra.Command) (map[string]string, error) {
+	flags, _ := cmd.Flags().GetStringArray("arg")
+	values := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid argument %q, use NAME=value", flag)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// isCustomCommand reports whether arg names a custom command, such as
+// /project:review.
+func isCustomCommand(arg string) bool {
+	return strings.HasPrefix(arg, "/user:") || strings.HasPrefix(arg, "/project:")
+}
+
+// expandCustomCommand returns the prompt of the custom command named by arg,
+// followed by extra, and the options to run it with. Its shell blocks are
+// only run with allowShell, since nobody can be asked for permission.
+func expandCustomCommand(ctx context.Context, cfg *config.Config, arg string, values map[string]string, extra string, allowShell bool) (string, agent.RunOptions, error) {
+	customCommands, err := commands.LoadCustomCommands(cfg)
+	if err != nil {
+		return "", agent.RunOptions{}, err
+	}
+	id := strings.TrimPrefix(arg, "/")
+	command, ok := commands.FindCustomCommand(customCommands, id)
+	if !ok {
+		return "", agent.RunOptions{}, fmt.Errorf("custom command %q not found", id)
+	}
+	args, err := commands.ResolveArgs(command.Arguments, values)
+	if err != nil {
+		return "", agent.RunOptions{}, fmt.Errorf("%s: %w", id, err)
+	}
+	run := refuseShell
+	if allowShell {
+		run = commands.Shell(cfg.WorkingDir())
+	}
+	prompt, err := command.Expand(ctx, cfg.WorkingDir(), args, run)
+	if err != nil {
+		return "", agent.RunOptions{}, err
+	}
+	return joinPrompt(prompt, extra), agent.RunOptions{
+		Model:        command.Model,
+		Agent:        command.Agent,
+		AllowedTools: command.AllowedTools,
+	}, nil
+}
+
+// refuseShell is the [commands.ShellFunc] of custom commands run without
+// --allow-shell.
+func refuseShell(_ context.Context, command string) (string, error) {
+	return "", fmt.Errorf("the command runs %q, use --allow-shell to run its shell blocks", command)
+}
+
+// expandMCPPrompt returns the messages of the MCP prompt id, followed by
+// extra.
+func expandMCPPrompt(ctx context.Context, id string, values map[string]string, extra string) (string, error) {
+	if err := mcp.WaitForInit(ctx); err != nil {
+		return "", fmt.Errorf("failed to wait for MCP initialization: %w", err)
+	}
+	prompts, err := commands.LoadMCPPrompts()
+	if err != nil {
+		return "", err
+	}
+	prompt, ok := commands.FindMCPPrompt(prompts, id)
+	if !ok {
+		return "", fmt.Errorf("MCP prompt %q not found", id)
+	}
+	args, err := commands.ResolveArgs(prompt.Arguments, values)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", id, err)
+	}
+	content, err := commands.GetMCPPrompt(prompt.ClientID, prompt.PromptID, args)
+	if err != nil {
+		return "", err
+	}
+	return joinPrompt(content, extra), nil
+}
+
+func joinPrompt(prompt, extra string) string {
+	if extra == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + extra
 }
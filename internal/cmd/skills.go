@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/skills"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var skillsCmd = &cobra.Command{
+	Use:   "skills",
+	Short: "Manage Agent Skills",
+	Long:  "List, inspect, validate, install and remove the Agent Skills available to Crush",
+	Example: `
+# List skills, including the ones that fail validation
+crush skills list
+
+# Install a skill for every project
+crush skills install ./pdf-processing
+
+# Install a skill archive in the current project
+crush skills install --project pdf-processing.zip
+
+# Remove a skill
+crush skills remove pdf-processing
+  `,
+}
+
+var skillsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List skills and their validation errors",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := loadSkillsConfig(cmd)
+		if err != nil {
+			return err
+		}
+		entries := skills.Scan(skillsPaths(cfg))
+
+		if jsonOutput {
+			type skillEntry struct {
+				Name  string `json:"name,omitempty"`
+				Path  string `json:"path"`
+				Error string `json:"error,omitempty"`
+			}
+			output := struct {
+				Skills []skillEntry `json:"skills"`
+			}{Skills: []skillEntry{}}
+			for _, e := range entries {
+				output.Skills = append(output.Skills, skillEntry{
+					Name:  skillName(e),
+					Path:  e.Path,
+					Error: skillError(e),
+				})
+			}
+
+			data, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(data))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			cmd.Println("No skills found.")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			// We're in a TTY: make it fancy.
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Name", "Path", "Errors")
+
+			for _, e := range entries {
+				t.Row(skillName(e), home.Short(e.Path), skillError(e))
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		// Not a TTY: plain output
+		for _, e := range entries {
+			cmd.Printf("%s\t%s\t%s\n", skillName(e), e.Path, skillError(e))
+		}
+		return nil
+	},
+}
+
+var skillsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a skill's metadata and instructions",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadSkillsConfig(cmd)
+		if err != nil {
+			return err
+		}
+		entry, err := findSkill(skills.Scan(skillsPaths(cfg)), args[0])
+		if err != nil {
+			return err
+		}
+
+		s := entry.Skill
+		cmd.Printf("Name:        %s\n", s.Name)
+		cmd.Printf("Description: %s\n", s.Description)
+		cmd.Printf("Path:        %s\n", entry.Path)
+		if s.License != "" {
+			cmd.Printf("License:     %s\n", s.License)
+		}
+		if s.Compatibility != "" {
+			cmd.Printf("Compatible:  %s\n", s.Compatibility)
+		}
+		if entry.Err != nil {
+			cmd.Printf("Errors:      %s\n", skillError(entry))
+		}
+		if s.Instructions != "" {
+			cmd.Printf("\n%s\n", s.Instructions)
+		}
+		return nil
+	},
+}
+
+var skillsValidateCmd = &cobra.Command{
+	Use:   "validate [path...]",
+	Short: "Validate skills",
+	Long:  "Validate the skills in the given directories, or all the skills Crush discovers when none are given",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if len(paths) == 0 {
+			cfg, err := loadSkillsConfig(cmd)
+			if err != nil {
+				return err
+			}
+			paths = skillsPaths(cfg)
+		}
+
+		entries := skills.Scan(paths)
+		if len(entries) == 0 {
+			return fmt.Errorf("no %s found", skills.SkillFileName)
+		}
+		invalid := 0
+		for _, e := range entries {
+			if e.Err != nil {
+				invalid++
+				cmd.Printf("✗ %s: %s\n", e.Path, skillError(e))
+				continue
+			}
+			cmd.Printf("✓ %s\n", e.Path)
+		}
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d skills are invalid", invalid, len(entries))
+		}
+		return nil
+	},
+}
+
+var skillsInstallCmd = &cobra.Command{
+	Use:   "install <directory|archive>",
+	Short: "Install a skill from a directory or a tar/zip archive",
+	Long: `Validate a skill and copy it into the first writable global skills directory,
+or into the project skills directory with --project`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetBool("project")
+		force, _ := cmd.Flags().GetBool("force")
+
+		dir, err := skillsInstallDir(cmd, project)
+		if err != nil {
+			return err
+		}
+		skill, err := skills.Install(args[0], dir, force)
+		if errors.Is(err, skills.ErrSkillExists) {
+			return fmt.Errorf("%w, use --force to replace it", err)
+		}
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Installed %s in %s\n", skill.Name, skill.Path)
+		return nil
+	},
+}
+
+var skillsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an installed skill",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadSkillsConfig(cmd)
+		if err != nil {
+			return err
+		}
+		entry, err := findSkill(skills.Scan(skillsPaths(cfg)), args[0])
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(entry.Skill.Path)
+		if err := skills.Remove(dir, filepath.Base(entry.Skill.Path)); err != nil {
+			return err
+		}
+		cmd.Printf("Removed %s from %s\n", args[0], dir)
+		return nil
+	},
+}
+
+func init() {
+	skillsListCmd.Flags().Bool("json", false, "Output as JSON")
+	skillsInstallCmd.Flags().Bool("project", false, "Install in the project skills directory")
+	skillsInstallCmd.Flags().BoolP("force", "f", false, "Replace an installed skill with the same name")
+
+	skillsCmd.AddCommand(
+		skillsListCmd,
+		skillsShowCmd,
+		skillsValidateCmd,
+		skillsInstallCmd,
+		skillsRemoveCmd,
+	)
+}
+
+func loadSkillsConfig(cmd *cobra.Command) (*config.Config, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	debug, _ := cmd.Flags().GetBool("debug")
+	cfg, err := config.Load(cwd, dataDir, ResolveProfile(cmd), debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+// skillsPaths returns the configured skills paths, expanded and relative to
+// the working directory.
+func skillsPaths(cfg *config.Config) []string {
+	paths := make([]string, 0, len(cfg.Options.SkillsPaths))
+	for _, p := range cfg.Options.SkillsPaths {
+		p = home.Long(p)
+		if strings.HasPrefix(p, "$") {
+			if expanded, err := cfg.Resolver().ResolveValue(p); err == nil {
+				p = expanded
+			}
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(cfg.WorkingDir(), p)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// skillsInstallDir returns the project skills directory, or the first global
+// skills directory that can be written to.
+func skillsInstallDir(cmd *cobra.Command, project bool) (string, error) {
+	if project {
+		cfg, err := loadSkillsConfig(cmd)
+		if err != nil {
+			return "", err
+		}
+		return cfg.ProjectSkillsDir(), nil
+	}
+	for _, dir := range config.GlobalSkillsDirs() {
+		if isWritableDir(dir) {
+			return dir, nil
+		}
+	}
+	return "", errors.New("no writable global skills directory, use --project to install in the project")
+}
+
+func isWritableDir(dir string) bool {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".crush-write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// findSkill returns the first parsed skill named name.
+func findSkill(entries []skills.Entry, name string) (skills.Entry, error) {
+	for _, e := range entries {
+		if e.Skill != nil && e.Skill.Name == name {
+			return e, nil
+		}
+	}
+	return skills.Entry{}, fmt.Errorf("%w: %s", skills.ErrSkillNotFound, name)
+}
+
+func skillName(e skills.Entry) string {
+	if e.Skill == nil {
+		return "-"
+	}
+	return e.Skill.Name
+}
+
+func skillError(e skills.Entry) string {
+	if e.Err == nil {
+		return ""
+	}
+	return strings.ReplaceAll(e.Err.Error(), "\n", "; ")
+}
@@ -124,7 +124,7 @@ func runStats(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 
 	if dataDir == "" {
-		cfg, err := config.Init("", "", false)
+		cfg, err := config.Init("", "", ResolveProfile(cmd), false)
 		if err != nil {
 			return fmt.Errorf("failed to initialize config: %w", err)
 		}
@@ -1,19 +1,27 @@
 package commands
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/home"
+	"gopkg.in/yaml.v3"
 )
 
-var namedArgPattern = regexp.MustCompile(`\$([A-Z][A-Z0-9_]*)`)
+var (
+	namedArgPattern = regexp.MustCompile(`\$([A-Z][A-Z0-9_]*)`)
+	argNamePattern  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
 
 const (
 	userCommandPrefix    = "user:"
@@ -26,6 +34,8 @@ type Argument struct {
 	Title       string
 	Description string
 	Required    bool
+	// Default is used when the argument is left empty.
+	Default string
 }
 
 // MCPPrompt represents a custom command loaded from an MCP server.
@@ -40,10 +50,38 @@ type MCPPrompt struct {
 
 // CustomCommand represents a user-defined custom command loaded from markdown files.
 type CustomCommand struct {
-	ID        string
-	Name      string
+	ID          string
+	Name        string
+	Description string
+	// Content is the prompt, without the frontmatter.
 	Content   string
 	Arguments []Argument
+	// Model is the model to run the command with: large, small, or a model
+	// ID, optionally prefixed with its provider. Empty uses the current
+	// model.
+	Model string
+	// Agent is the agent whose tools the command can use.
+	Agent string
+	// AllowedTools limits the tools the command can use, nil allows them
+	// all.
+	AllowedTools []string
+}
+
+// frontmatter is the optional YAML header of a command file.
+type frontmatter struct {
+	Description  string         `yaml:"description"`
+	Arguments    []argumentSpec `yaml:"arguments"`
+	Model        string         `yaml:"model"`
+	Agent        string         `yaml:"agent"`
+	AllowedTools []string       `yaml:"allowed_tools"`
+}
+
+type argumentSpec struct {
+	Name        string `yaml:"name"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Default     string `yaml:"default"`
+	Optional    bool   `yaml:"optional"`
 }
 
 type commandSource struct {
@@ -89,6 +127,60 @@ func LoadMCPPrompts() ([]MCPPrompt, error) {
 	return commands, nil
 }
 
+// FindCustomCommand returns the command with the given ID, such as
+// project:review.
+func FindCustomCommand(commands []CustomCommand, id string) (CustomCommand, bool) {
+	for _, cmd := range commands {
+		if cmd.ID == id {
+			return cmd, true
+		}
+	}
+	return CustomCommand{}, false
+}
+
+// FindMCPPrompt returns the prompt with the given ID, made of the MCP server
+// and prompt names, such as github:review.
+func FindMCPPrompt(prompts []MCPPrompt, id string) (MCPPrompt, bool) {
+	for _, prompt := range prompts {
+		if prompt.ID == id {
+			return prompt, true
+		}
+	}
+	return MCPPrompt{}, false
+}
+
+// ResolveArgs returns the values of arguments, using their defaults for the
+// ones left empty. It fails on values of unknown arguments and lists the
+// required arguments that are missing.
+func ResolveArgs(arguments []Argument, values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(arguments))
+	var missing []string
+	for _, arg := range arguments {
+		value := values[arg.ID]
+		if strings.TrimSpace(value) == "" {
+			value = arg.Default
+		}
+		if arg.Required && strings.TrimSpace(value) == "" {
+			missing = append(missing, arg.ID)
+		}
+		resolved[arg.ID] = value
+	}
+	var unknown []string
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("unknown arguments: %s", strings.Join(unknown, ", "))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments: %s", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
 func buildCommandSources(cfg *config.Config) []commandSource {
 	var sources []commandSource
 
@@ -159,30 +251,93 @@ func loadCommand(path, baseDir, prefix string) (CustomCommand, error) {
 		return CustomCommand{}, err
 	}
 
-	id := buildCommandID(path, baseDir, prefix)
+	return parseCommand(buildCommandID(path, baseDir, prefix), string(content))
+}
+
+// parseCommand parses the content of a command file, made of an optional
+// YAML frontmatter and the prompt.
+func parseCommand(id, content string) (CustomCommand, error) {
+	header, body, err := splitFrontmatter(content)
+	if err != nil {
+		return CustomCommand{}, err
+	}
+
+	var fm frontmatter
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		return CustomCommand{}, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+
+	args := make([]Argument, 0, len(fm.Arguments))
+	for _, spec := range fm.Arguments {
+		if !argNamePattern.MatchString(spec.Name) {
+			return CustomCommand{}, fmt.Errorf("invalid argument name %q, use upper case letters, digits and underscores", spec.Name)
+		}
+		args = append(args, Argument{
+			ID:          spec.Name,
+			Title:       cmp.Or(spec.Title, spec.Name),
+			Description: spec.Description,
+			Required:    !spec.Optional && spec.Default == "",
+			Default:     spec.Default,
+		})
+	}
+	args = append(args, extractArgNames(body, args)...)
+	if len(args) == 0 {
+		args = nil
+	}
 
 	return CustomCommand{
-		ID:        id,
-		Name:      id,
-		Content:   string(content),
-		Arguments: extractArgNames(string(content)),
+		ID:           id,
+		Name:         id,
+		Description:  fm.Description,
+		Content:      body,
+		Arguments:    args,
+		Model:        fm.Model,
+		Agent:        fm.Agent,
+		AllowedTools: fm.AllowedTools,
 	}, nil
 }
 
-func extractArgNames(content string) []Argument {
+// splitFrontmatter splits content into its YAML frontmatter, if any, and
+// body.
+func splitFrontmatter(content string) (frontmatter, body string, err error) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(normalized, "---\n")
+	if !ok {
+		return "", content, nil
+	}
+	// The newline lets the frontmatter be closed right away.
+	frontmatter, body, ok = strings.Cut("\n"+rest, "\n---")
+	if !ok {
+		return "", "", errors.New("unclosed frontmatter")
+	}
+	frontmatter = strings.TrimPrefix(frontmatter, "\n")
+	// Drop the rest of the closing line.
+	if _, after, ok := strings.Cut(body, "\n"); ok {
+		body = after
+	} else {
+		body = ""
+	}
+	return frontmatter, strings.TrimLeft(body, "\n"), nil
+}
+
+// extractArgNames returns the arguments used in content that aren't
+// declared, which are all required.
+func extractArgNames(content string, declared []Argument) []Argument {
 	matches := namedArgPattern.FindAllStringSubmatch(content, -1)
 	if len(matches) == 0 {
 		return nil
 	}
 
 	seen := make(map[string]bool)
+	for _, arg := range declared {
+		seen[arg.ID] = true
+	}
 	var args []Argument
 
 	for _, match := range matches {
 		arg := match[1]
 		if !seen[arg] {
 			seen[arg] = true
-			// for normal custom commands, all args are required
 			args = append(args, Argument{ID: arg, Title: arg, Required: true})
 		}
 	}
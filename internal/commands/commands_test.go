@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	t.Parallel()
+
+	cmd, err := parseCommand("project:review", `---
+description: Review a branch
+arguments:
+  - name: BRANCH
+    description: Branch to review
+    default: main
+  - name: FOCUS
+    optional: true
+model: small
+agent: task
+allowed_tools: [view, grep]
+---
+
+Review $BRANCH, focusing on $FOCUS. Mention $TICKET.
+`)
+	require.NoError(t, err)
+	require.Equal(t, "Review a branch", cmd.Description)
+	require.Equal(t, "Review $BRANCH, focusing on $FOCUS. Mention $TICKET.\n", cmd.Content)
+	require.Equal(t, "small", cmd.Model)
+	require.Equal(t, "task", cmd.Agent)
+	require.Equal(t, []string{"view", "grep"}, cmd.AllowedTools)
+	require.Equal(t, []Argument{
+		{ID: "BRANCH", Title: "BRANCH", Description: "Branch to review", Default: "main"},
+		{ID: "FOCUS", Title: "FOCUS"},
+		{ID: "TICKET", Title: "TICKET", Required: true},
+	}, cmd.Arguments)
+
+	cmd, err = parseCommand("user:plain", "Just do $THING")
+	require.NoError(t, err)
+	require.Equal(t, "Just do $THING", cmd.Content)
+	require.Equal(t, []Argument{{ID: "THING", Title: "THING", Required: true}}, cmd.Arguments)
+
+	cmd, err = parseCommand("user:empty", "---\n---\nDo it")
+	require.NoError(t, err)
+	require.Equal(t, "Do it", cmd.Content)
+
+	_, err = parseCommand("user:bad", "---\narguments:\n  - name: lower\n---\n")
+	require.Error(t, err)
+	_, err = parseCommand("user:bad", "---\ndescription: never closed\n")
+	require.Error(t, err)
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("some notes\n"), 0o644))
+
+	cmd := CustomCommand{
+		Content:   "Branch $BRANCH, see @notes.md. Status:\n!`git status`\nPing @someone.",
+		Arguments: []Argument{{ID: "BRANCH", Default: "main"}},
+	}
+
+	var ran []string
+	run := func(_ context.Context, command string) (string, error) {
+		ran = append(ran, command)
+		return "clean @notes.md", nil
+	}
+	content, err := cmd.Expand(t.Context(), dir, nil, run)
+	require.NoError(t, err)
+	require.Equal(t, []string{"git status"}, ran)
+	require.Equal(t, "Branch main, see <file path=\"notes.md\">\nsome notes\n</file>. Status:\nclean @notes.md\nPing @someone.", content)
+
+	content, err = cmd.Expand(t.Context(), dir, map[string]string{"BRANCH": "dev"}, run)
+	require.NoError(t, err)
+	require.Contains(t, content, "Branch dev,")
+
+	_, err = cmd.Expand(t.Context(), dir, nil, func(context.Context, string) (string, error) {
+		return "", context.Canceled
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExpandArgsAreInert(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("notes"), 0o644))
+
+	cmd := CustomCommand{
+		Content:   "Review $PR\n!`gh pr view $PR`",
+		Arguments: []Argument{{ID: "PR"}},
+	}
+	var ran []string
+	run := func(_ context.Context, command string) (string, error) {
+		ran = append(ran, command)
+		return "ok", nil
+	}
+	content, err := cmd.Expand(t.Context(), dir, map[string]string{"PR": "1; rm -rf ~ !`id` @notes.md $PR"}, run)
+	require.NoError(t, err)
+	require.Equal(t, []string{`gh pr view '1; rm -rf ~ !` + "`id`" + ` @notes.md $PR'`}, ran)
+	require.Equal(t, "Review 1; rm -rf ~ !`id` @notes.md $PR\nok", content)
+
+	_, err = cmd.Expand(t.Context(), dir, map[string]string{"PR": "it's"}, run)
+	require.NoError(t, err)
+	require.Equal(t, `gh pr view 'it'\''s'`, ran[len(ran)-1])
+
+	outside := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0o644))
+	_, err = CustomCommand{Content: "See @" + outside}.Expand(t.Context(), dir, nil, run)
+	require.ErrorContains(t, err, "outside of the project")
+}
+
+func TestResolveArgs(t *testing.T) {
+	t.Parallel()
+
+	arguments := []Argument{
+		{ID: "PR", Required: true},
+		{ID: "BRANCH", Default: "main"},
+		{ID: "NOTE"},
+		{ID: "TICKET", Required: true},
+	}
+
+	args, err := ResolveArgs(arguments, map[string]string{"PR": "123", "TICKET": "CRU-1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"PR": "123", "BRANCH": "main", "NOTE": "", "TICKET": "CRU-1"}, args)
+
+	_, err = ResolveArgs(arguments, map[string]string{"PR": " "})
+	require.EqualError(t, err, "missing required arguments: PR, TICKET")
+
+	_, err = ResolveArgs(arguments, map[string]string{"PR": "1", "TICKET": "2", "OTHER": "3"})
+	require.EqualError(t, err, "unknown arguments: OTHER")
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+// maxIncludeSize is the maximum size of a file included with @path.
+const maxIncludeSize = 256 * 1024
+
+// expandPattern matches !`command` shell blocks and @path file includes.
+var expandPattern = regexp.MustCompile("!`([^`\n]+)`|(^|\\s)@([^\\s`]+)")
+
+// ShellFunc runs a shell command of a command's prompt and returns its
+// output.
+type ShellFunc func(ctx context.Context, command string) (string, error)
+
+// Expand returns the prompt of the command with the output of its !`command`
+// blocks run with run, the content of the files of its @path includes, which
+// must be in workingDir, and its arguments replaced by args, or their
+// defaults.
+//
+// Arguments are replaced after the blocks and includes are expanded, so their
+// values are inert text: they can't add blocks or includes. In shell blocks,
+// values are quoted as single shell words.
+func (c CustomCommand) Expand(ctx context.Context, workingDir string, args map[string]string, run ShellFunc) (string, error) {
+	values := make(map[string]string, len(args)+len(c.Arguments))
+	maps.Copy(values, args)
+	for _, arg := range c.Arguments {
+		if strings.TrimSpace(values[arg.ID]) == "" && arg.Default != "" {
+			values[arg.ID] = arg.Default
+		}
+	}
+
+	// A single pass over the template, so shell output, included files and
+	// argument values aren't expanded in turn.
+	var sb strings.Builder
+	var last int
+	for _, loc := range expandPattern.FindAllStringSubmatchIndex(c.Content, -1) {
+		sb.WriteString(SubstituteArgs(c.Content[last:loc[0]], values))
+		last = loc[1]
+		match := c.Content[loc[0]:loc[1]]
+		if loc[2] >= 0 {
+			command := substituteArgsFunc(c.Content[loc[2]:loc[3]], values, shellQuote)
+			output, err := run(ctx, command)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(output)
+			continue
+		}
+		included, err := includeFile(workingDir, c.Content[loc[6]:loc[7]])
+		if err != nil {
+			return "", err
+		}
+		if included == "" {
+			sb.WriteString(SubstituteArgs(match, values))
+			continue
+		}
+		sb.WriteString(c.Content[loc[4]:loc[5]] + included)
+	}
+	sb.WriteString(SubstituteArgs(c.Content[last:], values))
+	return sb.String(), nil
+}
+
+// SubstituteArgs replaces $ARG_NAME placeholders in content with the values
+// in args. Placeholders of other names are left alone.
+func SubstituteArgs(content string, args map[string]string) string {
+	return substituteArgsFunc(content, args, func(s string) string { return s })
+}
+
+func substituteArgsFunc(content string, args map[string]string, quote func(string) string) string {
+	return namedArgPattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		value, ok := args[placeholder[1:]]
+		if !ok {
+			return placeholder
+		}
+		return quote(value)
+	})
+}
+
+// shellQuote quotes s as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// includeFile returns the content of the file at path wrapped in a file tag,
+// or nothing when there's no such file, so mentions that aren't files are
+// left alone. Trailing punctuation is not part of the path. Files outside of
+// workingDir can't be included.
+func includeFile(workingDir, path string) (string, error) {
+	for _, p := range []string{path, strings.TrimRight(path, ".,;:!?)")} {
+		name := home.Long(p)
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(workingDir, name)
+		}
+		info, err := os.Stat(name)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if !inDir(name, workingDir) {
+			return "", fmt.Errorf("included file %s is outside of the project", p)
+		}
+		if info.Size() > maxIncludeSize {
+			return "", fmt.Errorf("included file %s is larger than %d bytes", p, maxIncludeSize)
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("reading included file %s: %w", p, err)
+		}
+		tag := fmt.Sprintf("<file path=%q>\n%s\n</file>", p, strings.TrimRight(string(content), "\n"))
+		return tag + strings.TrimPrefix(path, p), nil
+	}
+	return "", nil
+}
+
+// inDir reports whether path is in dir, following symlinks.
+func inDir(path, dir string) bool {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	return fsext.HasPrefix(path, dir)
+}
+
+// Shell returns a [ShellFunc] that runs commands in workingDir, combining
+// their standard output and error.
+func Shell(workingDir string) ShellFunc {
+	sh := shell.NewShell(&shell.Options{WorkingDir: workingDir})
+	return func(ctx context.Context, command string) (string, error) {
+		stdout, stderr, err := sh.Exec(ctx, command)
+		if err != nil && shell.IsInterrupt(err) {
+			return "", err
+		}
+		return strings.TrimRight(stdout+stderr, "\n"), nil
+	}
+}
+
+// PermissionShell returns a [ShellFunc] that asks for permission before
+// running each command in workingDir, as the bash tool does.
+func PermissionShell(permissions permission.Service, sessionID, workingDir string) ShellFunc {
+	run := Shell(workingDir)
+	return func(ctx context.Context, command string) (string, error) {
+		ok, err := permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        workingDir,
+			ToolName:    tools.BashToolName,
+			Action:      "execute",
+			Description: fmt.Sprintf("Execute command: %s", command),
+			Params: tools.BashPermissionsParams{
+				Description: "Custom command shell block",
+				Command:     command,
+				WorkingDir:  workingDir,
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", permission.ErrorPermissionDenied
+		}
+		return run(ctx, command)
+	}
+}
@@ -81,7 +81,7 @@ func TestAttributionMigration(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			cfg, err := loadFromBytes([][]byte{[]byte(tt.configJSON)})
+			cfg, err := loadFromBytes([][]byte{[]byte(tt.configJSON)}, "")
 			require.NoError(t, err)
 
 			cfg.setDefaults(t.TempDir(), "")
@@ -127,6 +127,11 @@ type ProviderConfig struct {
 
 	// The provider models
 	Models []catwalk.Model `json:"models,omitempty" jsonschema:"description=List of models available from this provider"`
+
+	// Adds the models the provider lists to the configured ones.
+	DiscoverModels bool `json:"discover_models,omitempty" jsonschema:"description=Whether to add the models listed by the API of an openai-compat provider to the configured ones,default=false"`
+	// The IDs of the models that were discovered rather than configured.
+	DiscoveredModels []string `json:"-"`
 }
 
 // ToProvider converts the [ProviderConfig] to a [catwalk.Provider].
@@ -180,9 +185,26 @@ type MCPConfig struct {
 	Disabled      bool              `json:"disabled,omitempty" jsonschema:"description=Whether this MCP server is disabled,default=false"`
 	DisabledTools []string          `json:"disabled_tools,omitempty" jsonschema:"description=List of tools from this MCP server to disable,example=get-library-doc"`
 	Timeout       int               `json:"timeout,omitempty" jsonschema:"description=Timeout in seconds for MCP server connections,default=15,example=30,example=60,example=120"`
+	ToolTimeouts  map[string]int    `json:"tool_timeouts,omitempty" jsonschema:"description=Timeout in seconds for calls to specific tools of this MCP server by tool name, no timeout by default"`
+	AlwaysLoaded  []string          `json:"always_loaded_tools,omitempty" jsonschema:"description=Tools of this MCP server that are always sent to the model even when MCP tools are loaded on demand with tool_search,example=search"`
 
 	// TODO: maybe make it possible to get the value from the env
 	Headers map[string]string `json:"headers,omitempty" jsonschema:"description=HTTP headers for HTTP/SSE MCP servers"`
+
+	OAuth *MCPOAuth `json:"oauth,omitempty" jsonschema:"description=OAuth authorization for HTTP/SSE MCP servers, filled in by crush login mcp"`
+
+	AutoApproveSampling bool     `json:"auto_approve_sampling,omitempty" jsonschema:"description=Whether to let this MCP server generate completions with the small model without asking,default=false"`
+	Roots               []string `json:"roots,omitempty" jsonschema:"description=Extra directories shared with this MCP server as roots besides the working directory,example=~/notes"`
+}
+
+// MCPOAuth holds the OAuth client and token of an HTTP or SSE MCP server.
+type MCPOAuth struct {
+	ClientID     string       `json:"client_id,omitempty" jsonschema:"description=OAuth client ID, registered dynamically when empty"`
+	ClientSecret string       `json:"client_secret,omitempty" jsonschema:"description=OAuth client secret for confidential clients"`
+	RedirectURI  string       `json:"redirect_uri,omitempty" jsonschema:"description=Loopback redirect URI the client is registered with,example=http://127.0.0.1:33418/callback"`
+	Scopes       []string     `json:"scopes,omitempty" jsonschema:"description=OAuth scopes to request, defaults to the scopes the server supports"`
+	TokenURL     string       `json:"token_url,omitempty" jsonschema:"description=Token endpoint used to refresh the token"`
+	Token        *oauth.Token `json:"token,omitempty" jsonschema:"description=OAuth token for the MCP server"`
 }
 
 type LSPConfig struct {
@@ -244,21 +266,22 @@ func (Attribution) JSONSchemaExtend(schema *jsonschema.Schema) {
 }
 
 type Options struct {
-	ContextPaths              []string     `json:"context_paths,omitempty" jsonschema:"description=Paths to files containing context information for the AI,example=.cursorrules,example=CRUSH.md"`
-	SkillsPaths               []string     `json:"skills_paths,omitempty" jsonschema:"description=Paths to directories containing Agent Skills (folders with SKILL.md files),example=~/.config/crush/skills,example=./skills"`
-	TUI                       *TUIOptions  `json:"tui,omitempty" jsonschema:"description=Terminal user interface options"`
-	Debug                     bool         `json:"debug,omitempty" jsonschema:"description=Enable debug logging,default=false"`
-	DebugLSP                  bool         `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
-	DisableAutoSummarize      bool         `json:"disable_auto_summarize,omitempty" jsonschema:"description=Disable automatic conversation summarization,default=false"`
-	DataDirectory             string       `json:"data_directory,omitempty" jsonschema:"description=Directory for storing application data (relative to working directory),default=.crush,example=.crush"` // Relative to the cwd
-	DisabledTools             []string     `json:"disabled_tools,omitempty" jsonschema:"description=List of built-in tools to disable and hide from the agent,example=bash,example=sourcegraph"`
-	DisableProviderAutoUpdate bool         `json:"disable_provider_auto_update,omitempty" jsonschema:"description=Disable providers auto-update,default=false"`
-	DisableDefaultProviders   bool         `json:"disable_default_providers,omitempty" jsonschema:"description=Ignore all default/embedded providers. When enabled, providers must be fully specified in the config file with base_url, models, and api_key - no merging with defaults occurs,default=false"`
-	Attribution               *Attribution `json:"attribution,omitempty" jsonschema:"description=Attribution settings for generated content"`
-	DisableMetrics            bool         `json:"disable_metrics,omitempty" jsonschema:"description=Disable sending metrics,default=false"`
-	InitializeAs              string       `json:"initialize_as,omitempty" jsonschema:"description=Name of the context file to create/update during project initialization,default=AGENTS.md,example=AGENTS.md,example=CRUSH.md,example=CLAUDE.md,example=docs/LLMs.md"`
-	AutoLSP                   *bool        `json:"auto_lsp,omitempty" jsonschema:"description=Automatically setup LSPs based on root markers,default=true"`
-	Progress                  *bool        `json:"progress,omitempty" jsonschema:"description=Show indeterminate progress updates during long operations,default=true"`
+	ContextPaths              []string            `json:"context_paths,omitempty" jsonschema:"description=Paths to files containing context information for the AI,example=.cursorrules,example=CRUSH.md"`
+	SkillsPaths               []string            `json:"skills_paths,omitempty" jsonschema:"description=Paths to directories containing Agent Skills (folders with SKILL.md files),example=~/.config/crush/skills,example=./skills"`
+	AgentSkills               map[string][]string `json:"agent_skills,omitempty" jsonschema:"description=Skills available to each agent (coder or task). Agents not listed can use every skill"`
+	TUI                       *TUIOptions         `json:"tui,omitempty" jsonschema:"description=Terminal user interface options"`
+	Debug                     bool                `json:"debug,omitempty" jsonschema:"description=Enable debug logging,default=false"`
+	DebugLSP                  bool                `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
+	DisableAutoSummarize      bool                `json:"disable_auto_summarize,omitempty" jsonschema:"description=Disable automatic conversation summarization,default=false"`
+	DataDirectory             string              `json:"data_directory,omitempty" jsonschema:"description=Directory for storing application data (relative to working directory),default=.crush,example=.crush"` // Relative to the cwd
+	DisabledTools             []string            `json:"disabled_tools,omitempty" jsonschema:"description=List of built-in tools to disable and hide from the agent,example=bash,example=sourcegraph"`
+	DisableProviderAutoUpdate bool                `json:"disable_provider_auto_update,omitempty" jsonschema:"description=Disable providers auto-update,default=false"`
+	DisableDefaultProviders   bool                `json:"disable_default_providers,omitempty" jsonschema:"description=Ignore all default/embedded providers. When enabled, providers must be fully specified in the config file with base_url, models, and api_key - no merging with defaults occurs,default=false"`
+	Attribution               *Attribution        `json:"attribution,omitempty" jsonschema:"description=Attribution settings for generated content"`
+	DisableMetrics            bool                `json:"disable_metrics,omitempty" jsonschema:"description=Disable sending metrics,default=false"`
+	InitializeAs              string              `json:"initialize_as,omitempty" jsonschema:"description=Name of the context file to create/update during project initialization,default=AGENTS.md,example=AGENTS.md,example=CRUSH.md,example=CLAUDE.md,example=docs/LLMs.md"`
+	AutoLSP                   *bool               `json:"auto_lsp,omitempty" jsonschema:"description=Automatically setup LSPs based on root markers,default=true"`
+	Progress                  *bool               `json:"progress,omitempty" jsonschema:"description=Show indeterminate progress updates during long operations,default=true"`
 }
 
 type MCPs map[string]MCPConfig
@@ -345,10 +368,19 @@ type Agent struct {
 
 	// Overrides the context paths for this agent
 	ContextPaths []string `json:"context_paths,omitempty"`
+
+	// The skills available to the agent
+	//  if this is nil, all skills are available
+	AllowedSkills []string `json:"allowed_skills,omitempty"`
 }
 
 type Tools struct {
-	Ls ToolLs `json:"ls,omitempty"`
+	Ls          ToolLs          `json:"ls,omitempty"`
+	WebSearch   ToolWebSearch   `json:"web_search,omitempty" jsonschema:"description=Web search backend used by the web_search and agentic_fetch tools"`
+	Fetch       ToolFetch       `json:"fetch,omitempty" jsonschema:"description=HTTP response cache used by the fetch and agentic_fetch tools"`
+	Sourcegraph ToolSourcegraph `json:"sourcegraph,omitempty" jsonschema:"description=Sourcegraph instance searched by the sourcegraph tool"`
+	RepoMap     ToolRepoMap     `json:"repo_map,omitempty" jsonschema:"description=Repository map used by the repo_map tool and the system prompt"`
+	ToolSearch  ToolSearch      `json:"tool_search,omitempty" jsonschema:"description=Loading MCP tools on demand with the tool_search tool when there are many of them"`
 }
 
 type ToolLs struct {
@@ -360,10 +392,116 @@ func (t ToolLs) Limits() (depth, items int) {
 	return ptrValOr(t.MaxDepth, 0), ptrValOr(t.MaxItems, 0)
 }
 
+type ToolSearch struct {
+	Threshold *int `json:"threshold,omitempty" jsonschema:"description=Number of MCP tools above which they are loaded on demand with the tool_search tool, 0 always sends them all,default=40,example=100"`
+}
+
+// Limit returns the number of MCP tools above which they are loaded on
+// demand, zero meaning they never are.
+func (t ToolSearch) Limit() int {
+	return max(ptrValOr(t.Threshold, 40), 0)
+}
+
+type ToolFetch struct {
+	DisableCache bool `json:"disable_cache,omitempty" jsonschema:"description=Disable the on-disk cache of fetched pages,default=false"`
+	CacheTTL     *int `json:"cache_ttl,omitempty" jsonschema:"description=Seconds a fetched page is considered fresh when the server does not say otherwise,default=3600,example=600"`
+	CacheMaxSize *int `json:"cache_max_size,omitempty" jsonschema:"description=Maximum size of the fetch cache in megabytes,default=50,example=100"`
+}
+
+// CacheLimits returns the cache TTL and maximum size, in bytes.
+func (t ToolFetch) CacheLimits() (ttl time.Duration, maxSize int64) {
+	ttl = time.Duration(ptrValOr(t.CacheTTL, 3600)) * time.Second
+	maxSize = int64(ptrValOr(t.CacheMaxSize, 50)) * 1024 * 1024
+	return ttl, maxSize
+}
+
+type ToolSourcegraph struct {
+	URL         string   `json:"url,omitempty" jsonschema:"description=URL of the Sourcegraph instance,format=uri,default=https://sourcegraph.com,example=https://sourcegraph.example.com"`
+	AccessToken string   `json:"access_token,omitempty" jsonschema:"description=Access token for the Sourcegraph instance,example=$SRC_ACCESS_TOKEN"`
+	RepoFilters []string `json:"repo_filters,omitempty" jsonschema:"description=Repository patterns searched when a query has no repo: filter,example=^git.example.com/acme/"`
+	Timeout     *int     `json:"timeout,omitempty" jsonschema:"description=Default timeout for searches in seconds,default=30,example=60"`
+}
+
+type ToolRepoMap struct {
+	MaxTokens          *int `json:"max_tokens,omitempty" jsonschema:"description=Default token budget of the repo_map tool,default=4096,example=8192"`
+	SystemPrompt       bool `json:"system_prompt,omitempty" jsonschema:"description=Include a repository map in the system prompt,default=false"`
+	SystemPromptTokens *int `json:"system_prompt_tokens,omitempty" jsonschema:"description=Token budget of the repository map in the system prompt,default=1024,example=2048"`
+}
+
+// Limits returns the token budgets of the tool and of the system prompt
+// section.
+func (t ToolRepoMap) Limits() (tool, systemPrompt int) {
+	return ptrValOr(t.MaxTokens, 4096), ptrValOr(t.SystemPromptTokens, 1024)
+}
+
+type WebSearchProvider string
+
+const (
+	WebSearchDuckDuckGo WebSearchProvider = "duckduckgo"
+	WebSearchSearXNG    WebSearchProvider = "searxng"
+	WebSearchBrave      WebSearchProvider = "brave"
+	WebSearchTavily     WebSearchProvider = "tavily"
+	WebSearchJSON       WebSearchProvider = "json"
+)
+
+type ToolWebSearch struct {
+	Provider WebSearchProvider `json:"provider,omitempty" jsonschema:"description=Web search backend to use,enum=duckduckgo,enum=searxng,enum=brave,enum=tavily,enum=json,default=duckduckgo"`
+
+	DuckDuckGo *WebSearchBackend      `json:"duckduckgo,omitempty" jsonschema:"description=DuckDuckGo settings"`
+	SearXNG    *WebSearchBackend      `json:"searxng,omitempty" jsonschema:"description=SearXNG settings; url is required and must point to an instance with the JSON format enabled"`
+	Brave      *WebSearchBackend      `json:"brave,omitempty" jsonschema:"description=Brave Search API settings"`
+	Tavily     *WebSearchBackend      `json:"tavily,omitempty" jsonschema:"description=Tavily API settings"`
+	JSON       *WebSearchJSONEndpoint `json:"json,omitempty" jsonschema:"description=Generic JSON search endpoint settings"`
+}
+
+// Backend returns the settings for the selected provider, if any.
+func (t ToolWebSearch) Backend() *WebSearchBackend {
+	switch t.Provider {
+	case WebSearchSearXNG:
+		return t.SearXNG
+	case WebSearchBrave:
+		return t.Brave
+	case WebSearchTavily:
+		return t.Tavily
+	case WebSearchJSON:
+		if t.JSON != nil {
+			return &t.JSON.WebSearchBackend
+		}
+		return nil
+	default:
+		return t.DuckDuckGo
+	}
+}
+
+type WebSearchBackend struct {
+	URL        string            `json:"url,omitempty" jsonschema:"description=Endpoint of the search API,format=uri,example=https://searx.example.com"`
+	APIKey     string            `json:"api_key,omitempty" jsonschema:"description=API key for the search backend,example=$BRAVE_API_KEY"`
+	MaxResults int               `json:"max_results,omitempty" jsonschema:"description=Default number of results to return,default=10,minimum=1,maximum=20"`
+	SafeSearch string            `json:"safe_search,omitempty" jsonschema:"description=Safe search level,enum=off,enum=moderate,enum=strict,default=moderate"`
+	Headers    map[string]string `json:"headers,omitempty" jsonschema:"description=Additional HTTP headers to send with search requests"`
+}
+
+// WebSearchJSONEndpoint describes a generic JSON search API. The URL and body
+// may contain the {query}, {max_results}, {safe_search} and {api_key}
+// placeholders, and results are read from the response using gjson paths.
+type WebSearchJSONEndpoint struct {
+	WebSearchBackend
+
+	Method       string `json:"method,omitempty" jsonschema:"description=HTTP method to use,enum=GET,enum=POST,default=GET"`
+	Body         string `json:"body,omitempty" jsonschema:"description=Request body template for POST requests,example={\"q\":\"{query}\"}"`
+	ResultsPath  string `json:"results_path,omitempty" jsonschema:"description=gjson path to the array of results in the response,example=results,example=data.items"`
+	TitleField   string `json:"title_field,omitempty" jsonschema:"description=Field of a result holding its title,default=title"`
+	URLField     string `json:"url_field,omitempty" jsonschema:"description=Field of a result holding its URL,default=url"`
+	SnippetField string `json:"snippet_field,omitempty" jsonschema:"description=Field of a result holding its snippet,default=snippet"`
+}
+
 // Config holds the configuration for crush.
 type Config struct {
 	Schema string `json:"$schema,omitempty"`
 
+	// Config files this one builds on, merged before it.
+	Extends []Extend `json:"extends,omitempty" jsonschema:"description=Config files to build on which are merged in order before this one so its own settings win"`
+
 	// We currently only support large/small as values here.
 	Models map[SelectedModelType]SelectedModel `json:"models,omitempty" jsonschema:"description=Model configurations for different model types,example={\"large\":{\"model\":\"gpt-4o\",\"provider\":\"openai\"}}"`
 
@@ -383,6 +521,8 @@ type Config struct {
 
 	Tools Tools `json:"tools,omitempty" jsonschema:"description=Tool configurations"`
 
+	Profiles map[string]Profile `json:"profiles,omitempty" jsonschema:"description=Named sets of settings that overlay the rest of the configuration when selected with --profile or CRUSH_PROFILE"`
+
 	Agents map[string]Agent `json:"-"`
 
 	// Internal
@@ -391,6 +531,33 @@ type Config struct {
 	resolver       VariableResolver
 	dataConfigDir  string             `json:"-"`
 	knownProviders []catwalk.Provider `json:"-"`
+	profile        string             `json:"-"`
+	// loadedModels holds the models as the files select them, to tell the
+	// ones selected while running apart when reloading.
+	loadedModels map[SelectedModelType]SelectedModel
+}
+
+// Profile is a named set of settings that overlays the rest of the config
+// when selected. Objects are merged with the config, everything else,
+// including lists, replaces what the config sets.
+type Profile struct {
+	Models map[SelectedModelType]SelectedModel `json:"models,omitempty" jsonschema:"description=Model configurations for different model types"`
+
+	Providers map[string]ProviderConfig `json:"providers,omitempty" jsonschema:"description=AI provider configurations"`
+
+	MCP MCPs `json:"mcp,omitempty" jsonschema:"description=Model Context Protocol server configurations"`
+
+	LSP LSPs `json:"lsp,omitempty" jsonschema:"description=Language Server Protocol configurations"`
+
+	Permissions *Permissions `json:"permissions,omitempty" jsonschema:"description=Permission settings for tool usage"`
+
+	Options *Options `json:"options,omitempty" jsonschema:"description=General application options"`
+}
+
+// Profile returns the name of the profile the config was loaded with, if
+// any.
+func (c *Config) Profile() string {
+	return c.profile
 }
 
 func (c *Config) WorkingDir() string {
@@ -493,7 +660,17 @@ func (c *Config) HasConfigField(key string) bool {
 }
 
 func (c *Config) SetConfigField(key string, value any) error {
-	data, err := os.ReadFile(c.dataConfigDir)
+	return SetField(c.dataConfigDir, key, value)
+}
+
+func (c *Config) RemoveConfigField(key string) error {
+	return RemoveField(c.dataConfigDir, key)
+}
+
+// SetField sets key, an sjson path, to value in the config file at path,
+// creating the file if needed.
+func SetField(path, key string, value any) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			data = []byte("{}")
@@ -506,17 +683,12 @@ func (c *Config) SetConfigField(key string, value any) error {
 	if err != nil {
 		return fmt.Errorf("failed to set config field %s: %w", key, err)
 	}
-	if err := os.MkdirAll(filepath.Dir(c.dataConfigDir), 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory %q: %w", c.dataConfigDir, err)
-	}
-	if err := os.WriteFile(c.dataConfigDir, []byte(newValue), 0o600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
-	return nil
+	return writeConfigFile(path, newValue)
 }
 
-func (c *Config) RemoveConfigField(key string) error {
-	data, err := os.ReadFile(c.dataConfigDir)
+// RemoveField removes key, an sjson path, from the config file at path.
+func RemoveField(path, key string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -525,15 +697,35 @@ func (c *Config) RemoveConfigField(key string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete config field %s: %w", key, err)
 	}
-	if err := os.MkdirAll(filepath.Dir(c.dataConfigDir), 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory %q: %w", c.dataConfigDir, err)
+	return writeConfigFile(path, newValue)
+}
+
+func writeConfigFile(path, data string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory %q: %w", path, err)
 	}
-	if err := os.WriteFile(c.dataConfigDir, []byte(newValue), 0o600); err != nil {
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
+	written.Set(filepath.Clean(path), data)
 	return nil
 }
 
+// written holds what Crush last wrote to each config file, for the watcher
+// to ignore its own writes.
+var written = csync.NewMap[string, string]()
+
+// wroteLast reports whether the config file at path holds what Crush last
+// wrote to it.
+func wroteLast(path string) bool {
+	last, ok := written.Get(path)
+	if !ok {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && string(data) == last
+}
+
 // RefreshOAuthToken refreshes the OAuth token for the given provider.
 func (c *Config) RefreshOAuthToken(ctx context.Context, providerID string) error {
 	providerConfig, exists := c.Providers.Get(providerID)
@@ -580,6 +772,35 @@ func (c *Config) RefreshOAuthToken(ctx context.Context, providerID string) error
 	return nil
 }
 
+// SetMCPOAuth persists the OAuth client and token of the MCP server name to
+// the data config.
+func (c *Config) SetMCPOAuth(name string, auth *MCPOAuth) error {
+	key := "mcp." + strings.ReplaceAll(name, ".", `\.`) + ".oauth"
+	if err := c.SetConfigField(key, auth); err != nil {
+		return fmt.Errorf("failed to save oauth credentials of mcp %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetMCPDisabled disables or enables the MCP server name and saves it in the
+// data config.
+func (c *Config) SetMCPDisabled(name string, disabled bool) error {
+	m, ok := c.MCP[name]
+	if !ok {
+		return fmt.Errorf("mcp %q not configured", name)
+	}
+	key := "mcp." + strings.ReplaceAll(name, ".", `\.`) + ".disabled"
+	if err := c.SetConfigField(key, disabled); err != nil {
+		return fmt.Errorf("failed to save mcp %s: %w", name, err)
+	}
+	m.Disabled = disabled
+	update(func(next *Config) {
+		next.MCP = maps.Clone(next.MCP)
+		next.MCP[name] = m
+	})
+	return nil
+}
+
 func (c *Config) SetProviderAPIKey(providerID string, apiKey any) error {
 	var providerConfig ProviderConfig
 	var exists bool
@@ -696,14 +917,22 @@ func allToolNames() []string {
 		"download",
 		"edit",
 		"multiedit",
+		"notebook_edit",
+		"move",
+		"copy",
+		"delete",
+		"memory",
 		"lsp_diagnostics",
 		"lsp_references",
 		"lsp_restart",
+		"read_mcp_resource",
+		"tool_search",
 		"fetch",
 		"agentic_fetch",
 		"glob",
 		"grep",
 		"ls",
+		"repo_map",
 		"sourcegraph",
 		"todos",
 		"view",
@@ -720,7 +949,7 @@ func resolveAllowedTools(allTools []string, disabledTools []string) []string {
 }
 
 func resolveReadOnlyTools(tools []string) []string {
-	readOnlyTools := []string{"glob", "grep", "ls", "sourcegraph", "view"}
+	readOnlyTools := []string{"glob", "grep", "ls", "repo_map", "sourcegraph", "view"}
 	// filter to only include tools that are in allowedtools (include mode)
 	return filterSlice(tools, readOnlyTools, true)
 }
@@ -742,21 +971,23 @@ func (c *Config) SetupAgents() {
 
 	agents := map[string]Agent{
 		AgentCoder: {
-			ID:           AgentCoder,
-			Name:         "Coder",
-			Description:  "An agent that helps with executing coding tasks.",
-			Model:        SelectedModelTypeLarge,
-			ContextPaths: c.Options.ContextPaths,
-			AllowedTools: allowedTools,
+			ID:            AgentCoder,
+			Name:          "Coder",
+			Description:   "An agent that helps with executing coding tasks.",
+			Model:         SelectedModelTypeLarge,
+			ContextPaths:  c.Options.ContextPaths,
+			AllowedTools:  allowedTools,
+			AllowedSkills: c.Options.AgentSkills[AgentCoder],
 		},
 
 		AgentTask: {
-			ID:           AgentCoder,
-			Name:         "Task",
-			Description:  "An agent that helps with searching for context and finding implementation details.",
-			Model:        SelectedModelTypeLarge,
-			ContextPaths: c.Options.ContextPaths,
-			AllowedTools: resolveReadOnlyTools(allowedTools),
+			ID:            AgentCoder,
+			Name:          "Task",
+			Description:   "An agent that helps with searching for context and finding implementation details.",
+			Model:         SelectedModelTypeLarge,
+			ContextPaths:  c.Options.ContextPaths,
+			AllowedTools:  resolveReadOnlyTools(allowedTools),
+			AllowedSkills: c.Options.AgentSkills[AgentTask],
 			// NO MCPs or LSPs by default
 			AllowedMCP: map[string][]string{},
 		},
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"charm.land/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/csync"
+	"golang.org/x/sync/errgroup"
+)
+
+// discoverClient lists the models of providers. It's kept short since
+// providers are discovered at startup when nothing was cached.
+var discoverClient = &http.Client{Timeout: 3 * time.Second}
+
+// maxDiscoverSize is the largest response read when discovering models.
+const maxDiscoverSize = 8 << 20
+
+// The defaults of discovered models, for what the provider doesn't tell.
+const (
+	discoveredContextWindow = 32_768
+	discoveredMaxTokens     = 4_096
+)
+
+// discoveredMu guards the cache of discovered models.
+var discoveredMu sync.Mutex
+
+func discoveredCache() cache[map[string][]catwalk.Model] {
+	return newCache[map[string][]catwalk.Model](cachePathFor("discovered_models"))
+}
+
+// DiscoverModels lists the models of the providers with discover_models set
+// again, adding the new ones to the current config. It returns how many
+// models were discovered, and whether that changed the providers.
+func (c *Config) DiscoverModels(ctx context.Context) (int, bool, error) {
+	var count int
+	var changed bool
+	var errs []error
+	for id, p := range c.Providers.Seq2() {
+		if !p.DiscoverModels || p.Disable {
+			continue
+		}
+		apiKey, _ := c.resolver.ResolveValue(p.APIKey)
+		baseURL, err := c.resolver.ResolveValue(p.BaseURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to discover models of %s: %w", id, err))
+			continue
+		}
+		models, err := fetchModels(ctx, p, apiKey, baseURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to discover models of %s: %w", id, err))
+			continue
+		}
+		storeDiscoveredModels(id, models)
+		count += len(mergeDiscoveredModels(p, models).DiscoveredModels)
+		update(func(next *Config) {
+			current, ok := next.Providers.Get(id)
+			if !ok {
+				return
+			}
+			merged := mergeDiscoveredModels(current, models)
+			if reflect.DeepEqual(merged, current) {
+				return
+			}
+			next.Providers = csync.NewMapFrom(next.Providers.Copy())
+			next.Providers.Set(id, merged)
+			changed = true
+		})
+	}
+	return count, changed, errors.Join(errs...)
+}
+
+// DiscoversModels reports whether any enabled provider has discover_models
+// set.
+func (c *Config) DiscoversModels() bool {
+	for p := range c.Providers.Seq() {
+		if p.DiscoverModels && !p.Disable {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedOrDiscoveredModels adds the models discovered last to the configured
+// ones. The provider is only asked when nothing was cached for it yet, so
+// loading the config doesn't wait on the network; see
+// [Config.DiscoverModels] for listing them again.
+func cachedOrDiscoveredModels(ctx context.Context, p ProviderConfig, apiKey, baseURL string) ProviderConfig {
+	if models, ok := cachedDiscoveredModels(p.ID); ok {
+		return mergeDiscoveredModels(p, models)
+	}
+	models, err := fetchModels(ctx, p, apiKey, baseURL)
+	if err != nil {
+		slog.Warn("Failed to discover models", "provider", p.ID, "error", err)
+		return p
+	}
+	storeDiscoveredModels(p.ID, models)
+	return mergeDiscoveredModels(p, models)
+}
+
+// mergeDiscoveredModels adds models to the ones of p, replacing the ones
+// discovered before. Configured models win over discovered ones.
+func mergeDiscoveredModels(p ProviderConfig, models []catwalk.Model) ProviderConfig {
+	configured := slices.DeleteFunc(slices.Clone(p.Models), func(m catwalk.Model) bool {
+		return slices.Contains(p.DiscoveredModels, m.ID)
+	})
+	p.Models = configured
+	p.DiscoveredModels = nil
+	for _, m := range models {
+		if slices.ContainsFunc(configured, func(c catwalk.Model) bool { return c.ID == m.ID }) {
+			continue
+		}
+		p.Models = append(p.Models, m)
+		p.DiscoveredModels = append(p.DiscoveredModels, m.ID)
+	}
+	return p
+}
+
+func cachedDiscoveredModels(id string) ([]catwalk.Model, bool) {
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	cached, _, err := discoveredCache().Get()
+	if err != nil {
+		return nil, false
+	}
+	models, ok := cached[id]
+	return models, ok
+}
+
+func storeDiscoveredModels(id string, models []catwalk.Model) {
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	cache := discoveredCache()
+	cached, _, err := cache.Get()
+	if err != nil || cached == nil {
+		cached = make(map[string][]catwalk.Model)
+	}
+	cached[id] = models
+	if err := cache.Store(cached); err != nil {
+		slog.Warn("Failed to cache discovered models", "provider", id, "error", err)
+	}
+}
+
+// fetchModels lists the models of the provider at baseURL, using the Ollama
+// API for Ollama since it tells the context window of each model.
+func fetchModels(ctx context.Context, p ProviderConfig, apiKey, baseURL string) ([]catwalk.Model, error) {
+	if p.Type != catwalk.TypeOpenAICompat && p.Type != catwalk.TypeOpenAI {
+		return nil, fmt.Errorf("models can only be discovered for openai-compat providers, not %s", p.Type)
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if isOllama(p, baseURL) {
+		models, err := fetchOllamaModels(ctx, p, apiKey, strings.TrimSuffix(baseURL, "/v1"))
+		if err == nil {
+			return models, nil
+		}
+		slog.Debug("Failed to list Ollama models, trying the OpenAI API", "provider", p.ID, "error", err)
+	}
+	return fetchOpenAIModels(ctx, p, apiKey, baseURL)
+}
+
+// ollamaPort is the port Ollama listens on by default.
+const ollamaPort = "11434"
+
+// isOllama reports whether the provider looks like Ollama, by its id, name
+// or port.
+func isOllama(p ProviderConfig, baseURL string) bool {
+	if strings.Contains(strings.ToLower(p.ID), "ollama") || strings.Contains(strings.ToLower(p.Name), "ollama") {
+		return true
+	}
+	u, err := url.Parse(baseURL)
+	return err == nil && u.Port() == ollamaPort
+}
+
+func fetchOpenAIModels(ctx context.Context, p ProviderConfig, apiKey, baseURL string) ([]catwalk.Model, error) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+			// Servers tell the context window under different names: vLLM,
+			// OpenRouter and others.
+			MaxModelLen   int64 `json:"max_model_len"`
+			ContextLength int64 `json:"context_length"`
+			ContextWindow int64 `json:"context_window"`
+		} `json:"data"`
+	}
+	if err := requestJSON(ctx, p, apiKey, http.MethodGet, baseURL+"/models", nil, &list); err != nil {
+		return nil, err
+	}
+	models := make([]catwalk.Model, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID == "" {
+			continue
+		}
+		models = append(models, discoveredModel(m.ID, max(m.MaxModelLen, m.ContextLength, m.ContextWindow)))
+	}
+	return models, nil
+}
+
+func fetchOllamaModels(ctx context.Context, p ProviderConfig, apiKey, baseURL string) ([]catwalk.Model, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := requestJSON(ctx, p, apiKey, http.MethodGet, baseURL+"/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	if len(tags.Models) == 0 {
+		return nil, errors.New("no Ollama models")
+	}
+
+	models := make([]catwalk.Model, len(tags.Models))
+	var g errgroup.Group
+	g.SetLimit(4)
+	for i, tag := range tags.Models {
+		g.Go(func() error {
+			var show struct {
+				ModelInfo    map[string]any `json:"model_info"`
+				Capabilities []string       `json:"capabilities"`
+			}
+			body := map[string]string{"model": tag.Name}
+			if err := requestJSON(ctx, p, apiKey, http.MethodPost, baseURL+"/api/show", body, &show); err != nil {
+				slog.Debug("Failed to get Ollama model details", "model", tag.Name, "error", err)
+			}
+			var contextWindow int64
+			for k, v := range show.ModelInfo {
+				if n, ok := v.(float64); ok && strings.HasSuffix(k, ".context_length") {
+					contextWindow = int64(n)
+				}
+			}
+			model := discoveredModel(tag.Name, contextWindow)
+			model.SupportsImages = slices.Contains(show.Capabilities, "vision")
+			model.CanReason = slices.Contains(show.Capabilities, "thinking")
+			models[i] = model
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return models, nil
+}
+
+// discoveredModel returns the model id with the defaults for what the
+// provider doesn't tell.
+func discoveredModel(id string, contextWindow int64) catwalk.Model {
+	if contextWindow <= 0 {
+		contextWindow = discoveredContextWindow
+	}
+	return catwalk.Model{
+		ID:               id,
+		Name:             id,
+		ContextWindow:    contextWindow,
+		DefaultMaxTokens: min(discoveredMaxTokens, contextWindow/2),
+	}
+}
+
+func requestJSON(ctx context.Context, p ProviderConfig, apiKey, method, url string, body, v any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	for k, v := range p.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := discoverClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDiscoverSize)).Decode(v)
+}
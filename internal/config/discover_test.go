@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"charm.land/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedOrDiscoveredModels(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var paths []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/v1/models" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"data": [{"id": "qwen"}, {"id": "llama", "max_model_len": 8192}]}`)
+	}))
+	defer server.Close()
+
+	p := ProviderConfig{
+		ID:             "local",
+		Type:           catwalk.TypeOpenAICompat,
+		DiscoverModels: true,
+		Models:         []catwalk.Model{{ID: "qwen", Name: "Qwen", ContextWindow: 131_072}},
+	}
+	p = cachedOrDiscoveredModels(t.Context(), p, "key", server.URL+"/v1")
+	require.Equal(t, []string{"/v1/models"}, paths, "only Ollama is asked for its tags")
+	require.Equal(t, "Bearer key", auth)
+	require.Equal(t, []string{"llama"}, p.DiscoveredModels)
+	require.Len(t, p.Models, 2)
+	require.Equal(t, "Qwen", p.Models[0].Name, "configured models win")
+	require.Equal(t, catwalk.Model{ID: "llama", Name: "llama", ContextWindow: 8192, DefaultMaxTokens: 4096}, p.Models[1])
+
+	// Once cached, the provider isn't asked again.
+	p = cachedOrDiscoveredModels(t.Context(), p, "key", server.URL+"/v1")
+	require.Len(t, paths, 1)
+	require.Equal(t, []string{"llama"}, p.DiscoveredModels)
+	require.Len(t, p.Models, 2)
+}
+
+func TestConfigDiscoverModels(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": [{"id": "qwen"}, {"id": "llama"}]}`)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		Providers: csync.NewMapFrom(map[string]ProviderConfig{
+			"local": {ID: "local", Type: catwalk.TypeOpenAICompat, BaseURL: server.URL, DiscoverModels: true},
+		}),
+	}
+	cfg.resolver = NewEnvironmentVariableResolver(env.NewFromMap(nil))
+	instance.Store(cfg)
+	t.Cleanup(func() { instance.Store(nil) })
+
+	require.True(t, cfg.DiscoversModels())
+	n, changed, err := cfg.DiscoverModels(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.True(t, changed)
+
+	p, _ := Get().Providers.Get("local")
+	require.Equal(t, []string{"qwen", "llama"}, p.DiscoveredModels)
+	p, _ = cfg.Providers.Get("local")
+	require.Empty(t, p.Models, "the loaded config is left as is")
+	cached, ok := cachedDiscoveredModels("local")
+	require.True(t, ok)
+	require.Len(t, cached, 2)
+
+	_, changed, err = Get().DiscoverModels(t.Context())
+	require.NoError(t, err)
+	require.False(t, changed, "the same models don't change the providers")
+}
+
+func TestIsOllama(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isOllama(ProviderConfig{ID: "ollama"}, "http://gpu-box:8080/v1"))
+	require.True(t, isOllama(ProviderConfig{ID: "local", Name: "Ollama"}, "http://gpu-box:8080/v1"))
+	require.True(t, isOllama(ProviderConfig{ID: "local"}, "http://localhost:11434/v1"))
+	require.False(t, isOllama(ProviderConfig{ID: "vllm"}, "http://localhost:8000/v1"))
+}
+
+func TestFetchOllamaModels(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var shown []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models": [{"name": "gemma3:4b"}, {"name": "qwen3:8b"}]}`)
+		case "/api/show":
+			var body struct {
+				Model string `json:"model"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			shown = append(shown, body.Model)
+			mu.Unlock()
+			if body.Model == "gemma3:4b" {
+				fmt.Fprint(w, `{"model_info": {"gemma3.context_length": 131072}, "capabilities": ["completion", "vision"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"capabilities": ["completion", "thinking"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	models, err := fetchModels(t.Context(), ProviderConfig{ID: "ollama", Type: catwalk.TypeOpenAICompat}, "", server.URL+"/v1/")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"gemma3:4b", "qwen3:8b"}, shown)
+	require.Equal(t, []catwalk.Model{
+		{ID: "gemma3:4b", Name: "gemma3:4b", ContextWindow: 131_072, DefaultMaxTokens: 4096, SupportsImages: true},
+		{ID: "qwen3:8b", Name: "qwen3:8b", ContextWindow: 32_768, DefaultMaxTokens: 4096, CanReason: true},
+	}, models)
+
+	_, err = fetchModels(t.Context(), ProviderConfig{ID: "ollama", Type: catwalk.TypeAnthropic}, "", server.URL)
+	require.Error(t, err)
+}
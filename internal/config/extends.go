@@ -0,0 +1,246 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/invopop/jsonschema"
+)
+
+// extendsClient downloads remote config files.
+var extendsClient = &http.Client{Timeout: 10 * time.Second}
+
+const (
+	// maxExtendSize is the largest remote config file that's downloaded.
+	maxExtendSize = 1 << 20
+	// extendMaxAge is how long a cached remote config file is used before
+	// it's downloaded again, so reloading the config doesn't download it
+	// each time.
+	extendMaxAge = time.Hour
+)
+
+// Extend is a config file another config file builds on.
+type Extend struct {
+	Source string `json:"source" jsonschema:"required,description=Path of the config file to extend relative to this one or an https URL,example=../team/crush.json,example=https://example.com/crush.json"`
+	SHA256 string `json:"sha256,omitempty" jsonschema:"description=SHA-256 checksum the config file must match,pattern=^[a-fA-F0-9]{64}$"`
+}
+
+// UnmarshalJSON accepts the source alone as a string too.
+func (e *Extend) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err == nil {
+		*e = Extend{Source: source}
+		return nil
+	}
+	type extend Extend
+	return json.Unmarshal(data, (*extend)(e))
+}
+
+// JSONSchemaExtend allows the source alone as a string in the schema.
+func (Extend) JSONSchemaExtend(schema *jsonschema.Schema) {
+	object := *schema
+	*schema = jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{Type: "string", Description: "Path of the config file to extend relative to this one or an https URL"},
+			&object,
+		},
+	}
+}
+
+// configSource is a config file to merge.
+type configSource struct {
+	// Name is the path or URL of the file.
+	Name string
+	Data []byte
+}
+
+// readConfigs reads the config files at paths, in order of priority, each
+// preceded by the files it extends so its own settings win.
+func readConfigs(paths []string) ([]configSource, error) {
+	var sources []configSource
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		extended, err := readExtends(path, data, []string{path})
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, extended...)
+		sources = append(sources, configSource{Name: path, Data: data})
+	}
+	return sources, nil
+}
+
+// readExtends reads the files the config file name extends, and the ones
+// they extend in turn. seen holds the files being extended, to catch cycles.
+func readExtends(name string, data []byte, seen []string) ([]configSource, error) {
+	var cfg struct {
+		Extends []Extend `json:"extends"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	var sources []configSource
+	for _, e := range cfg.Extends {
+		source, err := resolveExtend(name, e.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend %s from %s: %w", e.Source, name, err)
+		}
+		if slices.Contains(seen, source) {
+			return nil, fmt.Errorf("config file %s extends itself through %s", source, name)
+		}
+		data, err := readExtend(source, e.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend %s from %s: %w", e.Source, name, err)
+		}
+		extended, err := readExtends(source, data, append(seen, source))
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, extended...)
+		sources = append(sources, configSource{Name: source, Data: data})
+	}
+	return sources, nil
+}
+
+// resolveExtend returns the path or URL of source, extended from the config
+// file name.
+func resolveExtend(name, source string) (string, error) {
+	switch {
+	case source == "":
+		return "", errors.New("no source")
+	case strings.HasPrefix(source, "http://"):
+		return "", errors.New("only https URLs can be extended")
+	case strings.HasPrefix(source, "https://"):
+		return source, nil
+	case isRemote(name):
+		base, err := url.Parse(name)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(source)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	source = home.Long(source)
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(filepath.Dir(name), source)
+	}
+	return filepath.Clean(source), nil
+}
+
+// readExtend reads the extended config file at source, checking it matches
+// the checksum when there's one.
+func readExtend(source, checksum string) ([]byte, error) {
+	if isRemote(source) {
+		return fetchExtend(source, checksum)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(data, checksum); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// fetchExtend downloads the config file at rawURL and caches it, falling back
+// to the cached copy when it can't be downloaded. A pinned file that's
+// already cached isn't downloaded again, and other ones only once the cached
+// copy is older than [extendMaxAge].
+func fetchExtend(rawURL, checksum string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(rawURL))
+	cachePath := cachePathFor(filepath.Join("extends", hex.EncodeToString(sum[:8])))
+	cached, cacheErr := os.ReadFile(cachePath)
+	if cacheErr == nil {
+		cacheErr = verifyChecksum(cached, checksum)
+	}
+	if cacheErr == nil && (checksum != "" || isFresh(cachePath)) {
+		return cached, nil
+	}
+
+	data, err := download(rawURL)
+	if err == nil {
+		err = verifyChecksum(data, checksum)
+	}
+	if err != nil {
+		if cacheErr == nil {
+			slog.Warn("Failed to fetch extended config, using the cached one", "url", rawURL, "error", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		slog.Warn("Failed to create extended config cache directory", "error", err)
+	} else if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		slog.Warn("Failed to cache extended config", "url", rawURL, "error", err)
+	}
+	return data, nil
+}
+
+func download(rawURL string) ([]byte, error) {
+	resp, err := extendsClient.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxExtendSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxExtendSize {
+		return nil, fmt.Errorf("config file is larger than %d bytes", maxExtendSize)
+	}
+	return data, nil
+}
+
+// isFresh reports whether the cached file at path was written less than
+// [extendMaxAge] ago.
+func isFresh(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < extendMaxAge
+}
+
+func verifyChecksum(data []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, checksum) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", checksum, got)
+	}
+	return nil
+}
+
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "https://")
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigsExtends(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	team := filepath.Join(dir, "team", "crush.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(team), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team", "base.json"), []byte(`{"options": {"debug": true, "debug_lsp": true}}`), 0o644))
+	require.NoError(t, os.WriteFile(team, []byte(`{"extends": ["base.json"], "options": {"debug_lsp": false}, "mcp": {"jira": {"command": "jira"}}}`), 0o644))
+	project := filepath.Join(dir, "crush.json")
+	require.NoError(t, os.WriteFile(project, []byte(`{"extends": [{"source": "team/crush.json"}], "options": {"debug": false}}`), 0o644))
+
+	sources, err := readConfigs([]string{project})
+	require.NoError(t, err)
+	var names []string
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	require.Equal(t, []string{filepath.Join(dir, "team", "base.json"), team, project}, names)
+
+	cfg, err := loadFromConfigPaths([]string{project}, "")
+	require.NoError(t, err)
+	require.False(t, cfg.Options.Debug, "local keys win")
+	require.False(t, cfg.Options.DebugLSP)
+	require.Contains(t, cfg.MCP, "jira")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team", "base.json"), []byte(`{"extends": ["../crush.json"]}`), 0o644))
+	_, err = readConfigs([]string{project})
+	require.ErrorContains(t, err, "extends itself")
+}
+
+func TestFetchExtend(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	body := `{"mcp": {"jira": {"command": "jira"}}}`
+	sum := sha256.Sum256([]byte(body))
+	checksum := hex.EncodeToString(sum[:])
+
+	up := true
+	var requests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/large.json" {
+			fmt.Fprint(w, strings.Repeat(" ", maxExtendSize+1))
+			return
+		}
+		if !up {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	client := extendsClient
+	extendsClient = server.Client()
+	t.Cleanup(func() { extendsClient = client })
+
+	url := server.URL + "/crush.json"
+	data, err := fetchExtend(url, "")
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+
+	// Recent copies aren't downloaded again.
+	data, err = fetchExtend(url, "")
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+	require.Equal(t, 1, requests)
+
+	// The cached copy is used when the server is down, and pinned copies
+	// aren't downloaded again.
+	sum = sha256.Sum256([]byte(url))
+	old := time.Now().Add(-2 * extendMaxAge)
+	require.NoError(t, os.Chtimes(cachePathFor(filepath.Join("extends", hex.EncodeToString(sum[:8]))), old, old))
+	up = false
+	data, err = fetchExtend(url, "")
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+	require.Equal(t, 2, requests)
+	data, err = fetchExtend(url, checksum)
+	require.NoError(t, err)
+	require.Equal(t, body, string(data))
+	require.Equal(t, 2, requests)
+
+	_, err = fetchExtend(url, "0000000000000000000000000000000000000000000000000000000000000000")
+	require.ErrorContains(t, err, "unexpected status")
+
+	up = true
+	_, err = fetchExtend(url, "0000000000000000000000000000000000000000000000000000000000000000")
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = fetchExtend(server.URL+"/large.json", "")
+	require.ErrorContains(t, err, "larger than")
+
+	_, err = resolveExtend("/tmp/crush.json", "http://example.com/crush.json")
+	require.ErrorContains(t, err, "only https")
+}
@@ -22,8 +22,8 @@ type ProjectInitFlag struct {
 // TODO: we need to remove the global config instance keeping it now just until everything is migrated
 var instance atomic.Pointer[Config]
 
-func Init(workingDir, dataDir string, debug bool) (*Config, error) {
-	cfg, err := Load(workingDir, dataDir, debug)
+func Init(workingDir, dataDir, profile string, debug bool) (*Config, error) {
+	cfg, err := Load(workingDir, dataDir, profile, debug)
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +36,23 @@ func Get() *Config {
 	return cfg
 }
 
+// update makes a copy of the current config changed by fn the current one.
+// The config is swapped atomically rather than changed in place since other
+// goroutines may be reading it.
+func update(fn func(*Config)) {
+	for {
+		current := instance.Load()
+		if current == nil {
+			return
+		}
+		next := *current
+		fn(&next)
+		if instance.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
+
 func ProjectNeedsInitialization() (bool, error) {
 	cfg := Get()
 	if cfg == nil {
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// OriginDefault is the origin of the values Crush sets itself.
+const OriginDefault = "default"
+
+// envOverrides are the environment variables that override options, keyed
+// by the option they override.
+var envOverrides = map[string]string{
+	"options.disable_provider_auto_update": "CRUSH_DISABLE_PROVIDER_AUTO_UPDATE",
+	"options.disable_default_providers":    "CRUSH_DISABLE_DEFAULT_PROVIDERS",
+}
+
+// Setting is a value of the merged config and where it comes from.
+type Setting struct {
+	// Key is the path of the value, as used by get and set.
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+	// Origin is the config file that sets the value, the environment
+	// variable that overrides it, or [OriginDefault].
+	Origin string `json:"origin"`
+}
+
+// redacted replaces the secrets of the config when it's inspected.
+const redacted = "********"
+
+var (
+	// secretKeys are the names of the values holding secrets.
+	secretKeys = []string{"api_key", "access_token", "refresh_token", "client_secret"}
+	// secretMaps are the names of the objects whose values may all be
+	// secrets.
+	secretMaps = []string{"headers", "extra_headers", "env"}
+)
+
+// Inspect merges the config files of workingDir and applies the defaults,
+// without configuring the providers. It returns the merged config as JSON
+// and each of its values along with where it comes from, overlaid with the
+// profile when it's not empty. Secrets, like API keys, tokens and headers,
+// are redacted unless showSecrets is set.
+func Inspect(workingDir, dataDir, profile string, showSecrets bool) ([]byte, []Setting, error) {
+	paths := lookupConfigs(workingDir)
+
+	sources, err := readConfigs(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	origins := make(map[string]string)
+	var configs [][]byte
+	for _, source := range sources {
+		var v any
+		if err := json.Unmarshal(source.Data, &v); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file %s: %w", source.Name, err)
+		}
+		walkValues("", v, func(key string, value any) {
+			// Arrays of several files are concatenated.
+			if _, ok := value.([]any); ok && origins[key] != "" {
+				origins[key] += ", " + source.Name
+				return
+			}
+			origins[key] = source.Name
+		})
+		configs = append(configs, source.Data)
+	}
+	if profile != "" {
+		// Settings of the profile replace the ones of every file.
+		prefix := joinKey("profiles", profile) + "."
+		for key, path := range origins {
+			if rest, ok := strings.CutPrefix(key, prefix); ok {
+				origins[rest] = fmt.Sprintf("%s (profile %s)", path, profile)
+			}
+		}
+	}
+
+	cfg, err := loadFromBytes(configs, profile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config from paths %v: %w", paths, err)
+	}
+	cfg.setDefaults(workingDir, dataDir)
+	merged, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Values set to false, zero or empty are left out when marshaling the
+	// config, add back the ones the files set.
+	if len(configs) > 0 {
+		data, err := mergeConfigs(configs, profile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to merge config files: %w", err)
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		walkValues("", v, func(key string, value any) {
+			if !gjson.GetBytes(merged, key).Exists() {
+				merged, _ = sjson.SetBytes(merged, key, value)
+			}
+		})
+	}
+
+	var v any
+	if err := json.Unmarshal(merged, &v); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if !showSecrets {
+		walkValues("", v, func(key string, value any) {
+			if isSecret(key, value) {
+				merged, _ = sjson.SetBytes(merged, key, redacted)
+			}
+		})
+		if err := json.Unmarshal(merged, &v); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+	var settings []Setting
+	walkValues("", v, func(key string, value any) {
+		raw, _ := json.Marshal(value)
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  raw,
+			Origin: origin(key, origins),
+		})
+	})
+	slices.SortFunc(settings, func(a, b Setting) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, merged, "", "  "); err != nil {
+		return nil, nil, fmt.Errorf("failed to format config: %w", err)
+	}
+	return out.Bytes(), settings, nil
+}
+
+func origin(key string, origins map[string]string) string {
+	if env, ok := envOverrides[key]; ok {
+		if _, ok := os.LookupEnv(env); ok {
+			return "$" + env
+		}
+	}
+	if path, ok := origins[key]; ok {
+		return path
+	}
+	return OriginDefault
+}
+
+// isSecret reports whether value, at key, holds a secret. References to
+// environment variables and commands, like $OPENAI_API_KEY, aren't secrets.
+func isSecret(key string, value any) bool {
+	str, ok := value.(string)
+	if !ok || str == "" || strings.HasPrefix(str, "$") {
+		return false
+	}
+	parts := splitKey(key)
+	if slices.Contains(secretKeys, parts[len(parts)-1]) {
+		return true
+	}
+	return len(parts) > 1 && slices.Contains(secretMaps, parts[len(parts)-2])
+}
+
+// splitKey splits the sjson path key into its unescaped parts.
+func splitKey(key string) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key):
+			i++
+			part.WriteByte(key[i])
+		case key[i] == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(key[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// walkValues calls fn with the key of each value in v that isn't an
+// object. Arrays are values too.
+func walkValues(prefix string, v any, fn func(key string, value any)) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		if prefix != "" {
+			fn(prefix, v)
+		}
+		return
+	}
+	for k, child := range obj {
+		walkValues(joinKey(prefix, k), child, fn)
+	}
+}
+
+// joinKey appends k to the sjson path prefix.
+func joinKey(prefix, k string) string {
+	k = strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`).Replace(k)
+	if prefix == "" {
+		return k
+	}
+	return prefix + "." + k
+}
+
+// Problem is an issue found in a config file.
+type Problem struct {
+	Path string `json:"path"`
+	// Key is the path of the value with the issue, empty when it's about the
+	// whole file.
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Key, p.Message)
+}
+
+// Validate checks the config files of workingDir against the config schema,
+// reporting invalid JSON, values of the wrong type and unknown keys.
+func Validate(workingDir string) ([]Problem, error) {
+	schema := new(jsonschema.Reflector).Reflect(&Config{})
+
+	var problems []Problem
+	for _, path := range lookupConfigs(workingDir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			problems = append(problems, Problem{Path: path, Message: err.Error()})
+			continue
+		}
+		if err := json.Unmarshal(data, &Config{}); err != nil {
+			problems = append(problems, Problem{Path: path, Message: err.Error()})
+		}
+		if obj, ok := v.(map[string]any); ok {
+			// Saved in the data config, but left out of the schema.
+			delete(obj, "recent_models")
+		}
+		for _, key := range unknownKeys(schema, schema, "", v) {
+			problems = append(problems, Problem{Path: path, Key: key, Message: "unknown key"})
+		}
+	}
+	return problems, nil
+}
+
+// unknownKeys returns the keys of v that s doesn't allow.
+func unknownKeys(root, s *jsonschema.Schema, prefix string, v any) []string {
+	s = resolveRef(root, s)
+	if s == nil {
+		return nil
+	}
+
+	var keys []string
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			key := joinKey(prefix, k)
+			if s.Properties != nil {
+				if prop, ok := s.Properties.Get(k); ok {
+					keys = append(keys, unknownKeys(root, prop, key, child)...)
+					continue
+				}
+			}
+			switch {
+			case s.AdditionalProperties == jsonschema.FalseSchema:
+				keys = append(keys, key)
+			case s.AdditionalProperties != nil:
+				keys = append(keys, unknownKeys(root, s.AdditionalProperties, key, child)...)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, child := range v {
+				keys = append(keys, unknownKeys(root, s.Items, fmt.Sprintf("%s.%d", prefix, i), child)...)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resolveRef returns the definition s refers to, if any.
+func resolveRef(root, s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+	def, ok := root.Definitions[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	if !ok {
+		return nil
+	}
+	return def
+}
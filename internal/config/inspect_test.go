@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("CRUSH_DISABLE_DEFAULT_PROVIDERS", "true")
+	workingDir := t.TempDir()
+
+	global := GlobalConfig()
+	require.NoError(t, os.MkdirAll(filepath.Dir(global), 0o755))
+	require.NoError(t, os.WriteFile(global, []byte(`{"options": {"debug": true, "context_paths": ["a.md"]}}`), 0o644))
+	team := filepath.Join(workingDir, "team.json")
+	require.NoError(t, os.WriteFile(team, []byte(`{"lsp": {"gopls": {"command": "gopls"}}}`), 0o644))
+	project := filepath.Join(workingDir, "crush.json")
+	require.NoError(t, os.WriteFile(project, []byte(`{"extends": ["team.json"], "options": {"debug": false, "context_paths": ["b.md"]}, "mcp": {"a.b": {"command": "x"}}}`), 0o644))
+
+	merged, settings, err := Inspect(workingDir, "", "", false)
+	require.NoError(t, err)
+	require.Contains(t, string(merged), `"debug": false`)
+
+	origins := make(map[string]string)
+	for _, s := range settings {
+		origins[s.Key] = s.Origin
+	}
+	require.Equal(t, project, origins["options.debug"])
+	require.Equal(t, global+", "+project, origins["options.context_paths"])
+	require.Equal(t, project, origins[`mcp.a\.b.command`])
+	require.Equal(t, team, origins["lsp.gopls.command"])
+	require.Equal(t, "$CRUSH_DISABLE_DEFAULT_PROVIDERS", origins["options.disable_default_providers"])
+	require.Equal(t, OriginDefault, origins["options.initialize_as"])
+}
+
+func TestInspectRedactsSecrets(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	workingDir := t.TempDir()
+
+	project := filepath.Join(workingDir, "crush.json")
+	require.NoError(t, os.WriteFile(project, []byte(`{
+		"providers": {"openai": {"api_key": "sk-secret", "base_url": "https://api.openai.com/v1"}, "azure": {"api_key": "$AZURE_API_KEY"}},
+		"mcp": {"docs": {"type": "http", "url": "https://docs.example.com", "headers": {"Authorization": "Bearer secret"}, "oauth": {"client_id": "crush", "token": {"access_token": "at", "refresh_token": "rt"}}}}
+	}`), 0o644))
+
+	merged, settings, err := Inspect(workingDir, "", "", false)
+	require.NoError(t, err)
+	require.NotContains(t, string(merged), "secret")
+	values := make(map[string]string)
+	for _, s := range settings {
+		values[s.Key] = string(s.Value)
+	}
+	require.Equal(t, `"********"`, values["providers.openai.api_key"])
+	require.Equal(t, `"$AZURE_API_KEY"`, values["providers.azure.api_key"], "references aren't secrets")
+	require.Equal(t, `"https://api.openai.com/v1"`, values["providers.openai.base_url"])
+	require.Equal(t, `"********"`, values["mcp.docs.headers.Authorization"])
+	require.Equal(t, `"********"`, values["mcp.docs.oauth.token.access_token"])
+	require.Equal(t, `"********"`, values["mcp.docs.oauth.token.refresh_token"])
+	require.Equal(t, `"crush"`, values["mcp.docs.oauth.client_id"])
+
+	merged, _, err = Inspect(workingDir, "", "", true)
+	require.NoError(t, err)
+	require.Contains(t, string(merged), "sk-secret")
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	workingDir := t.TempDir()
+
+	data := GlobalConfigData()
+	require.NoError(t, os.MkdirAll(filepath.Dir(data), 0o755))
+	require.NoError(t, os.WriteFile(data, []byte(`{"recent_models": {}, "options": {"debug": "yes"}}`), 0o644))
+	project := filepath.Join(workingDir, ".crush.json")
+	require.NoError(t, os.WriteFile(project, []byte(`{"optoins": {}, "lsp": {"go": {"command": "gopls", "filetype": ["go"]}}, "mcp": {"fs": {"command": "fs"}}}`), 0o644))
+
+	problems, err := Validate(workingDir)
+	require.NoError(t, err)
+	require.Len(t, problems, 3)
+	require.Equal(t, data, problems[0].Path)
+	require.Contains(t, problems[0].Message, "cannot unmarshal")
+	require.Equal(t, Problem{Path: project, Key: "lsp.go.filetype", Message: "unknown key"}, problems[1])
+	require.Equal(t, Problem{Path: project, Key: "optoins", Message: "unknown key"}, problems[2])
+
+	require.NoError(t, os.WriteFile(project, []byte(`{"options": `), 0o644))
+	problems, err = Validate(workingDir)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	require.Equal(t, project, problems[1].Path)
+}
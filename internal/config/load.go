@@ -29,11 +29,12 @@ import (
 
 const defaultCatwalkURL = "https://catwalk.charm.sh"
 
-// Load loads the configuration from the default paths.
-func Load(workingDir, dataDir string, debug bool) (*Config, error) {
+// Load loads the configuration from the default paths, overlaid with the
+// profile when it's not empty.
+func Load(workingDir, dataDir, profile string, debug bool) (*Config, error) {
 	configPaths := lookupConfigs(workingDir)
 
-	cfg, err := loadFromConfigPaths(configPaths)
+	cfg, err := loadFromConfigPaths(configPaths, profile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config from paths %v: %w", configPaths, err)
 	}
@@ -79,12 +80,14 @@ func Load(workingDir, dataDir string, debug bool) (*Config, error) {
 
 	if !cfg.IsConfigured() {
 		slog.Warn("No providers configured")
+		cfg.loadedModels = maps.Clone(cfg.Models)
 		return cfg, nil
 	}
 
 	if err := cfg.configureSelectedModels(cfg.knownProviders); err != nil {
 		return nil, fmt.Errorf("failed to configure selected models: %w", err)
 	}
+	cfg.loadedModels = maps.Clone(cfg.Models)
 	cfg.SetupAgents()
 	return cfg, nil
 }
@@ -299,7 +302,7 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			c.Providers.Del(id)
 			continue
 		}
-		if len(providerConfig.Models) == 0 {
+		if len(providerConfig.Models) == 0 && !providerConfig.DiscoverModels {
 			slog.Warn("Skipping custom provider because the provider has no models", "provider", id)
 			c.Providers.Del(id)
 			continue
@@ -324,6 +327,15 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			providerConfig.ExtraHeaders[k] = resolved
 		}
 
+		if providerConfig.DiscoverModels {
+			providerConfig = cachedOrDiscoveredModels(context.Background(), providerConfig, apiKey, baseURL)
+			if len(providerConfig.Models) == 0 {
+				slog.Warn("Skipping custom provider because no models were discovered", "provider", id)
+				c.Providers.Del(id)
+				continue
+			}
+		}
+
 		c.Providers.Set(id, providerConfig)
 	}
 	return nil
@@ -364,6 +376,14 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 	if c.MCP == nil {
 		c.MCP = make(map[string]MCPConfig)
 	}
+	// OAuth credentials and the disabled flag saved in the data config
+	// outlive the MCP servers they were saved for, ignore them once the
+	// server is gone.
+	for name, m := range c.MCP {
+		if m.Type == "" && m.Command == "" && m.URL == "" {
+			delete(c.MCP, name)
+		}
+	}
 	if c.LSP == nil {
 		c.LSP = make(map[string]LSPConfig)
 	}
@@ -377,7 +397,7 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 	c.Options.ContextPaths = slices.Compact(c.Options.ContextPaths)
 
 	// Add the default skills directories if not already present.
-	for _, dir := range GlobalSkillsDirs() {
+	for _, dir := range append(GlobalSkillsDirs(), c.ProjectSkillsDir()) {
 		if !slices.Contains(c.Options.SkillsPaths, dir) {
 			c.Options.SkillsPaths = append(c.Options.SkillsPaths, dir)
 		}
@@ -645,32 +665,25 @@ func lookupConfigs(cwd string) []string {
 	return append(configPaths, foundConfigs...)
 }
 
-func loadFromConfigPaths(configPaths []string) (*Config, error) {
-	var configs [][]byte
-
-	for _, path := range configPaths {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
-		}
-		if len(data) == 0 {
-			continue
-		}
-		configs = append(configs, data)
+func loadFromConfigPaths(configPaths []string, profile string) (*Config, error) {
+	sources, err := readConfigs(configPaths)
+	if err != nil {
+		return nil, err
 	}
 
-	return loadFromBytes(configs)
+	configs := make([][]byte, 0, len(sources))
+	for _, source := range sources {
+		configs = append(configs, source.Data)
+	}
+	return loadFromBytes(configs, profile)
 }
 
-func loadFromBytes(configs [][]byte) (*Config, error) {
+func loadFromBytes(configs [][]byte, profile string) (*Config, error) {
 	if len(configs) == 0 {
 		return &Config{}, nil
 	}
 
-	data, err := jsons.Merge(configs)
+	data, err := mergeConfigs(configs, profile)
 	if err != nil {
 		return nil, err
 	}
@@ -678,9 +691,23 @@ func loadFromBytes(configs [][]byte) (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	config.profile = profile
 	return &config, nil
 }
 
+// mergeConfigs merges the config files, in order of priority, and overlays
+// the profile, if any.
+func mergeConfigs(configs [][]byte, profile string) ([]byte, error) {
+	data, err := jsons.Merge(configs)
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		return applyProfile(data, profile)
+	}
+	return data, nil
+}
+
 func hasVertexCredentials(env env.Env) bool {
 	hasProject := env.Get("VERTEXAI_PROJECT") != ""
 	hasLocation := env.Get("VERTEXAI_LOCATION") != ""
@@ -727,6 +754,18 @@ func GlobalConfig() string {
 	return filepath.Join(home.Dir(), ".config", appName, fmt.Sprintf("%s.json", appName))
 }
 
+// ProjectConfig returns the path to the config file of the project in
+// workingDir: the existing .crush.json or crush.json, crush.json otherwise.
+func ProjectConfig(workingDir string) string {
+	for _, name := range []string{"." + appName + ".json", appName + ".json"} {
+		path := filepath.Join(workingDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(workingDir, appName+".json")
+}
+
 // GlobalConfigData returns the path to the main data directory for the application.
 // this config is used when the app overrides configurations instead of updating the global config.
 func GlobalConfigData() string {
@@ -792,3 +831,13 @@ func GlobalSkillsDirs() []string {
 		filepath.Join(configBase, "agents", "skills"),
 	}
 }
+
+// ProjectSkillsDir returns the directory for the Agent Skills of the project,
+// in its data directory.
+func (c *Config) ProjectSkillsDir() string {
+	dataDir := c.Options.DataDirectory
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(c.workingDir, dataDir)
+	}
+	return filepath.Join(dataDir, "skills")
+}
@@ -26,7 +26,7 @@ func TestConfig_LoadFromBytes(t *testing.T) {
 	data2 := []byte(`{"providers": {"openai": {"api_key": "key2", "base_url": "https://api.openai.com/v2"}}}`)
 	data3 := []byte(`{"providers": {"openai": {}}}`)
 
-	loadedConfig, err := loadFromBytes([][]byte{data1, data2, data3})
+	loadedConfig, err := loadFromBytes([][]byte{data1, data2, data3}, "")
 
 	require.NoError(t, err)
 	require.NotNil(t, loadedConfig)
@@ -54,6 +54,16 @@ func TestConfig_setDefaults(t *testing.T) {
 		require.Contains(t, cfg.Options.ContextPaths, path)
 	}
 	require.Equal(t, "/tmp", cfg.workingDir)
+	require.Contains(t, cfg.Options.SkillsPaths, filepath.Join("/tmp", ".crush", "skills"))
+}
+
+func TestConfig_ProjectSkillsDir(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Options: &Options{DataDirectory: "data"}}
+	cfg.setDefaults("/tmp", "")
+	require.Equal(t, filepath.Join("/tmp", "data", "skills"), cfg.ProjectSkillsDir())
+	require.Contains(t, cfg.Options.SkillsPaths, cfg.ProjectSkillsDir())
 }
 
 func TestConfig_configureProviders(t *testing.T) {
@@ -468,7 +478,7 @@ func TestConfig_setupAgentsWithNoDisabledTools(t *testing.T) {
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "grep", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"glob", "grep", "ls", "repo_map", "sourcegraph", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
@@ -486,11 +496,11 @@ func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
 
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "multiedit", "lsp_diagnostics", "lsp_references", "lsp_restart", "fetch", "agentic_fetch", "glob", "ls", "sourcegraph", "todos", "view", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "multiedit", "notebook_edit", "move", "copy", "delete", "memory", "lsp_diagnostics", "lsp_references", "lsp_restart", "read_mcp_resource", "tool_search", "fetch", "agentic_fetch", "glob", "ls", "repo_map", "sourcegraph", "todos", "view", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
-	assert.Equal(t, []string{"glob", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
+	assert.Equal(t, []string{"glob", "ls", "repo_map", "sourcegraph", "view"}, taskAgent.AllowedTools)
 }
 
 func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
@@ -500,6 +510,7 @@ func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 				"glob",
 				"grep",
 				"ls",
+				"repo_map",
 				"sourcegraph",
 				"view",
 			},
@@ -509,7 +520,7 @@ func TestConfig_setupAgentsWithEveryReadOnlyToolDisabled(t *testing.T) {
 	cfg.SetupAgents()
 	coderAgent, ok := cfg.Agents[AgentCoder]
 	require.True(t, ok)
-	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "download", "edit", "multiedit", "lsp_diagnostics", "lsp_references", "lsp_restart", "fetch", "agentic_fetch", "todos", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"agent", "bash", "job_output", "job_kill", "download", "edit", "multiedit", "notebook_edit", "move", "copy", "delete", "memory", "lsp_diagnostics", "lsp_references", "lsp_restart", "read_mcp_resource", "tool_search", "fetch", "agentic_fetch", "todos", "write"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents[AgentTask]
 	require.True(t, ok)
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ProfileNames returns the names of the profiles in the config, sorted.
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// applyProfile overlays the profile name of the merged config data.
+func applyProfile(data []byte, name string) ([]byte, error) {
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	profiles, _ := cfg["profiles"].(map[string]any)
+	profile, ok := profiles[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	overlay(cfg, profile)
+	return json.Marshal(cfg)
+}
+
+// overlay merges the objects of src into dst, replacing everything else.
+func overlay(dst, src map[string]any) {
+	for k, v := range src {
+		srcObj, ok := v.(map[string]any)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstObj, ok := dst[k].(map[string]any)
+		if !ok {
+			dstObj = make(map[string]any)
+			dst[k] = dstObj
+		}
+		overlay(dstObj, srcObj)
+	}
+}
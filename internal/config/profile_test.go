@@ -0,0 +1,66 @@
+package config
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfile(t *testing.T) {
+	global := []byte(`{
+		"models": {"large": {"provider": "anthropic", "model": "claude"}},
+		"mcp": {"fs": {"command": "fs"}},
+		"permissions": {"allowed_tools": ["view", "bash"]},
+		"options": {"debug": true},
+		"profiles": {
+			"work": {
+				"models": {"large": {"provider": "azure", "model": "gpt"}},
+				"mcp": {"jira": {"command": "jira"}},
+				"permissions": {"allowed_tools": ["view"]}
+			}
+		}
+	}`)
+	project := []byte(`{"profiles": {"work": {"options": {"debug": false}}}}`)
+
+	cfg, err := loadFromBytes([][]byte{global, project}, "")
+	require.NoError(t, err)
+	require.Empty(t, cfg.Profile())
+	require.Equal(t, "anthropic", cfg.Models[SelectedModelTypeLarge].Provider)
+	require.Equal(t, []string{"work"}, cfg.ProfileNames())
+
+	cfg, err = loadFromBytes([][]byte{global, project}, "work")
+	require.NoError(t, err)
+	require.Equal(t, "work", cfg.Profile())
+	require.Equal(t, SelectedModel{Provider: "azure", Model: "gpt"}, cfg.Models[SelectedModelTypeLarge])
+	require.Contains(t, cfg.MCP, "fs")
+	require.Contains(t, cfg.MCP, "jira")
+	require.Equal(t, []string{"view"}, cfg.Permissions.AllowedTools, "lists are replaced")
+	require.False(t, cfg.Options.Debug, "profiles of every file are merged")
+
+	_, err = loadFromBytes([][]byte{global, project}, "home")
+	require.EqualError(t, err, `profile "home" not found`)
+}
+
+func TestReloadProfileKeepsSelectedModel(t *testing.T) {
+	t.Parallel()
+
+	global := []byte(`{"profiles": {"work": {"models": {"large": {"provider": "azure", "model": "gpt"}}}}}`)
+	load := func(data []byte) *Config {
+		cfg, err := loadFromBytes([][]byte{global, data}, "work")
+		require.NoError(t, err)
+		cfg.loadedModels = maps.Clone(cfg.Models)
+		return cfg
+	}
+
+	cfg := load([]byte(`{}`))
+	selected := SelectedModel{Provider: "anthropic", Model: "claude"}
+	cfg.Models[SelectedModelTypeLarge] = selected
+
+	// Saving the selection in the data config reloads the config, where the
+	// profile still wins.
+	reloaded := load([]byte(`{"models": {"large": {"provider": "anthropic", "model": "claude"}}}`))
+	require.Equal(t, "azure", reloaded.Models[SelectedModelTypeLarge].Provider)
+	cfg.KeepRuntime(reloaded)
+	require.Equal(t, selected, reloaded.Models[SelectedModelTypeLarge])
+}
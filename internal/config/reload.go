@@ -0,0 +1,85 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Changes describes what differs between two loads of the config.
+type Changes struct {
+	// Profile tells whether another profile was selected, or the profiles
+	// changed.
+	Profile     bool
+	Models      bool
+	Providers   bool
+	MCP         bool
+	Permissions bool
+	Options     bool
+	Tools       bool
+	// LSP holds the names of the LSP servers that were added, removed or
+	// changed.
+	LSP []string
+}
+
+// Any reports whether anything changed.
+func (c Changes) Any() bool {
+	return c.Profile || c.Models || c.Providers || c.MCP || c.Permissions || c.Options || c.Tools || len(c.LSP) > 0
+}
+
+// Diff returns what changed from c to other.
+func (c *Config) Diff(other *Config) Changes {
+	changes := Changes{
+		Profile:     c.profile != other.profile || !reflect.DeepEqual(c.Profiles, other.Profiles),
+		Models:      !reflect.DeepEqual(c.Models, other.Models),
+		Providers:   !reflect.DeepEqual(c.Providers.Copy(), other.Providers.Copy()),
+		MCP:         !reflect.DeepEqual(c.MCP, other.MCP),
+		Permissions: !slices.Equal(c.allowedTools(), other.allowedTools()),
+		Options:     !reflect.DeepEqual(c.Options, other.Options),
+		Tools:       !reflect.DeepEqual(c.Tools, other.Tools),
+	}
+	for name := range maps.Keys(c.LSP) {
+		if lsp, ok := other.LSP[name]; !ok || !reflect.DeepEqual(c.LSP[name], lsp) {
+			changes.LSP = append(changes.LSP, name)
+		}
+	}
+	for name := range maps.Keys(other.LSP) {
+		if _, ok := c.LSP[name]; !ok {
+			changes.LSP = append(changes.LSP, name)
+		}
+	}
+	slices.Sort(changes.LSP)
+	return changes
+}
+
+// KeepRuntime carries the settings of c that only live in memory over to
+// other, a config loaded again from the same files: skipping permission
+// requests, and the models selected while running unless the files select
+// other ones now. Otherwise a project or profile selecting models would undo
+// the selection as soon as it's saved.
+func (c *Config) KeepRuntime(other *Config) {
+	if c.Permissions != nil {
+		if other.Permissions == nil {
+			other.Permissions = &Permissions{}
+		}
+		other.Permissions.SkipRequests = c.Permissions.SkipRequests
+	}
+	if reflect.DeepEqual(other.loadedModels, c.loadedModels) {
+		other.Models = maps.Clone(c.Models)
+	}
+}
+
+// Apply makes other, a config loaded again from the same files, the current
+// config in place of c. The config is swapped atomically rather than updated
+// in place, so readers never see it half applied: c is left as it was and
+// [Get] returns other from now on.
+func (c *Config) Apply(other *Config) {
+	instance.Store(other)
+}
+
+func (c *Config) allowedTools() []string {
+	if c.Permissions == nil {
+		return nil
+	}
+	return c.Permissions.AllowedTools
+}
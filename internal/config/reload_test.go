@@ -0,0 +1,105 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func() *Config {
+		return &Config{
+			Models: map[SelectedModelType]SelectedModel{
+				SelectedModelTypeLarge: {Provider: "openai", Model: "gpt-4o"},
+			},
+			Providers: csync.NewMapFrom(map[string]ProviderConfig{
+				"openai": {ID: "openai", APIKey: "key"},
+			}),
+			MCP: MCPs{"fs": {Command: "fs-server"}},
+			LSP: LSPs{
+				"gopls": {Command: "gopls"},
+				"rust":  {Command: "rust-analyzer"},
+			},
+			Options:     &Options{},
+			Permissions: &Permissions{AllowedTools: []string{"view"}},
+		}
+	}
+
+	require.False(t, newConfig().Diff(newConfig()).Any())
+
+	other := newConfig()
+	other.Models[SelectedModelTypeLarge] = SelectedModel{Provider: "openai", Model: "gpt-5"}
+	other.Permissions.AllowedTools = append(other.Permissions.AllowedTools, "ls")
+	other.LSP["gopls"] = LSPConfig{Command: "gopls", Disabled: true}
+	delete(other.LSP, "rust")
+	other.LSP["zls"] = LSPConfig{Command: "zls"}
+	require.Equal(t, Changes{
+		Models:      true,
+		Permissions: true,
+		LSP:         []string{"gopls", "rust", "zls"},
+	}, newConfig().Diff(other))
+
+	other = newConfig()
+	other.Providers.Set("openai", ProviderConfig{ID: "openai", APIKey: "new-key"})
+	other.MCP["fs"] = MCPConfig{Command: "fs-server", Disabled: true}
+	require.Equal(t, Changes{Providers: true, MCP: true}, newConfig().Diff(other))
+}
+
+func TestKeepRuntime(t *testing.T) {
+	t.Parallel()
+
+	selected := map[SelectedModelType]SelectedModel{
+		SelectedModelTypeLarge: {Provider: "openai", Model: "gpt-4o"},
+	}
+	project := map[SelectedModelType]SelectedModel{
+		SelectedModelTypeLarge: {Provider: "anthropic", Model: "claude"},
+	}
+	cfg := &Config{
+		Models:       selected,
+		Permissions:  &Permissions{SkipRequests: true},
+		loadedModels: project,
+	}
+
+	other := &Config{Models: project, loadedModels: project}
+	cfg.KeepRuntime(other)
+	require.Equal(t, selected, other.Models, "the models selected while running are kept")
+	require.True(t, other.Permissions.SkipRequests, "skipping requests is kept")
+
+	changed := map[SelectedModelType]SelectedModel{
+		SelectedModelTypeLarge: {Provider: "openai", Model: "gpt-5"},
+	}
+	other = &Config{Models: changed, loadedModels: changed}
+	cfg.KeepRuntime(other)
+	require.Equal(t, changed, other.Models, "the models the files select now win")
+}
+
+func TestApply(t *testing.T) {
+	cfg := &Config{MCP: MCPs{"fs": {Command: "fs-server"}}}
+	instance.Store(cfg)
+	t.Cleanup(func() { instance.Store(nil) })
+
+	other := &Config{LSP: LSPs{"gopls": {Command: "gopls"}}}
+	cfg.Apply(other)
+
+	require.Same(t, other, Get())
+	require.Equal(t, MCPs{"fs": {Command: "fs-server"}}, cfg.MCP, "the previous config isn't changed")
+}
+
+func TestSetMCPDisabled(t *testing.T) {
+	cfg := &Config{
+		MCP:           MCPs{"fs": {Command: "fs-server"}},
+		dataConfigDir: filepath.Join(t.TempDir(), "crush.json"),
+	}
+	instance.Store(cfg)
+	t.Cleanup(func() { instance.Store(nil) })
+
+	require.NoError(t, cfg.SetMCPDisabled("fs", true))
+	require.False(t, cfg.MCP["fs"].Disabled, "the config read by others isn't changed")
+	require.True(t, Get().MCP["fs"].Disabled)
+
+	require.Error(t, Get().SetMCPDisabled("git", true))
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long to wait for more changes before reloading, as
+// editors often write a file in several steps.
+const watchDebounce = 300 * time.Millisecond
+
+// Watch calls onChange whenever one of the config files of workingDir is
+// created, changed or removed, until ctx is done. Writes of Crush itself,
+// like saving the selected model, are ignored since the config in memory
+// already has them. The files are looked up again after each change, so new
+// ones, like the targets of new extends, are watched too.
+func Watch(ctx context.Context, workingDir string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	var files, dirs []string
+	watch := func() {
+		files = watchedConfigs(workingDir)
+		for _, file := range files {
+			dir := filepath.Dir(file)
+			if slices.Contains(dirs, dir) {
+				continue
+			}
+			// Watch the directories, files may not exist yet and editors
+			// often replace them instead of writing them.
+			if err := watcher.Add(dir); err != nil {
+				slog.Debug("Error watching config directory", "dir", dir, "error", err)
+				continue
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	watch()
+
+	go func() {
+		defer watcher.Close()
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		changed := make(map[string]bool)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Clean(event.Name)
+				if event.Op == fsnotify.Chmod || !slices.Contains(files, name) {
+					continue
+				}
+				changed[name] = true
+				timer.Reset(watchDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Config watcher error", "error", err)
+			case <-timer.C:
+				own := true
+				for name := range changed {
+					own = own && wroteLast(name)
+				}
+				clear(changed)
+				if own {
+					continue
+				}
+				onChange()
+				watch()
+			}
+		}
+	}()
+	return nil
+}
+
+// watchedConfigs returns the config files that are loaded for workingDir,
+// along with the local files they extend, plus the ones in workingDir that
+// may be created.
+func watchedConfigs(workingDir string) []string {
+	files := lookupConfigs(workingDir)
+	sources, _ := readConfigs(files)
+	for _, source := range sources {
+		if !isRemote(source.Name) {
+			files = append(files, source.Name)
+		}
+	}
+	for _, name := range []string{appName + ".json", "." + appName + ".json"} {
+		files = append(files, filepath.Join(workingDir, name))
+	}
+	for i, file := range files {
+		files[i] = filepath.Clean(file)
+	}
+	slices.Sort(files)
+	return slices.Compact(files)
+}
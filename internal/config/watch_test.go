@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	workingDir := t.TempDir()
+
+	changed := make(chan struct{}, 10)
+	require.NoError(t, Watch(t.Context(), workingDir, func() {
+		changed <- struct{}{}
+	}))
+
+	// Files that aren't config files are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "main.go"), []byte("package main"), 0o644))
+	select {
+	case <-changed:
+		t.Fatal("unexpected change")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// Several writes in a row are reported once.
+	path := filepath.Join(workingDir, "crush.json")
+	for range 3 {
+		require.NoError(t, os.WriteFile(path, []byte(`{"mcp":{}}`), 0o644))
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+	select {
+	case <-changed:
+		t.Fatal("change reported twice")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// Writes of Crush itself are ignored.
+	require.NoError(t, SetField(path, "options.debug", true))
+	select {
+	case <-changed:
+		t.Fatal("own write reported")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// Files created after watching started are watched once they're
+	// extended.
+	shared := filepath.Join(t.TempDir(), "shared.json")
+	require.NoError(t, os.WriteFile(shared, []byte(`{}`), 0o644))
+	require.NoError(t, os.WriteFile(path, []byte(`{"extends": ["`+filepath.ToSlash(shared)+`"]}`), 0o644))
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+	require.NoError(t, os.WriteFile(shared, []byte(`{"options": {"debug": true}}`), 0o644))
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change of the extended file not reported")
+	}
+}
@@ -0,0 +1,264 @@
+package filetracker
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// maxSnapshotSize is the largest file whose content is kept to show what
+// changed in it. Larger files are only compared by their hash.
+const maxSnapshotSize = 1024 * 1024
+
+// Change describes a file that was modified outside of the session since
+// the session last read or wrote it, e.g. by the user in their editor.
+type Change struct {
+	Path string
+	// Before and After are the content of the file when it was last read and
+	// now. They're only set when Text is true.
+	Before string
+	After  string
+	// Text reports whether both versions are small text files that can be
+	// compared line by line.
+	Text bool
+	// Deleted reports whether the file no longer exists.
+	Deleted bool
+}
+
+// snapshot is what a session last saw of a file.
+type snapshot struct {
+	hash    [sha256.Size]byte
+	content []byte // nil when the file is larger than maxSnapshotSize
+	missing bool
+	// dirty is set when the file may have changed since the snapshot.
+	dirty bool
+}
+
+// changeTracker keeps a snapshot of every file read or written in a session,
+// and watches their directories to know which may have been changed
+// externally. Files that can't be watched are compared every time.
+type changeTracker struct {
+	mu        sync.Mutex
+	sessions  map[string]map[string]*snapshot
+	watcher   *fsnotify.Watcher
+	watchErr  error
+	watched   map[string]bool
+	unwatched map[string]bool
+	closed    bool
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{
+		sessions:  make(map[string]map[string]*snapshot),
+		watched:   make(map[string]bool),
+		unwatched: make(map[string]bool),
+	}
+}
+
+// record takes a new snapshot of the file at path for the session.
+func (t *changeTracker) record(sessionID, path string) {
+	snap, err := readSnapshot(path)
+	if err != nil {
+		slog.Debug("Error taking file snapshot", "error", err, "file", path)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	if snap.missing {
+		delete(t.sessions[sessionID], path)
+		return
+	}
+	files, ok := t.sessions[sessionID]
+	if !ok {
+		files = make(map[string]*snapshot)
+		t.sessions[sessionID] = files
+	}
+	files[path] = snap
+	t.watchLocked(filepath.Dir(path))
+}
+
+// change compares the file at path with the session's snapshot of it.
+func (t *changeTracker) change(sessionID, path string) (Change, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snap, ok := t.sessions[sessionID][path]
+	if !ok {
+		return Change{}, false
+	}
+	return t.compareLocked(path, snap)
+}
+
+// changes compares every file of the session that may have changed with its
+// snapshot.
+func (t *changeTracker) changes(sessionID string) []Change {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var changes []Change
+	for path, snap := range t.sessions[sessionID] {
+		if !snap.dirty && !t.unwatched[filepath.Dir(path)] {
+			continue
+		}
+		if change, ok := t.compareLocked(path, snap); ok {
+			changes = append(changes, change)
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes
+}
+
+func (t *changeTracker) compareLocked(path string, snap *snapshot) (Change, bool) {
+	current, err := readSnapshot(path)
+	if err != nil {
+		slog.Debug("Error reading file to check for changes", "error", err, "file", path)
+		return Change{}, false
+	}
+	if current.missing == snap.missing && current.hash == snap.hash {
+		snap.dirty = false
+		return Change{}, false
+	}
+	snap.dirty = true
+
+	change := Change{
+		Path:    path,
+		Deleted: current.missing,
+	}
+	if isText(snap) && isText(current) {
+		change.Before = string(snap.content)
+		change.After = string(current.content)
+		change.Text = true
+	}
+	return change, true
+}
+
+// watchLocked starts watching dir for changes, if it isn't yet.
+func (t *changeTracker) watchLocked(dir string) {
+	if t.watched[dir] || t.unwatched[dir] {
+		return
+	}
+	if t.watcher == nil && t.watchErr == nil {
+		t.watcher, t.watchErr = fsnotify.NewWatcher()
+		if t.watchErr != nil {
+			slog.Warn("Error creating file watcher, external changes will be polled", "error", t.watchErr)
+		} else {
+			go t.loop(t.watcher)
+		}
+	}
+	if t.watcher == nil {
+		t.unwatched[dir] = true
+		return
+	}
+	if err := t.watcher.Add(dir); err != nil {
+		slog.Debug("Error watching directory, external changes will be polled", "error", err, "dir", dir)
+		t.unwatched[dir] = true
+		return
+	}
+	t.watched[dir] = true
+}
+
+func (t *changeTracker) loop(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			t.markDirty(filepath.Clean(event.Name))
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been dropped, so anything may have changed.
+			slog.Warn("File watcher error", "error", err)
+			t.markDirty("")
+		}
+	}
+}
+
+// markDirty flags the snapshots of path in every session as possibly
+// outdated. An empty path flags all of them.
+func (t *changeTracker) markDirty(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, files := range t.sessions {
+		if path == "" {
+			for _, snap := range files {
+				snap.dirty = true
+			}
+			continue
+		}
+		if snap, ok := files[path]; ok {
+			snap.dirty = true
+		}
+	}
+}
+
+func (t *changeTracker) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	t.sessions = make(map[string]map[string]*snapshot)
+	if t.watcher == nil {
+		return nil
+	}
+	err := t.watcher.Close()
+	t.watcher = nil
+	return err
+}
+
+// readSnapshot hashes the file at path, keeping its content if it's small
+// enough.
+func readSnapshot(path string) (*snapshot, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &snapshot{missing: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, errors.New("path is a directory")
+	}
+
+	snap := &snapshot{}
+	h := sha256.New()
+	if info.Size() <= maxSnapshotSize {
+		snap.content, err = io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(snap.content)
+	} else if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	copy(snap.hash[:], h.Sum(nil))
+	return snap, nil
+}
+
+func isText(snap *snapshot) bool {
+	return snap.missing || (snap.content != nil && utf8.Valid(snap.content))
+}
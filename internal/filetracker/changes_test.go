@@ -0,0 +1,75 @@
+package filetracker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExternalChange(t *testing.T) {
+	env := setupTest(t)
+	t.Cleanup(func() { env.svc.Close() })
+
+	sessionID := "test-session"
+	env.createSession(t, sessionID)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("one\n"), 0o644))
+
+	_, changed := env.svc.ExternalChange(env.ctx, sessionID, path)
+	require.False(t, changed, "unread files have no snapshot")
+
+	env.svc.RecordRead(env.ctx, sessionID, path)
+	_, changed = env.svc.ExternalChange(env.ctx, sessionID, path)
+	require.False(t, changed)
+
+	require.NoError(t, os.WriteFile(path, []byte("two\n"), 0o644))
+	change, changed := env.svc.ExternalChange(env.ctx, sessionID, path)
+	require.True(t, changed)
+	require.True(t, change.Text)
+	require.Equal(t, "one\n", change.Before)
+	require.Equal(t, "two\n", change.After)
+
+	_, changed = env.svc.ExternalChange(env.ctx, "other-session", path)
+	require.False(t, changed, "other sessions never read the file")
+
+	env.svc.RecordRead(env.ctx, sessionID, path)
+	_, changed = env.svc.ExternalChange(env.ctx, sessionID, path)
+	require.False(t, changed, "reading the file again accepts the change")
+
+	require.NoError(t, os.Remove(path))
+	change, changed = env.svc.ExternalChange(env.ctx, sessionID, path)
+	require.True(t, changed)
+	require.True(t, change.Deleted)
+}
+
+func TestService_ExternalChanges(t *testing.T) {
+	env := setupTest(t)
+	t.Cleanup(func() { env.svc.Close() })
+
+	sessionID := "test-session"
+	env.createSession(t, sessionID)
+
+	dir := t.TempDir()
+	changedPath := filepath.Join(dir, "changed.txt")
+	untouchedPath := filepath.Join(dir, "untouched.txt")
+	require.NoError(t, os.WriteFile(changedPath, []byte("before"), 0o644))
+	require.NoError(t, os.WriteFile(untouchedPath, []byte("same"), 0o644))
+	env.svc.RecordRead(env.ctx, sessionID, changedPath)
+	env.svc.RecordRead(env.ctx, sessionID, untouchedPath)
+	require.Empty(t, env.svc.ExternalChanges(env.ctx, sessionID))
+
+	require.NoError(t, os.WriteFile(changedPath, []byte("after"), 0o644))
+	require.Eventually(t, func() bool {
+		changes := env.svc.ExternalChanges(env.ctx, sessionID)
+		return len(changes) == 1 && changes[0].Path == changedPath
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Changes are reported until the file is read again.
+	require.Len(t, env.svc.ExternalChanges(env.ctx, sessionID), 1)
+	env.svc.RecordRead(env.ctx, sessionID, changedPath)
+	require.Empty(t, env.svc.ExternalChanges(env.ctx, sessionID))
+}
@@ -13,24 +13,39 @@ import (
 
 // Service defines the interface for tracking file reads in sessions.
 type Service interface {
-	// RecordRead records when a file was read.
+	// RecordRead records when a file was read, and what it contained.
 	RecordRead(ctx context.Context, sessionID, path string)
 
 	// LastReadTime returns when a file was last read.
 	// Returns zero time if never read.
 	LastReadTime(ctx context.Context, sessionID, path string) time.Time
+
+	// ExternalChange reports whether a file was changed on disk since it was
+	// last read in the session.
+	ExternalChange(ctx context.Context, sessionID, path string) (Change, bool)
+
+	// ExternalChanges returns the files of the session that were changed on
+	// disk since they were last read.
+	ExternalChanges(ctx context.Context, sessionID string) []Change
+
+	// Close stops watching files for external changes.
+	Close() error
 }
 
 type service struct {
-	q *db.Queries
+	q       *db.Queries
+	changes *changeTracker
 }
 
 // NewService creates a new file tracker service.
 func NewService(q *db.Queries) Service {
-	return &service{q: q}
+	return &service{
+		q:       q,
+		changes: newChangeTracker(),
+	}
 }
 
-// RecordRead records when a file was read.
+// RecordRead records when a file was read, and what it contained.
 func (s *service) RecordRead(ctx context.Context, sessionID, path string) {
 	if err := s.q.RecordFileRead(ctx, db.RecordFileReadParams{
 		SessionID: sessionID,
@@ -38,6 +53,7 @@ func (s *service) RecordRead(ctx context.Context, sessionID, path string) {
 	}); err != nil {
 		slog.Error("Error recording file read", "error", err, "file", path)
 	}
+	s.changes.record(sessionID, abspath(path))
 }
 
 // LastReadTime returns when a file was last read.
@@ -54,6 +70,31 @@ func (s *service) LastReadTime(ctx context.Context, sessionID, path string) time
 	return time.Unix(readFile.ReadAt, 0)
 }
 
+// ExternalChange reports whether a file was changed on disk since it was last
+// read in the session.
+func (s *service) ExternalChange(ctx context.Context, sessionID, path string) (Change, bool) {
+	return s.changes.change(sessionID, abspath(path))
+}
+
+// ExternalChanges returns the files of the session that were changed on disk
+// since they were last read.
+func (s *service) ExternalChanges(ctx context.Context, sessionID string) []Change {
+	return s.changes.changes(sessionID)
+}
+
+// Close stops watching files for external changes.
+func (s *service) Close() error {
+	return s.changes.close()
+}
+
+func abspath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
 func relpath(path string) string {
 	path = filepath.Clean(path)
 	basepath, err := os.Getwd()
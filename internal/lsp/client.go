@@ -39,6 +39,9 @@ type Client struct {
 	// Working directory this LSP is scoped to.
 	workDir string
 
+	// Project root this LSP was started for, within the working directory.
+	root string
+
 	// File types this LSP server handles (e.g., .go, .rs, .py)
 	fileTypes []string
 
@@ -67,10 +70,12 @@ type Client struct {
 	serverState atomic.Value
 }
 
-// New creates a new LSP client using the powernap implementation.
-func New(ctx context.Context, name string, cfg config.LSPConfig, resolver config.VariableResolver) (*Client, error) {
+// New creates a new LSP client using the powernap implementation, rooted at
+// the given project root. If root is empty, the working directory is used.
+func New(ctx context.Context, name string, cfg config.LSPConfig, resolver config.VariableResolver, root string) (*Client, error) {
 	client := &Client{
 		name:        name,
+		root:        root,
 		fileTypes:   cfg.FileTypes,
 		diagnostics: csync.NewVersionedMap[protocol.DocumentURI, []protocol.Diagnostic](),
 		openFiles:   csync.NewMap[string, *OpenFileInfo](),
@@ -137,8 +142,11 @@ func (c *Client) createPowernapClient() error {
 		return fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	rootURI := string(protocol.URIFromPath(workDir))
 	c.workDir = workDir
+	if c.root == "" {
+		c.root = workDir
+	}
+	rootURI := string(protocol.URIFromPath(c.root))
 
 	command, err := c.resolver.ResolveValue(c.config.Command)
 	if err != nil {
@@ -155,7 +163,7 @@ func (c *Client) createPowernapClient() error {
 		WorkspaceFolders: []protocol.WorkspaceFolder{
 			{
 				URI:  rootURI,
-				Name: filepath.Base(workDir),
+				Name: filepath.Base(c.root),
 			},
 		},
 	}
@@ -255,6 +263,11 @@ func (c *Client) GetName() string {
 	return c.name
 }
 
+// Root returns the project root the LSP client was started for.
+func (c *Client) Root() string {
+	return c.root
+}
+
 // SetDiagnosticsCallback sets the callback function for diagnostic changes
 func (c *Client) SetDiagnosticsCallback(callback func(name string, count int)) {
 	c.onDiagnosticsChanged = callback
@@ -312,38 +325,52 @@ type OpenFileInfo struct {
 }
 
 // HandlesFile checks if this LSP client handles the given file based on its
-// extension and whether it's within the working directory.
+// extension and whether it's within the client's project root. Files that
+// belong to a nested project root (as determined by the root markers) are
+// left to the client of that root.
 func (c *Client) HandlesFile(path string) bool {
-	// Check if file is within working directory.
+	// Check if file is within the project root.
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		slog.Debug("Cannot resolve path", "name", c.name, "file", path, "error", err)
 		return false
 	}
-	relPath, err := filepath.Rel(c.workDir, absPath)
-	if err != nil || strings.HasPrefix(relPath, "..") {
-		slog.Debug("File outside workspace", "name", c.name, "file", path, "workDir", c.workDir)
+	if !isWithin(c.root, absPath) {
+		slog.Debug("File outside workspace", "name", c.name, "file", path, "root", c.root)
+		return false
+	}
+	if root := FindRoot(absPath, c.workDir, c.config.RootMarkers); root != "" && root != c.root {
+		slog.Debug("File belongs to another root", "name", c.name, "file", path, "root", c.root, "fileRoot", root)
 		return false
 	}
 
-	// If no file types are specified, handle all files (backward compatibility).
-	if len(c.fileTypes) == 0 {
+	if !handlesFileType(c.fileTypes, path) {
+		slog.Debug("Doesn't handle file", "name", c.name, "file", filepath.Base(path))
+		return false
+	}
+	slog.Debug("Handles file", "name", c.name, "file", filepath.Base(path))
+	return true
+}
+
+// handlesFileType checks if the file matches one of the given file types.
+// If no file types are specified, all files are handled (backward
+// compatibility).
+func handlesFileType(fileTypes []string, path string) bool {
+	if len(fileTypes) == 0 {
 		return true
 	}
 
 	kind := powernap.DetectLanguage(path)
 	name := strings.ToLower(filepath.Base(path))
-	for _, filetype := range c.fileTypes {
+	for _, filetype := range fileTypes {
 		suffix := strings.ToLower(filetype)
 		if !strings.HasPrefix(suffix, ".") {
 			suffix = "." + suffix
 		}
 		if strings.HasSuffix(name, suffix) || filetype == string(kind) {
-			slog.Debug("Handles file", "name", c.name, "file", name, "filetype", filetype, "kind", kind)
 			return true
 		}
 	}
-	slog.Debug("Doesn't handle file", "name", c.name, "file", name)
 	return false
 }
 
@@ -407,6 +434,43 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	return c.client.NotifyDidChangeTextDocument(ctx, uri, int(fileInfo.Version), changes)
 }
 
+// CloseFile closes a file in the LSP server, if it's open.
+func (c *Client) CloseFile(ctx context.Context, filepath string) error {
+	uri := string(protocol.URIFromPath(filepath))
+	if _, exists := c.openFiles.Get(uri); !exists {
+		return nil
+	}
+	if err := c.client.NotifyDidCloseTextDocument(ctx, uri); err != nil {
+		return err
+	}
+	c.openFiles.Del(uri)
+	return nil
+}
+
+// NotifyFileOperations tells the server that files were created, moved or
+// deleted outside of the editing flow. Files that are gone are closed and
+// their diagnostics dropped, then the server is sent the matching watched
+// file events, which is how servers learn about renames and deletions made
+// on disk.
+func (c *Client) NotifyFileOperations(ctx context.Context, created, deleted []string) error {
+	var changes []protocol.FileEvent
+	for _, path := range deleted {
+		if err := c.CloseFile(ctx, path); err != nil {
+			slog.Warn("Error closing file", "file", path, "error", err)
+		}
+		uri := protocol.URIFromPath(path)
+		c.ClearDiagnosticsForURI(uri)
+		changes = append(changes, protocol.FileEvent{URI: uri, Type: protocol.Deleted})
+	}
+	for _, path := range created {
+		changes = append(changes, protocol.FileEvent{URI: protocol.URIFromPath(path), Type: protocol.Created})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return c.client.NotifyDidChangeWatchedFiles(ctx, changes)
+}
+
 // IsFileOpen checks if a file is currently open.
 func (c *Client) IsFileOpen(filepath string) bool {
 	uri := string(protocol.URIFromPath(filepath))
@@ -528,14 +592,9 @@ func (c *Client) DidChangeWatchedFiles(ctx context.Context, params protocol.DidC
 
 // openKeyConfigFiles opens important configuration files that help initialize the server.
 func (c *Client) openKeyConfigFiles(ctx context.Context) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return
-	}
-
 	// Try to open each file, ignoring errors if they don't exist
 	for _, file := range c.config.RootMarkers {
-		file = filepath.Join(wd, file)
+		file = filepath.Join(c.root, file)
 		if _, err := os.Stat(file); err == nil {
 			// File exists, try to open it
 			if err := c.OpenFile(ctx, file); err != nil {
@@ -578,7 +637,8 @@ func (c *Client) FindReferences(ctx context.Context, filepath string, line, char
 }
 
 // FilterMatching gets a list of configs and only returns the ones with
-// matching root markers.
+// matching root markers. Markers without a path separator match at any depth,
+// so servers for nested project roots (e.g. in a monorepo) are included.
 func FilterMatching(dir string, servers map[string]*powernapconfig.ServerConfig) map[string]*powernapconfig.ServerConfig {
 	result := map[string]*powernapconfig.ServerConfig{}
 	if len(servers) == 0 {
@@ -619,10 +679,14 @@ func FilterMatching(dir string, servers map[string]*powernapconfig.ServerConfig)
 			return nil
 		}
 		relPath = filepath.ToSlash(relPath)
+		baseName := filepath.Base(path)
 
 		for name, sp := range normalized {
 			for _, pattern := range sp.patterns {
 				matched, err := doublestar.Match(pattern, relPath)
+				if err == nil && !matched && !strings.Contains(pattern, "/") {
+					matched, err = doublestar.Match(pattern, baseName)
+				}
 				if err != nil || !matched {
 					continue
 				}
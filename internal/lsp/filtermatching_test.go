@@ -108,4 +108,22 @@ func TestFilterMatching(t *testing.T) {
 		require.Contains(t, result, "gopls")
 		require.Contains(t, result, "rust-analyzer")
 	})
+
+	t.Run("matches root markers in nested directories", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := t.TempDir()
+
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "services", "api"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "services", "api", "go.mod"), []byte("module api"), 0o644))
+
+		servers := map[string]*powernapconfig.ServerConfig{
+			"gopls":          {RootMarkers: []string{"go.mod"}},
+			"typescript-lsp": {RootMarkers: []string{"package.json"}},
+		}
+
+		result := FilterMatching(tmpDir, servers)
+
+		require.Contains(t, result, "gopls")
+		require.NotContains(t, result, "typescript-lsp")
+	})
 }
@@ -0,0 +1,177 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
+)
+
+// StartFunc starts a client for the named server rooted at root, and
+// registers it under key once it's up. path is the file that triggered the
+// start, if any, and should be opened once the client is ready.
+type StartFunc func(key, name string, cfg config.LSPConfig, root, path string)
+
+// Manager keeps track of the LSP clients of a workspace and routes files to
+// the client of their project root.
+//
+// Monorepos often have several roots for the same language (e.g. multiple
+// go.mod or package.json files). Instead of a single client rooted at the
+// working directory, a client is started per root, lazily, the first time a
+// file under that root is opened.
+type Manager struct {
+	workDir string
+	clients *csync.Map[string, *Client]
+	servers *csync.Map[string, config.LSPConfig]
+	start   StartFunc
+
+	mu      sync.Mutex
+	started map[string]struct{}
+}
+
+// NewManager creates a new manager for the given working directory.
+func NewManager(workDir string, start StartFunc) *Manager {
+	return &Manager{
+		workDir: workDir,
+		clients: csync.NewMap[string, *Client](),
+		servers: csync.NewMap[string, config.LSPConfig](),
+		start:   start,
+		started: make(map[string]struct{}),
+	}
+}
+
+// Clients returns the running clients, keyed by [ClientKey].
+func (m *Manager) Clients() *csync.Map[string, *Client] {
+	return m.clients
+}
+
+// Register adds a server that can be started on demand for files it handles.
+func (m *Manager) Register(name string, cfg config.LSPConfig) {
+	m.servers.Set(name, cfg)
+}
+
+// Remove unregisters the named server and returns its clients, keyed by
+// [ClientKey], which the caller should close.
+func (m *Manager) Remove(name string) map[string]*Client {
+	m.servers.Del(name)
+
+	m.mu.Lock()
+	for key := range m.started {
+		if isClientOf(name, key) {
+			delete(m.started, key)
+		}
+	}
+	m.mu.Unlock()
+
+	clients := make(map[string]*Client)
+	for key, client := range m.clients.Seq2() {
+		if isClientOf(name, key) {
+			clients[key] = client
+			m.clients.Del(key)
+		}
+	}
+	return clients
+}
+
+// isClientOf reports whether key belongs to a client of the named server.
+func isClientOf(name, key string) bool {
+	return key == name || strings.HasPrefix(key, name+"@")
+}
+
+// StartRoot starts the named server rooted at root, unless it was already
+// started.
+func (m *Manager) StartRoot(name, root, path string) {
+	cfg, ok := m.servers.Get(name)
+	if !ok {
+		return
+	}
+	key := ClientKey(name, root, m.workDir)
+
+	m.mu.Lock()
+	if _, ok := m.started[key]; ok {
+		m.mu.Unlock()
+		return
+	}
+	m.started[key] = struct{}{}
+	m.mu.Unlock()
+
+	go m.start(key, name, cfg, root, path)
+}
+
+// Start makes sure every registered server that handles path has a client
+// for the project root path belongs to, starting one if needed.
+//
+// Clients are started in the background, so the client for a new root will
+// not be available right away.
+func (m *Manager) Start(path string) {
+	if path == "" {
+		return
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil || !isWithin(m.workDir, absPath) {
+		return
+	}
+	for name, cfg := range m.servers.Seq2() {
+		if !handlesFileType(cfg.FileTypes, absPath) {
+			continue
+		}
+		root := FindRoot(absPath, m.workDir, cfg.RootMarkers)
+		if root == "" {
+			root = m.workDir
+		}
+		m.StartRoot(name, root, absPath)
+	}
+}
+
+// ClientKey returns the key a client is registered under: the server name
+// for the working directory root, and name@relative/root for nested roots.
+func ClientKey(name, root, workDir string) string {
+	rel, err := filepath.Rel(workDir, root)
+	if err != nil || rel == "." || rel == "" {
+		return name
+	}
+	return name + "@" + filepath.ToSlash(rel)
+}
+
+// FindRoot returns the project root for path: the closest directory, from
+// path up to boundary, containing one of the given root markers. Markers are
+// tried in order, so earlier markers take precedence over closer matches of
+// later ones (e.g. a go.work wins over a nested go.mod).
+//
+// Glob markers are ignored. An empty string is returned when no marker is
+// found within boundary.
+func FindRoot(path, boundary string, markers []string) string {
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	if !isWithin(boundary, dir) {
+		return ""
+	}
+	for _, marker := range markers {
+		if marker == "" || strings.ContainsAny(marker, "*?[{") {
+			continue
+		}
+		for current := dir; isWithin(boundary, current); current = filepath.Dir(current) {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current
+			}
+			if current == boundary || current == filepath.Dir(current) {
+				break
+			}
+		}
+	}
+	return ""
+}
+
+// isWithin reports whether path is dir or one of its descendants.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,139 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoot(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"svc/api/internal", "web/app/src", "docs"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0o755))
+	}
+	for _, file := range []string{"go.mod", "svc/api/go.mod", "web/app/package.json"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), nil, 0o644))
+	}
+
+	t.Run("closest root marker wins", func(t *testing.T) {
+		t.Parallel()
+		root := FindRoot(filepath.Join(tmpDir, "svc/api/internal/main.go"), tmpDir, []string{"go.mod"})
+		require.Equal(t, filepath.Join(tmpDir, "svc/api"), root)
+	})
+
+	t.Run("falls back to the working directory root", func(t *testing.T) {
+		t.Parallel()
+		root := FindRoot(filepath.Join(tmpDir, "docs/main.go"), tmpDir, []string{"go.mod"})
+		require.Equal(t, tmpDir, root)
+	})
+
+	t.Run("earlier markers take precedence", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "mod"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.work"), nil, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "mod", "go.mod"), nil, 0o644))
+
+		root := FindRoot(filepath.Join(dir, "mod", "main.go"), dir, []string{"go.work", "go.mod"})
+		require.Equal(t, dir, root)
+	})
+
+	t.Run("no marker found", func(t *testing.T) {
+		t.Parallel()
+		root := FindRoot(filepath.Join(tmpDir, "web/app/src/index.ts"), tmpDir, []string{"Cargo.toml"})
+		require.Empty(t, root)
+	})
+
+	t.Run("outside of boundary", func(t *testing.T) {
+		t.Parallel()
+		root := FindRoot(filepath.Join(t.TempDir(), "main.go"), tmpDir, []string{"go.mod"})
+		require.Empty(t, root)
+	})
+
+	t.Run("glob markers are ignored", func(t *testing.T) {
+		t.Parallel()
+		root := FindRoot(filepath.Join(tmpDir, "svc/api/main.go"), tmpDir, []string{"**/*.go"})
+		require.Empty(t, root)
+	})
+}
+
+func TestClientKey(t *testing.T) {
+	t.Parallel()
+
+	workDir := filepath.FromSlash("/repo")
+	require.Equal(t, "gopls", ClientKey("gopls", workDir, workDir))
+	require.Equal(t, "gopls@svc/api", ClientKey("gopls", filepath.Join(workDir, "svc", "api"), workDir))
+}
+
+func TestManagerStart(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "svc", "api"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "svc", "api", "go.mod"), nil, 0o644))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	started := map[string]string{}
+	manager := NewManager(tmpDir, func(key, name string, cfg config.LSPConfig, root, path string) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		started[key] = root
+	})
+	manager.Register("gopls", config.LSPConfig{
+		FileTypes:   []string{"go"},
+		RootMarkers: []string{"go.mod"},
+	})
+
+	wg.Add(2)
+	manager.Start(filepath.Join(tmpDir, "main.go"))
+	manager.Start(filepath.Join(tmpDir, "svc", "api", "main.go"))
+	// Already started roots and unhandled files don't start new clients.
+	manager.Start(filepath.Join(tmpDir, "svc", "api", "handler.go"))
+	manager.Start(filepath.Join(tmpDir, "svc", "api", "README.md"))
+	wg.Wait()
+
+	require.Equal(t, map[string]string{
+		"gopls":         tmpDir,
+		"gopls@svc/api": filepath.Join(tmpDir, "svc", "api"),
+	}, started)
+}
+
+func TestManagerRemove(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	var wg sync.WaitGroup
+	manager := NewManager(tmpDir, func(key, name string, cfg config.LSPConfig, root, path string) {
+		wg.Done()
+	})
+	manager.Register("gopls", config.LSPConfig{FileTypes: []string{"go"}})
+	wg.Add(1)
+	manager.Start(filepath.Join(tmpDir, "main.go"))
+	wg.Wait()
+	manager.Clients().Set("gopls", &Client{})
+	manager.Clients().Set("gopls@svc/api", &Client{})
+	manager.Clients().Set("goplsx", &Client{})
+
+	clients := manager.Remove("gopls")
+	require.Len(t, clients, 2)
+	require.Contains(t, clients, "gopls")
+	require.Contains(t, clients, "gopls@svc/api")
+	require.Equal(t, 1, manager.Clients().Len())
+
+	// Removed servers aren't started anymore, and start again once
+	// registered.
+	manager.Start(filepath.Join(tmpDir, "main.go"))
+	wg.Add(1)
+	manager.Register("gopls", config.LSPConfig{FileTypes: []string{"go"}})
+	manager.Start(filepath.Join(tmpDir, "main.go"))
+	wg.Wait()
+}
@@ -0,0 +1,220 @@
+// Package memory manages the entries the agent and the user record in a
+// context file, so they're part of the prompt of future sessions.
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scope selects the file the entries are saved in.
+type Scope string
+
+const (
+	// ScopeProject entries are saved in the project's context file, shared
+	// with everyone working on the project.
+	ScopeProject Scope = "project"
+	// ScopeLocal entries are saved in [LocalFile], for personal notes that
+	// aren't meant to be committed.
+	ScopeLocal Scope = "local"
+)
+
+// LocalFile is the context file of the local scope.
+const LocalFile = "crush.local.md"
+
+const (
+	sectionStart = "<!-- crush:memory:start -->"
+	sectionEnd   = "<!-- crush:memory:end -->"
+	sectionTitle = "## Memory"
+)
+
+var (
+	ErrEmptyEntry     = errors.New("entry is empty")
+	ErrEntryExists    = errors.New("entry already exists")
+	ErrEntryNotFound  = errors.New("entry not found")
+	ErrAmbiguousEntry = errors.New("entry matches more than one entry, be more specific")
+)
+
+// Path returns the memory file of the scope. Project entries go to
+// projectFile, the context file created when initializing the project.
+func Path(workingDir, projectFile string, scope Scope) string {
+	name := projectFile
+	if scope == ScopeLocal {
+		name = LocalFile
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(workingDir, name)
+}
+
+// Entries returns the entries of the managed section of content.
+func Entries(content string) []string {
+	_, section, _ := split(content)
+	var entries []string
+	for line := range strings.SplitSeq(section, "\n") {
+		if entry, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+	return entries
+}
+
+// Add returns content with entry added to its managed section.
+func Add(content, entry string) (string, error) {
+	entry = normalize(entry)
+	if entry == "" {
+		return "", ErrEmptyEntry
+	}
+	entries := Entries(content)
+	for _, e := range entries {
+		if e == entry {
+			return "", ErrEntryExists
+		}
+	}
+	return replace(content, append(entries, entry)), nil
+}
+
+// Update returns content with the entry matching oldEntry replaced by entry.
+func Update(content, oldEntry, entry string) (string, error) {
+	entry = normalize(entry)
+	if entry == "" {
+		return "", ErrEmptyEntry
+	}
+	entries := Entries(content)
+	i, err := find(entries, oldEntry)
+	if err != nil {
+		return "", err
+	}
+	entries[i] = entry
+	return replace(content, entries), nil
+}
+
+// Remove returns content without the entry matching oldEntry.
+func Remove(content, oldEntry string) (string, error) {
+	entries := Entries(content)
+	i, err := find(entries, oldEntry)
+	if err != nil {
+		return "", err
+	}
+	return replace(content, append(entries[:i], entries[i+1:]...)), nil
+}
+
+// Save adds entry to the memory file at path, creating it if needed.
+func Save(path, entry string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read memory file: %w", err)
+	}
+	newContent, err := Add(string(content), entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(newContent), 0o644); err != nil {
+		return fmt.Errorf("failed to write memory file: %w", err)
+	}
+	return nil
+}
+
+// find returns the index of the entry equal to query, or else the only one
+// containing it.
+func find(entries []string, query string) (int, error) {
+	query = normalize(query)
+	if query == "" {
+		return 0, ErrEmptyEntry
+	}
+	match := -1
+	for i, e := range entries {
+		if e == query {
+			return i, nil
+		}
+		if strings.Contains(e, query) {
+			if match != -1 {
+				return 0, ErrAmbiguousEntry
+			}
+			match = i
+		}
+	}
+	if match == -1 {
+		return 0, ErrEntryNotFound
+	}
+	return match, nil
+}
+
+// split splits content around the body of its managed section.
+func split(content string) (before, section, after string) {
+	start := strings.Index(content, sectionStart)
+	if start == -1 {
+		return content, "", ""
+	}
+	end := strings.Index(content[start:], sectionEnd)
+	if end == -1 {
+		return content[:start], content[start+len(sectionStart):], ""
+	}
+	end += start
+	return content[:start], content[start+len(sectionStart) : end], content[end+len(sectionEnd):]
+}
+
+// replace returns content with its managed section holding entries. The
+// section is added at the end of content if it doesn't have one, and removed
+// when there are no entries.
+func replace(content string, entries []string) string {
+	before, _, after := split(content)
+	var section strings.Builder
+	if len(entries) > 0 {
+		section.WriteString(sectionStart + "\n" + sectionTitle + "\n\n")
+		for _, e := range entries {
+			section.WriteString("- " + e + "\n")
+		}
+		section.WriteString(sectionEnd)
+	}
+
+	if after != "" || strings.Contains(content, sectionStart) {
+		if section.Len() == 0 {
+			result := strings.TrimRight(before, "\n")
+			if after = strings.TrimLeft(after, "\n"); after != "" {
+				return result + "\n\n" + after
+			}
+			if result == "" {
+				return ""
+			}
+			return result + "\n"
+		}
+		return before + section.String() + after
+	}
+	if section.Len() == 0 {
+		return content
+	}
+	before = strings.TrimRight(before, "\n")
+	if before == "" {
+		return section.String() + "\n"
+	}
+	return before + "\n\n" + section.String() + "\n"
+}
+
+// normalize keeps entries to a single line.
+func normalize(entry string) string {
+	return strings.Join(strings.Fields(entry), " ")
+}
+
+// ParseShortcut returns the entry of a single line message starting with #,
+// which users type to save it as memory without asking the agent. Markdown
+// headings of deeper levels aren't shortcuts.
+func ParseShortcut(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if strings.Contains(text, "\n") || strings.HasPrefix(text, "##") {
+		return "", false
+	}
+	entry, ok := strings.CutPrefix(text, "#")
+	if !ok {
+		return "", false
+	}
+	entry = strings.TrimSpace(entry)
+	return entry, entry != ""
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddUpdateRemove(t *testing.T) {
+	t.Parallel()
+
+	content := "# Project\n\nBuild with make.\n"
+
+	content, err := Add(content, "Run tests with `go test ./...`")
+	require.NoError(t, err)
+	require.Equal(t, "# Project\n\nBuild with make.\n\n"+
+		sectionStart+"\n"+sectionTitle+"\n\n"+
+		"- Run tests with `go test ./...`\n"+
+		sectionEnd+"\n", content)
+
+	content, err = Add(content, "The CI uses\nGo 1.25")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Run tests with `go test ./...`", "The CI uses Go 1.25"}, Entries(content))
+
+	_, err = Add(content, "The CI uses Go 1.25")
+	require.ErrorIs(t, err, ErrEntryExists)
+	_, err = Update(content, "u", "x")
+	require.ErrorIs(t, err, ErrAmbiguousEntry)
+	_, err = Remove(content, "nope")
+	require.ErrorIs(t, err, ErrEntryNotFound)
+
+	content, err = Update(content, "Go 1.25", "The CI uses Go 1.26")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Run tests with `go test ./...`", "The CI uses Go 1.26"}, Entries(content))
+
+	content, err = Remove(content, "Run tests")
+	require.NoError(t, err)
+	content, err = Remove(content, "The CI uses Go 1.26")
+	require.NoError(t, err)
+	require.Equal(t, "# Project\n\nBuild with make.\n", content)
+}
+
+func TestAddKeepsContentAfterSection(t *testing.T) {
+	t.Parallel()
+
+	content := "intro\n\n" + sectionStart + "\n" + sectionTitle + "\n\n- one\n" + sectionEnd + "\n\n## More\n"
+	content, err := Add(content, "two")
+	require.NoError(t, err)
+	require.Equal(t, "intro\n\n"+sectionStart+"\n"+sectionTitle+"\n\n- one\n- two\n"+sectionEnd+"\n\n## More\n", content)
+}
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := Path(dir, "AGENTS.md", ScopeLocal)
+	require.Equal(t, filepath.Join(dir, LocalFile), path)
+
+	require.NoError(t, Save(path, "first"))
+	require.NoError(t, Save(path, "second"))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second"}, Entries(string(content)))
+}
+
+func TestParseShortcut(t *testing.T) {
+	t.Parallel()
+
+	for text, want := range map[string]string{
+		"# use pnpm, not npm": "use pnpm, not npm",
+		"#tabs for indents":   "tabs for indents",
+		"#":                   "",
+		"## Heading":          "",
+		"# two\nlines":        "",
+		"no shortcut":         "",
+	} {
+		entry, ok := ParseShortcut(text)
+		require.Equal(t, want != "", ok, text)
+		require.Equal(t, want, entry, text)
+	}
+}
@@ -0,0 +1,210 @@
+// Package mcp implements the MCP authorization flow for HTTP and SSE MCP
+// servers: protected resource metadata discovery, dynamic client
+// registration and the authorization code flow with PKCE.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Metadata holds the endpoints of the authorization server of an MCP server.
+type Metadata struct {
+	// Resource is the MCP server URL, sent as the resource parameter of
+	// authorization and token requests.
+	Resource              string
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	RegistrationEndpoint  string
+	Scopes                []string
+}
+
+// protectedResource is the OAuth 2.0 protected resource metadata, RFC 9728.
+type protectedResource struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported"`
+}
+
+// authorizationServer is the OAuth 2.0 authorization server metadata, RFC
+// 8414.
+type authorizationServer struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint"`
+	ScopesSupported               []string `json:"scopes_supported"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Discover finds the authorization server of the MCP server at serverURL.
+//
+// The protected resource metadata is taken from the resource_metadata
+// parameter of the WWW-Authenticate header the server answers an
+// unauthenticated request with, or from its well-known location. Servers
+// without it are assumed to be their own authorization server.
+func Discover(ctx context.Context, serverURL string) (*Metadata, error) {
+	server, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mcp server url: %w", err)
+	}
+	origin := server.Scheme + "://" + server.Host
+
+	md := &Metadata{Resource: serverURL, Issuer: origin}
+
+	var candidates []string
+	if u := resourceMetadataURL(ctx, serverURL); u != "" {
+		candidates = append(candidates, u)
+	}
+	if path := strings.TrimSuffix(server.Path, "/"); path != "" {
+		candidates = append(candidates, origin+"/.well-known/oauth-protected-resource"+path)
+	}
+	candidates = append(candidates, origin+"/.well-known/oauth-protected-resource")
+
+	for _, u := range candidates {
+		var pr protectedResource
+		if err := getJSON(ctx, u, &pr); err != nil {
+			continue
+		}
+		if len(pr.AuthorizationServers) > 0 {
+			md.Issuer = pr.AuthorizationServers[0]
+		}
+		md.Scopes = pr.ScopesSupported
+		break
+	}
+
+	as, err := discoverAuthorizationServer(ctx, md.Issuer)
+	switch {
+	case err == nil:
+		if !slices.Contains(as.CodeChallengeMethodsSupported, "S256") && len(as.CodeChallengeMethodsSupported) > 0 {
+			return nil, fmt.Errorf("authorization server %s does not support PKCE with S256", md.Issuer)
+		}
+		md.AuthorizationEndpoint = as.AuthorizationEndpoint
+		md.TokenEndpoint = as.TokenEndpoint
+		md.RegistrationEndpoint = as.RegistrationEndpoint
+		if len(md.Scopes) == 0 {
+			md.Scopes = as.ScopesSupported
+		}
+	case md.Issuer == origin:
+		// Servers from before the metadata discovery spec use the default
+		// endpoints.
+		md.AuthorizationEndpoint = origin + "/authorize"
+		md.TokenEndpoint = origin + "/token"
+		md.RegistrationEndpoint = origin + "/register"
+	default:
+		return nil, err
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" {
+		return nil, fmt.Errorf("authorization server %s has no authorization or token endpoint", md.Issuer)
+	}
+	return md, nil
+}
+
+// discoverAuthorizationServer fetches the metadata of the authorization
+// server issuer from its OAuth or OpenID Connect well-known location.
+func discoverAuthorizationServer(ctx context.Context, issuer string) (*authorizationServer, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization server url: %w", err)
+	}
+	origin := u.Scheme + "://" + u.Host
+	path := strings.TrimSuffix(u.Path, "/")
+
+	candidates := []string{origin + "/.well-known/oauth-authorization-server" + path}
+	if path != "" {
+		candidates = append(candidates,
+			origin+"/.well-known/openid-configuration"+path,
+			origin+path+"/.well-known/openid-configuration",
+		)
+	} else {
+		candidates = append(candidates, origin+"/.well-known/openid-configuration")
+	}
+
+	var errs []error
+	for _, c := range candidates {
+		var as authorizationServer
+		if err := getJSON(ctx, c, &as); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return &as, nil
+	}
+	return nil, fmt.Errorf("discovering authorization server %s: %w", issuer, errors.Join(errs...))
+}
+
+// resourceMetadataURL returns the resource_metadata parameter of the
+// WWW-Authenticate header of an unauthenticated request to serverURL.
+func resourceMetadataURL(ctx context.Context, serverURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return ""
+	}
+	for _, h := range resp.Header.Values("WWW-Authenticate") {
+		if v := authParam(h, "resource_metadata"); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// authParam returns the value of the auth parameter name of a
+// WWW-Authenticate header.
+func authParam(header, name string) string {
+	for part := range strings.SplitSeq(header, ",") {
+		part = strings.TrimSpace(part)
+		// The first parameter follows the scheme.
+		if i := strings.IndexByte(part, ' '); i >= 0 && !strings.Contains(part[:i], "=") {
+			part = strings.TrimSpace(part[i+1:])
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), name) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return ""
+}
+
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	return nil
+}
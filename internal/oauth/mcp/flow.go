@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/oauth"
+	"golang.org/x/oauth2"
+)
+
+// Client holds the credentials of a client registered with an authorization
+// server.
+type Client struct {
+	ID          string
+	Secret      string
+	RedirectURI string
+}
+
+// Register registers Crush as a public client with redirectURI, using OAuth
+// 2.0 dynamic client registration, RFC 7591.
+func Register(ctx context.Context, md *Metadata, redirectURI string) (*Client, error) {
+	if md.RegistrationEndpoint == "" {
+		return nil, errors.New("authorization server does not support dynamic client registration, set oauth.client_id in the mcp config")
+	}
+	body, err := json.Marshal(map[string]any{
+		"client_name":                "Crush",
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.RegistrationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("client registration failed: %s: %s", resp.Status, data)
+	}
+
+	var registered struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.Unmarshal(data, &registered); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if registered.ClientID == "" {
+		return nil, errors.New("client registration returned no client_id")
+	}
+	return &Client{
+		ID:          registered.ClientID,
+		Secret:      registered.ClientSecret,
+		RedirectURI: redirectURI,
+	}, nil
+}
+
+// Listen starts listening for the authorization callback on a loopback
+// address. The port of redirectURI is reused when it's free, so a registered
+// client can be used again; any port is used otherwise.
+func Listen(redirectURI string) (net.Listener, string, error) {
+	if u, err := url.Parse(redirectURI); err == nil && u.Host != "" {
+		if l, err := net.Listen("tcp", u.Host); err == nil {
+			return l, redirectURI, nil
+		}
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", fmt.Errorf("listen for authorization callback: %w", err)
+	}
+	return l, fmt.Sprintf("http://%s/callback", l.Addr()), nil
+}
+
+// Authorize runs the authorization code flow with PKCE: it calls open with
+// the authorization URL, waits for the redirect on l and exchanges the code
+// for a token.
+func Authorize(ctx context.Context, md *Metadata, client *Client, l net.Listener, open func(url string) error) (*oauth.Token, error) {
+	conf := config(md, client)
+	verifier := oauth2.GenerateVerifier()
+	state := rand.Text()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	srv := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			var res result
+			switch {
+			case q.Get("state") != state:
+				// Not our callback, e.g. a favicon request.
+				http.NotFound(w, r)
+				return
+			case q.Get("error") != "":
+				res.err = fmt.Errorf("authorization failed: %s %s", q.Get("error"), q.Get("error_description"))
+			default:
+				res.code = q.Get("code")
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			msg := "Authorization complete, you can close this window and return to Crush."
+			if res.err != nil {
+				msg = res.err.Error()
+			}
+			fmt.Fprintf(w, "<!doctype html><html><body><p>%s</p></body></html>", html.EscapeString(msg))
+			select {
+			case results <- res:
+			default:
+			}
+		}),
+	}
+	go srv.Serve(l) //nolint:errcheck
+	defer srv.Close()
+
+	authURL := conf.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("resource", md.Resource),
+	)
+	if err := open(authURL); err != nil {
+		return nil, err
+	}
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	tok, err := conf.Exchange(
+		ctx,
+		res.code,
+		oauth2.VerifierOption(verifier),
+		oauth2.SetAuthURLParam("resource", md.Resource),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+	return toToken(tok), nil
+}
+
+// Refresh exchanges the refresh token of token for a new token at
+// tokenURL.
+func Refresh(ctx context.Context, tokenURL string, client *Client, token *oauth.Token) (*oauth.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errors.New("no refresh token")
+	}
+	conf := config(&Metadata{TokenEndpoint: tokenURL}, client)
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	// An expired token makes the token source refresh it.
+	tok, err := conf.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: token.RefreshToken,
+		Expiry:       time.Unix(1, 0),
+	}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("refresh token: %w", err)
+	}
+	newToken := toToken(tok)
+	if newToken.RefreshToken == "" {
+		newToken.RefreshToken = token.RefreshToken
+	}
+	return newToken, nil
+}
+
+func config(md *Metadata, client *Client) *oauth2.Config {
+	// Public clients identify themselves with the client_id parameter.
+	authStyle := oauth2.AuthStyleAutoDetect
+	if client.Secret == "" {
+		authStyle = oauth2.AuthStyleInParams
+	}
+	return &oauth2.Config{
+		ClientID:     client.ID,
+		ClientSecret: client.Secret,
+		RedirectURL:  client.RedirectURI,
+		Scopes:       md.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   md.AuthorizationEndpoint,
+			TokenURL:  md.TokenEndpoint,
+			AuthStyle: authStyle,
+		},
+	}
+}
+
+// toToken converts tok to a token, one without an expiry has an ExpiresAt of
+// zero.
+func toToken(tok *oauth2.Token) *oauth.Token {
+	token := &oauth.Token{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+	}
+	if !tok.Expiry.IsZero() {
+		token.ExpiresAt = tok.Expiry.Unix()
+		token.SetExpiresIn()
+	}
+	return token
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/oauth"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthServer returns an MCP server that is its own authorization server
+// and only accepts the token it issues.
+func newAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="`+srv.URL+`/meta/resource"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/meta/resource", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"resource":              srv.URL + "/mcp",
+			"authorization_servers": []string{srv.URL + "/auth"},
+			"scopes_supported":      []string{"read"},
+		})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server/auth", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                           srv.URL + "/auth",
+			"authorization_endpoint":           srv.URL + "/auth/authorize",
+			"token_endpoint":                   srv.URL + "/auth/token",
+			"registration_endpoint":            srv.URL + "/auth/register",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/auth/register", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"client_id": "client-1"})
+	})
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client-1", r.Form.Get("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			require.Equal(t, "code-1", r.Form.Get("code"))
+			require.NotEmpty(t, r.Form.Get("code_verifier"))
+			require.Equal(t, srv.URL+"/mcp", r.Form.Get("resource"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "access-1",
+				"refresh_token": "refresh-1",
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+			})
+		case "refresh_token":
+			require.Equal(t, "refresh-1", r.Form.Get("refresh_token"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "access-2",
+				"token_type":   "Bearer",
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	srv := newAuthServer(t)
+	md, err := Discover(t.Context(), srv.URL+"/mcp")
+	require.NoError(t, err)
+	require.Equal(t, &Metadata{
+		Resource:              srv.URL + "/mcp",
+		Issuer:                srv.URL + "/auth",
+		AuthorizationEndpoint: srv.URL + "/auth/authorize",
+		TokenEndpoint:         srv.URL + "/auth/token",
+		RegistrationEndpoint:  srv.URL + "/auth/register",
+		Scopes:                []string{"read"},
+	}, md)
+}
+
+func TestDiscoverDefaultEndpoints(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	md, err := Discover(t.Context(), srv.URL+"/mcp")
+	require.NoError(t, err)
+	require.Equal(t, srv.URL+"/authorize", md.AuthorizationEndpoint)
+	require.Equal(t, srv.URL+"/token", md.TokenEndpoint)
+	require.Equal(t, srv.URL+"/register", md.RegistrationEndpoint)
+}
+
+func TestAuthorizeAndRefresh(t *testing.T) {
+	t.Parallel()
+
+	srv := newAuthServer(t)
+	md, err := Discover(t.Context(), srv.URL+"/mcp")
+	require.NoError(t, err)
+
+	l, redirectURI, err := Listen("")
+	require.NoError(t, err)
+	defer l.Close()
+
+	client, err := Register(t.Context(), md, redirectURI)
+	require.NoError(t, err)
+	require.Equal(t, "client-1", client.ID)
+
+	// Play the browser: check the authorization request and redirect back.
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		q := u.Query()
+		require.Equal(t, "S256", q.Get("code_challenge_method"))
+		require.Equal(t, srv.URL+"/mcp", q.Get("resource"))
+		require.Equal(t, "read", q.Get("scope"))
+
+		callback := q.Get("redirect_uri") + "?code=code-1&state=" + url.QueryEscape(q.Get("state"))
+		go func() {
+			resp, err := http.Get(callback)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	token, err := Authorize(t.Context(), md, client, l, open)
+	require.NoError(t, err)
+	require.Equal(t, "access-1", token.AccessToken)
+	require.Equal(t, "refresh-1", token.RefreshToken)
+	require.False(t, token.IsExpired())
+
+	refreshed, err := Refresh(t.Context(), md.TokenEndpoint, client, token)
+	require.NoError(t, err)
+	require.Equal(t, &oauth.Token{AccessToken: "access-2", RefreshToken: "refresh-1"}, refreshed)
+}
+
+func TestAuthParam(t *testing.T) {
+	t.Parallel()
+
+	header := `Bearer error="invalid_token", resource_metadata="https://example.com/.well-known/oauth-protected-resource"`
+	require.Equal(t, "https://example.com/.well-known/oauth-protected-resource", authParam(header, "resource_metadata"))
+	require.Equal(t, "invalid_token", authParam(header, "error"))
+	require.Empty(t, authParam(header, "scope"))
+}
@@ -51,6 +51,7 @@ type Service interface {
 	AutoApproveSession(sessionID string)
 	SetSkipRequests(skip bool)
 	SkipRequests() bool
+	SetAllowedTools(tools []string)
 	SubscribeNotifications(ctx context.Context) <-chan pubsub.Event[PermissionNotification]
 }
 
@@ -66,6 +67,7 @@ type permissionService struct {
 	autoApproveSessionsMu sync.RWMutex
 	skip                  bool
 	allowedTools          []string
+	allowedToolsMu        sync.RWMutex
 
 	// used to make sure we only process one request at a time
 	requestMu       sync.Mutex
@@ -143,7 +145,10 @@ func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRe
 
 	// Check if the tool/action combination is in the allowlist
 	commandKey := opts.ToolName + ":" + opts.Action
-	if slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName) {
+	s.allowedToolsMu.RLock()
+	allowed := slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName)
+	s.allowedToolsMu.RUnlock()
+	if allowed {
 		return true, nil
 	}
 
@@ -233,6 +238,14 @@ func (s *permissionService) SkipRequests() bool {
 	return s.skip
 }
 
+// SetAllowedTools replaces the tools and commands that don't need
+// permission.
+func (s *permissionService) SetAllowedTools(tools []string) {
+	s.allowedToolsMu.Lock()
+	s.allowedTools = tools
+	s.allowedToolsMu.Unlock()
+}
+
 func NewPermissionService(workingDir string, skip bool, allowedTools []string) Service {
 	return &permissionService{
 		Broker:              pubsub.NewBroker[PermissionRequest](),
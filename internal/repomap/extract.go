@@ -0,0 +1,224 @@
+package repomap
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Symbol is a top-level declaration of a file.
+type Symbol struct {
+	Name      string
+	Signature string
+	Line      int
+}
+
+// extractor extracts the symbols of a file.
+type extractor func(content []byte) []Symbol
+
+// extractorFor returns the extractor for the given file, or nil if the
+// language isn't supported.
+func extractorFor(path string) extractor {
+	ext := strings.ToLower(filepath.Ext(path))
+	if strings.HasSuffix(path, "_test.go") {
+		// Tests are rarely useful to get to know a codebase.
+		return nil
+	}
+	if ext == ".go" {
+		return extractGo
+	}
+	if patterns, ok := regexExtractors[ext]; ok {
+		return func(content []byte) []Symbol {
+			return extractRegex(content, patterns)
+		}
+	}
+	return nil
+}
+
+// extractGo extracts functions, methods and types using go/parser.
+func extractGo(content []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil && file == nil {
+		return nil
+	}
+
+	var symbols []Symbol
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			fn := *decl
+			fn.Body = nil
+			fn.Doc = nil
+			symbols = append(symbols, Symbol{
+				Name:      decl.Name.Name,
+				Signature: printNode(fset, &fn),
+				Line:      fset.Position(decl.Pos()).Line,
+			})
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				symbols = append(symbols, Symbol{
+					Name:      ts.Name.Name,
+					Signature: "type " + ts.Name.Name + typeParams(fset, ts) + " " + typeKind(fset, ts),
+					Line:      fset.Position(ts.Pos()).Line,
+				})
+			}
+		}
+	}
+	return symbols
+}
+
+func typeParams(fset *token.FileSet, ts *ast.TypeSpec) string {
+	if ts.TypeParams == nil {
+		return ""
+	}
+	params := printNode(fset, &ast.FuncType{Params: ts.TypeParams})
+	// Printed as "func(T any)"; keep the parameter list only.
+	return "[" + strings.TrimSuffix(strings.TrimPrefix(params, "func("), ")") + "]"
+}
+
+// typeKind describes a type without its fields or methods, which would make
+// the map too long.
+func typeKind(fset *token.FileSet, ts *ast.TypeSpec) string {
+	prefix := ""
+	if ts.Assign.IsValid() {
+		prefix = "= "
+	}
+	switch ts.Type.(type) {
+	case *ast.StructType:
+		return prefix + "struct"
+	case *ast.InterfaceType:
+		return prefix + "interface"
+	default:
+		return prefix + printNode(fset, ts.Type)
+	}
+}
+
+func printNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// regexExtractors maps file extensions to patterns matching declarations.
+// Each pattern must have a "name" group.
+var regexExtractors = func() map[string][]*regexp.Regexp {
+	compile := func(patterns ...string) []*regexp.Regexp {
+		res := make([]*regexp.Regexp, len(patterns))
+		for i, p := range patterns {
+			res[i] = regexp.MustCompile(p)
+		}
+		return res
+	}
+
+	python := compile(
+		`^class\s+(?P<name>\w+)`,
+		`^(?:    )?(?:async\s+)?def\s+(?P<name>\w+)\s*\(`,
+	)
+	javascript := compile(
+		`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(?P<name>\w+)\s*[<(]`,
+		`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>\w+)`,
+		`^(?:export\s+)?(?:declare\s+)?(?:interface|type|enum)\s+(?P<name>\w+)`,
+		`^(?:export\s+)?const\s+(?P<name>\w+)\s*(?::[^=]+)?=\s*(?:async\s*)?(?:\([^)]*\)|\w+)\s*=>`,
+	)
+	rust := compile(
+		`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(?P<name>\w+)`,
+		`^(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|trait|type|union)\s+(?P<name>\w+)`,
+	)
+	java := compile(
+		`^\s*(?:(?:public|protected|private|abstract|final|static|sealed|data|open|internal)\s+)*(?:class|interface|enum|record|object)\s+(?P<name>\w+)`,
+		`^\s+(?:(?:public|protected|private|abstract|final|static|synchronized|override|suspend)\s+)+[\w<>\[\], ?]*?\s*(?:fun\s+)?(?P<name>\w+)\s*\(`,
+	)
+	ruby := compile(
+		`^\s*(?:class|module)\s+(?P<name>[\w:]+)`,
+		`^\s*def\s+(?P<name>(?:self\.)?[\w?!=]+)`,
+	)
+	c := compile(
+		`^(?:typedef\s+)?(?:struct|enum|union|class)\s+(?P<name>\w+)\s*[{:]`,
+		`^(?:static\s+|inline\s+|extern\s+)*[\w:<>*&]+(?:\s+[\w:<>*&]+)*\s+\**(?P<name>[\w:~]+)\s*\([^;]*$`,
+	)
+
+	return map[string][]*regexp.Regexp{
+		".py":    python,
+		".js":    javascript,
+		".jsx":   javascript,
+		".mjs":   javascript,
+		".cjs":   javascript,
+		".ts":    javascript,
+		".tsx":   javascript,
+		".mts":   javascript,
+		".rs":    rust,
+		".java":  java,
+		".kt":    java,
+		".cs":    java,
+		".scala": java,
+		".rb":    ruby,
+		".c":     c,
+		".h":     c,
+		".cc":    c,
+		".cpp":   c,
+		".hpp":   c,
+	}
+}()
+
+func extractRegex(content []byte, patterns []*regexp.Regexp) []Symbol {
+	var symbols []Symbol
+	for i, line := range strings.Split(string(content), "\n") {
+		if len(line) > 300 {
+			continue
+		}
+		for _, re := range patterns {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			symbols = append(symbols, Symbol{
+				Name:      m[re.SubexpIndex("name")],
+				Signature: signatureOf(line),
+				Line:      i + 1,
+			})
+			break
+		}
+	}
+	return symbols
+}
+
+// signatureOf trims a declaration line down to its signature.
+func signatureOf(line string) string {
+	line = strings.TrimSpace(line)
+	if i := strings.Index(line, "{"); i > 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ":"))
+	const maxLen = 120
+	if utf8.RuneCountInString(line) > maxLen {
+		line = string([]rune(line)[:maxLen]) + "…"
+	}
+	return line
+}
+
+var identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{2,}`)
+
+// identifiers returns the set of identifiers used in content.
+func identifiers(content []byte) map[string]struct{} {
+	ids := make(map[string]struct{})
+	for _, id := range identifierRe.FindAll(content, -1) {
+		ids[string(id)] = struct{}{}
+	}
+	return ids
+}
@@ -0,0 +1,223 @@
+// Package repomap builds a ranked outline of the symbols of a repository, to
+// help the model orient itself in unfamiliar codebases.
+package repomap
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/fsext"
+)
+
+const (
+	// DefaultMaxTokens is the default token budget of a map.
+	DefaultMaxTokens = 4096
+
+	maxFiles    = 5000
+	maxFileSize = 512 * 1024
+
+	// recentFiles is the number of most recently modified files that get
+	// a ranking boost, if modified within recentWindow.
+	recentFiles  = 20
+	recentWindow = 24 * time.Hour
+)
+
+// Options configures [Build].
+type Options struct {
+	// Path limits the map to a directory of the repository. Defaults to
+	// the root.
+	Path string
+	// MaxTokens is the token budget of the map.
+	MaxTokens int
+}
+
+// File is a file of the map and its symbols.
+type File struct {
+	Path    string
+	Symbols []Symbol
+	Refs    int
+	ModTime time.Time
+	score   int
+}
+
+// cachedFile holds the parsed symbols and identifiers of a file, valid as
+// long as its modification time and size don't change.
+type cachedFile struct {
+	modTime     time.Time
+	size        int64
+	symbols     []Symbol
+	identifiers map[string]struct{}
+}
+
+var cache = csync.NewMap[string, cachedFile]()
+
+// Build builds the map of the repository at root and renders it within the
+// token budget.
+func Build(ctx context.Context, root string, opts Options) (string, error) {
+	files, err := Rank(ctx, root, opts.Path)
+	if err != nil {
+		return "", err
+	}
+	return Render(files, cmp.Or(opts.MaxTokens, DefaultMaxTokens)), nil
+}
+
+// Rank parses the supported files under dir, relative to root, and returns
+// the ones with symbols, most relevant first.
+//
+// Files are ranked by how many other files reference their symbols, with a
+// boost for the ones modified in the last day, as those are likely to be
+// what the user is working on.
+func Rank(ctx context.Context, root, dir string) ([]File, error) {
+	searchPath := root
+	if dir != "" {
+		searchPath = dir
+		if !filepath.IsAbs(searchPath) {
+			searchPath = filepath.Join(root, dir)
+		}
+	}
+
+	paths, _, err := fsext.ListDirectory(searchPath, nil, 0, maxFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var (
+		files []File
+		ids   = make(map[string]map[string]struct{}, len(paths))
+	)
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(path, string(filepath.Separator)) {
+			continue
+		}
+		extract := extractorFor(path)
+		if extract == nil {
+			continue
+		}
+		parsed, ok := parse(path, extract)
+		if !ok {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+		ids[rel] = parsed.identifiers
+		if len(parsed.symbols) == 0 {
+			continue
+		}
+		files = append(files, File{
+			Path:    rel,
+			Symbols: parsed.symbols,
+			ModTime: parsed.modTime,
+		})
+	}
+
+	// Count, for each file, how many other files use its symbols.
+	definedIn := make(map[string][]int)
+	for i, f := range files {
+		for _, sym := range f.Symbols {
+			if defs := definedIn[sym.Name]; len(defs) == 0 || defs[len(defs)-1] != i {
+				definedIn[sym.Name] = append(defs, i)
+			}
+		}
+	}
+	for path, used := range ids {
+		for id := range used {
+			for _, i := range definedIn[id] {
+				if files[i].Path != path {
+					files[i].Refs++
+				}
+			}
+		}
+	}
+	for i := range files {
+		files[i].score = files[i].Refs
+	}
+
+	var recent []File
+	for _, f := range files {
+		if time.Since(f.ModTime) < recentWindow {
+			recent = append(recent, f)
+		}
+	}
+	slices.SortFunc(recent, func(a, b File) int {
+		return b.ModTime.Compare(a.ModTime)
+	})
+	boost := make(map[string]int, recentFiles)
+	for i, f := range recent[:min(recentFiles, len(recent))] {
+		boost[f.Path] = (recentFiles - i) * 2
+	}
+	for i := range files {
+		files[i].score += boost[files[i].Path]
+	}
+
+	slices.SortStableFunc(files, func(a, b File) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			strings.Compare(a.Path, b.Path),
+		)
+	})
+	return files, nil
+}
+
+// parse returns the symbols and identifiers of the file at path, from the
+// cache if the file didn't change.
+func parse(path string, extract extractor) (cachedFile, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFileSize {
+		return cachedFile{}, false
+	}
+	if cached, ok := cache.Get(path); ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, true
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cachedFile{}, false
+	}
+	parsed := cachedFile{
+		modTime:     info.ModTime(),
+		size:        info.Size(),
+		symbols:     extract(content),
+		identifiers: identifiers(content),
+	}
+	cache.Set(path, parsed)
+	return parsed, true
+}
+
+// Render renders files as an outline that fits within maxTokens. Files that
+// don't fit are left out, and a note with the number of omitted files is
+// added.
+func Render(files []File, maxTokens int) string {
+	budget := maxTokens * 4 // ~4 characters per token.
+	var sb strings.Builder
+	omitted := 0
+	for _, f := range files {
+		var entry strings.Builder
+		entry.WriteString(f.Path)
+		entry.WriteString(":\n")
+		for _, sym := range f.Symbols {
+			fmt.Fprintf(&entry, "  %d: %s\n", sym.Line, sym.Signature)
+		}
+		if sb.Len()+entry.Len() > budget {
+			omitted++
+			continue
+		}
+		sb.WriteString(entry.String())
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "\n[%d less relevant files omitted to fit the token budget]\n", omitted)
+	}
+	return sb.String()
+}
@@ -0,0 +1,159 @@
+package repomap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractGo(t *testing.T) {
+	t.Parallel()
+
+	symbols := extractGo([]byte(`package foo
+
+// Store stores things.
+type Store[T any] struct {
+	items []T
+}
+
+type Getter interface {
+	Get(key string) (string, error)
+}
+
+type ID = string
+
+func NewStore[T any]() *Store[T] {
+	return &Store[T]{}
+}
+
+func (s *Store[T]) Add(item T) {
+	s.items = append(s.items, item)
+}
+`))
+	require.Equal(t, []Symbol{
+		{Name: "Store", Signature: "type Store[T any] struct", Line: 4},
+		{Name: "Getter", Signature: "type Getter interface", Line: 8},
+		{Name: "ID", Signature: "type ID = string", Line: 12},
+		{Name: "NewStore", Signature: "func NewStore[T any]() *Store[T]", Line: 14},
+		{Name: "Add", Signature: "func (s *Store[T]) Add(item T)", Line: 18},
+	}, symbols)
+}
+
+func TestExtractRegex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			path: "app.py",
+			content: `import os
+
+class Server:
+    def start(self, port: int) -> None:
+        pass
+
+async def main():
+    pass
+`,
+			want: []string{"class Server", "def start(self, port: int) -> None", "async def main()"},
+		},
+		{
+			path: "index.ts",
+			content: `export interface Props {
+  name: string;
+}
+export default function App(props: Props) {
+}
+export const useThing = async (id: string) => {
+}
+class Internal {}
+`,
+			want: []string{"export interface Props", "export default function App(props: Props)", "export const useThing = async (id: string) =>", "class Internal"},
+		},
+		{
+			path: "lib.rs",
+			content: `pub struct Config {
+}
+impl Config {
+    pub fn new(path: &str) -> Result<Self, Error> {
+    }
+}
+`,
+			want: []string{"pub struct Config", "pub fn new(path: &str) -> Result<Self, Error>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			extract := extractorFor(tt.path)
+			require.NotNil(t, extract)
+			var got []string
+			for _, sym := range extract([]byte(tt.content)) {
+				got = append(got, sym.Signature)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	require.Nil(t, extractorFor("README.md"))
+	require.Nil(t, extractorFor("foo_test.go"))
+}
+
+func TestSignatureOf(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "func main()", signatureOf("func main() {"))
+	long := signatureOf("def " + strings.Repeat("é", 200) + "():")
+	require.True(t, utf8.ValidString(long))
+	require.Equal(t, 121, utf8.RuneCountInString(long))
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	write := func(path, content string, modTime time.Time) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(full, modTime, modTime))
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	write("core/core.go", "package core\n\nfunc Widely() {}\n", old)
+	write("a/a.go", "package a\n\nfunc A() { core.Widely() }\n", old)
+	write("b/b.go", "package b\n\nfunc B() { core.Widely() }\n", old)
+	write("c/c.go", "package c\n\nfunc C() { core.Widely() }\n", old)
+	write("ignored/gen.go", "package ignored\n\nfunc Generated() {}\n", old)
+	write(".gitignore", "ignored/\n", old)
+
+	files, err := Rank(t.Context(), root, "")
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	require.Equal(t, "core/core.go", files[0].Path)
+	require.Equal(t, 3, files[0].Refs)
+
+	// Recent edits are ranked higher.
+	write("c/c.go", "package c\n\nfunc C() { core.Widely() }\n\nfunc New() {}\n", time.Now())
+	files, err = Rank(t.Context(), root, "")
+	require.NoError(t, err)
+	require.Equal(t, "c/c.go", files[0].Path)
+	require.Len(t, files[0].Symbols, 2, "cache must be invalidated")
+
+	out, err := Build(t.Context(), root, Options{Path: "core"})
+	require.NoError(t, err)
+	require.Equal(t, "core/core.go:\n  3: func Widely()\n", out)
+
+	out = Render(files, 10)
+	require.True(t, strings.HasPrefix(out, "c/c.go:\n"))
+	require.Contains(t, out, "[3 less relevant files omitted to fit the token budget]")
+}
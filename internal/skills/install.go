@@ -0,0 +1,300 @@
+package skills
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxArchiveSize is the maximum size of the files extracted from a skill
+// archive.
+const MaxArchiveSize = 100 << 20
+
+var (
+	ErrSkillExists   = errors.New("skill already installed")
+	ErrSkillNotFound = errors.New("skill not found")
+)
+
+// Install copies the skill at src, a directory or a .zip, .tar, .tar.gz or
+// .tgz archive, into dir. The skill is validated before being installed, and
+// an installed skill with the same name is only replaced when force is set.
+func Install(src, dir string, force bool) (*Skill, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+
+	root := src
+	if !info.IsDir() {
+		tmp, err := os.MkdirTemp("", "crush-skill-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		if err := extract(src, tmp); err != nil {
+			return nil, fmt.Errorf("extracting %s: %w", src, err)
+		}
+		if root, err = findRoot(tmp); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(filepath.Join(root, SkillFileName)); err != nil {
+		if root, err = findRoot(src); err != nil {
+			return nil, err
+		}
+	}
+
+	skill, err := Parse(filepath.Join(root, SkillFileName))
+	if err != nil {
+		return nil, err
+	}
+	// The directory name of the source doesn't matter, the skill is
+	// installed in a directory named after it.
+	skill.Path = ""
+	if err := skill.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid skill: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating skills directory: %w", err)
+	}
+	dest := filepath.Join(dir, skill.Name)
+	if _, err := os.Stat(dest); err == nil && !force {
+		return nil, fmt.Errorf("%w: %s", ErrSkillExists, dest)
+	}
+
+	// Copy to a sibling directory first so a failed copy doesn't leave a
+	// half installed skill behind.
+	tmp, err := os.MkdirTemp(dir, "."+skill.Name+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyDir(root, tmp); err != nil {
+		return nil, fmt.Errorf("copying skill: %w", err)
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, fmt.Errorf("removing installed skill: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return nil, fmt.Errorf("installing skill: %w", err)
+	}
+
+	return Parse(filepath.Join(dest, SkillFileName))
+}
+
+// Remove deletes the skill named name from dir.
+func Remove(dir, name string) error {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return fmt.Errorf("invalid skill name %q", name)
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(filepath.Join(path, SkillFileName)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrSkillNotFound, path)
+		}
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// findRoot returns the only directory under dir holding a SKILL.md file.
+func findRoot(dir string) (string, error) {
+	var roots []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == SkillFileName {
+			roots = append(roots, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	switch len(roots) {
+	case 0:
+		return "", fmt.Errorf("no %s found in %s", SkillFileName, dir)
+	case 1:
+		return roots[0], nil
+	default:
+		return "", fmt.Errorf("more than one %s found in %s", SkillFileName, dir)
+	}
+}
+
+// copyDir copies the regular files and directories of src into dst, skipping
+// .git directories and symlinks.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, in, perm, nil)
+}
+
+// writeFile writes r to path, counting the bytes against remaining when it
+// isn't nil.
+func writeFile(path string, r io.Reader, perm fs.FileMode, remaining *int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if remaining == nil {
+		if _, err := io.Copy(out, r); err != nil {
+			return err
+		}
+		return out.Close()
+	}
+	n, err := io.Copy(out, io.LimitReader(r, *remaining+1))
+	if err != nil {
+		return err
+	}
+	if *remaining -= n; *remaining < 0 {
+		return fmt.Errorf("archive exceeds %d bytes", MaxArchiveSize)
+	}
+	return out.Close()
+}
+
+// extract extracts the archive at src into dst.
+func extract(src, dst string) error {
+	name := strings.ToLower(src)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return extractZip(src, dst)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		f, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(gz, dst)
+	case strings.HasSuffix(name, ".tar"):
+		f, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return extractTar(f, dst)
+	default:
+		return errors.New("unsupported archive, use a directory or a .zip, .tar, .tar.gz or .tgz file")
+	}
+}
+
+// archivePath returns where the archive entry name is extracted in dst,
+// refusing entries that would end up outside of it.
+func archivePath(dst, name string) (string, error) {
+	path := filepath.Join(dst, filepath.FromSlash(name))
+	if path != dst && !strings.HasPrefix(path, dst+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path in archive: %s", name)
+	}
+	return path, nil
+}
+
+func extractZip(src, dst string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	remaining := int64(MaxArchiveSize)
+	for _, f := range r.File {
+		path, err := archivePath(dst, f.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case f.FileInfo().IsDir():
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case f.Mode().IsRegular():
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeFile(path, rc, f.Mode().Perm(), &remaining)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	remaining := int64(MaxArchiveSize)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path, err := archivePath(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, fs.FileMode(hdr.Mode).Perm(), &remaining); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package skills
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSkill = "---\nname: my-skill\ndescription: A skill for testing.\n---\n# My Skill\n"
+
+func TestInstallDirectory(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join(t.TempDir(), "checkout")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "scripts"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, SkillFileName), []byte(testSkill), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "scripts", "run.sh"), []byte("echo hi"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0o644))
+
+	dir := t.TempDir()
+	skill, err := Install(src, dir, false)
+	require.NoError(t, err)
+	require.Equal(t, "my-skill", skill.Name)
+	require.Equal(t, filepath.Join(dir, "my-skill"), skill.Path)
+	require.NoError(t, skill.Validate())
+	require.FileExists(t, filepath.Join(dir, "my-skill", "scripts", "run.sh"))
+	require.NoDirExists(t, filepath.Join(dir, "my-skill", ".git"))
+
+	_, err = Install(src, dir, false)
+	require.ErrorIs(t, err, ErrSkillExists)
+	_, err = Install(src, dir, true)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary directories left behind")
+
+	require.NoError(t, Remove(dir, "my-skill"))
+	require.NoDirExists(t, filepath.Join(dir, "my-skill"))
+	require.ErrorIs(t, Remove(dir, "my-skill"), ErrSkillNotFound)
+	require.Error(t, Remove(dir, ".."))
+}
+
+func TestInstallInvalid(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, SkillFileName), []byte("---\nname: Bad--Name\n---\n"), 0o644))
+
+	dir := t.TempDir()
+	_, err := Install(src, dir, false)
+	require.ErrorContains(t, err, "invalid skill")
+	require.NoDirExists(t, filepath.Join(dir, "Bad--Name"))
+}
+
+func TestInstallZip(t *testing.T) {
+	t.Parallel()
+
+	archive := filepath.Join(t.TempDir(), "skill.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("my-skill-main/SKILL.md")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(testSkill))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	dir := t.TempDir()
+	skill, err := Install(archive, dir, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "my-skill"), skill.Path)
+}
+
+func TestInstallTarGz(t *testing.T) {
+	t.Parallel()
+
+	writeTar := func(t *testing.T, name, content string) string {
+		archive := filepath.Join(t.TempDir(), "skill.tar.gz")
+		f, err := os.Create(archive)
+		require.NoError(t, err)
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		require.NoError(t, f.Close())
+		return archive
+	}
+
+	dir := t.TempDir()
+	skill, err := Install(writeTar(t, "SKILL.md", testSkill), dir, false)
+	require.NoError(t, err)
+	require.Equal(t, "my-skill", skill.Name)
+
+	_, err = Install(writeTar(t, "../SKILL.md", testSkill), t.TempDir(), false)
+	require.ErrorContains(t, err, "invalid path in archive")
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -109,9 +110,20 @@ func splitFrontmatter(content string) (frontmatter, body string, err error) {
 	return before, after, nil
 }
 
-// Discover finds all valid skills in the given paths.
-func Discover(paths []string) []*Skill {
-	var skills []*Skill
+// Entry is a SKILL.md file found in the skills paths, valid or not.
+type Entry struct {
+	// Skill is nil when the file couldn't be parsed.
+	Skill *Skill
+	// Path is the path of the SKILL.md file.
+	Path string
+	// Err is why the skill can't be used, if it can't.
+	Err error
+}
+
+// Scan finds all SKILL.md files in the given paths, including the ones that
+// fail to parse or validate, sorted by path.
+func Scan(paths []string) []Entry {
+	var entries []Entry
 	var mu sync.Mutex
 	seen := make(map[string]bool)
 
@@ -119,7 +131,7 @@ func Discover(paths []string) []*Skill {
 		// We use fastwalk with Follow: true instead of filepath.WalkDir because
 		// WalkDir doesn't follow symlinked directories at any depth—only entry
 		// points. This ensures skills in symlinked subdirectories are discovered.
-		// fastwalk is concurrent, so we protect shared state (seen, skills) with mu.
+		// fastwalk is concurrent, so we protect shared state (seen, entries) with mu.
 		conf := fastwalk.Config{
 			Follow:  true,
 			ToSlash: fastwalk.DefaultToSlash(),
@@ -138,26 +150,54 @@ func Discover(paths []string) []*Skill {
 			}
 			seen[path] = true
 			mu.Unlock()
-			skill, err := Parse(path)
-			if err != nil {
-				slog.Warn("Failed to parse skill file", "path", path, "error", err)
-				return nil
-			}
-			if err := skill.Validate(); err != nil {
-				slog.Warn("Skill validation failed", "path", path, "error", err)
-				return nil
+
+			entry := Entry{Path: path}
+			entry.Skill, entry.Err = Parse(path)
+			if entry.Err == nil {
+				entry.Err = entry.Skill.Validate()
 			}
-			slog.Debug("Successfully loaded skill", "name", skill.Name, "path", path)
 			mu.Lock()
-			skills = append(skills, skill)
+			entries = append(entries, entry)
 			mu.Unlock()
 			return nil
 		})
 	}
 
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return entries
+}
+
+// Discover finds all valid skills in the given paths.
+func Discover(paths []string) []*Skill {
+	var skills []*Skill
+	for _, entry := range Scan(paths) {
+		if entry.Err != nil {
+			slog.Warn("Skipping invalid skill", "path", entry.Path, "error", entry.Err)
+			continue
+		}
+		slog.Debug("Successfully loaded skill", "name", entry.Skill.Name, "path", entry.Path)
+		skills = append(skills, entry.Skill)
+	}
 	return skills
 }
 
+// Filter returns the skills whose names are in allowed. A nil allowed list
+// allows every skill.
+func Filter(skills []*Skill, allowed []string) []*Skill {
+	if allowed == nil {
+		return skills
+	}
+	var filtered []*Skill
+	for _, s := range skills {
+		if slices.Contains(allowed, s.Name) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
 // ToPromptXML generates XML for injection into the system prompt.
 func ToPromptXML(skills []*Skill) string {
 	if len(skills) == 0 {
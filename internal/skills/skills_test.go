@@ -226,6 +226,42 @@ description: Name doesn't match directory.
 	require.True(t, names["skill-two"])
 }
 
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"good":     "---\nname: good\ndescription: A good skill.\n---\n",
+		"mismatch": "---\nname: other\ndescription: Wrong directory.\n---\n",
+		"broken":   "no frontmatter",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name, SkillFileName), []byte(content), 0o644))
+	}
+
+	entries := Scan([]string{tmpDir, tmpDir})
+	require.Len(t, entries, 3)
+
+	require.Equal(t, filepath.Join(tmpDir, "broken", SkillFileName), entries[0].Path)
+	require.Nil(t, entries[0].Skill)
+	require.Error(t, entries[0].Err)
+
+	require.Equal(t, "good", entries[1].Skill.Name)
+	require.NoError(t, entries[1].Err)
+
+	require.Equal(t, "other", entries[2].Skill.Name)
+	require.ErrorContains(t, entries[2].Err, "must match directory")
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	all := []*Skill{{Name: "a"}, {Name: "b"}}
+	require.Equal(t, all, Filter(all, nil))
+	require.Equal(t, []*Skill{{Name: "b"}}, Filter(all, []string{"b", "c"}))
+	require.Empty(t, Filter(all, []string{}))
+}
+
 func TestToPromptXML(t *testing.T) {
 	t.Parallel()
 
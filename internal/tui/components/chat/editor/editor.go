@@ -19,6 +19,7 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/memory"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
@@ -160,6 +161,11 @@ func (m *editorCmp) send() tea.Cmd {
 		return nil
 	}
 
+	if entry, ok := memory.ParseShortcut(value); ok && len(attachments) == 0 {
+		m.textarea.Reset()
+		return m.saveMemory(entry)
+	}
+
 	m.textarea.Reset()
 	m.attachments = nil
 	// Change the placeholder when sending a new message.
@@ -173,6 +179,17 @@ func (m *editorCmp) send() tea.Cmd {
 	)
 }
 
+// saveMemory saves entry in the project's memory file, without asking the
+// agent.
+func (m *editorCmp) saveMemory(entry string) tea.Cmd {
+	cfg := m.app.Config()
+	path := memory.Path(cfg.WorkingDir(), cfg.Options.InitializeAs, memory.ScopeProject)
+	if err := memory.Save(path, entry); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo(fmt.Sprintf("Saved to memory in %s", fsext.PrettyPath(path)))
+}
+
 func (m *editorCmp) repositionCompletions() tea.Msg {
 	x, y := m.completionsPosition()
 	return completions.RepositionCompletionsMsg{X: x, Y: y}
@@ -112,6 +112,10 @@ func (h *header) details(availWidth int) string {
 		parts = append(parts, s.Error.Render(fmt.Sprintf("%s%d", styles.ErrorIcon, errorCount)))
 	}
 
+	if profile := config.Get().Profile(); profile != "" {
+		parts = append(parts, s.Subtle.Render("profile ")+s.Muted.Render(profile))
+	}
+
 	agentCfg := config.Get().Agents[config.AgentCoder]
 	model := config.Get().GetModelByType(agentCfg.Model)
 	percentage := (float64(h.session.CompletionTokens+h.session.PromptTokens) / float64(model.ContextWindow)) * 100
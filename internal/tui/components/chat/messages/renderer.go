@@ -188,6 +188,11 @@ func init() {
 	registry.register(tools.ViewToolName, func() renderer { return viewRenderer{} })
 	registry.register(tools.EditToolName, func() renderer { return editRenderer{} })
 	registry.register(tools.MultiEditToolName, func() renderer { return multiEditRenderer{} })
+	registry.register(tools.NotebookEditToolName, func() renderer { return notebookEditRenderer{} })
+	registry.register(tools.MoveToolName, func() renderer { return moveRenderer{} })
+	registry.register(tools.CopyToolName, func() renderer { return copyRenderer{} })
+	registry.register(tools.DeleteToolName, func() renderer { return deleteRenderer{} })
+	registry.register(tools.MemoryToolName, func() renderer { return memoryRenderer{} })
 	registry.register(tools.WriteToolName, func() renderer { return writeRenderer{} })
 	registry.register(tools.FetchToolName, func() renderer { return simpleFetchRenderer{} })
 	registry.register(tools.AgenticFetchToolName, func() renderer { return agenticFetchRenderer{} })
@@ -196,6 +201,7 @@ func init() {
 	registry.register(tools.GlobToolName, func() renderer { return globRenderer{} })
 	registry.register(tools.GrepToolName, func() renderer { return grepRenderer{} })
 	registry.register(tools.LSToolName, func() renderer { return lsRenderer{} })
+	registry.register(tools.RepoMapToolName, func() renderer { return repoMapRenderer{} })
 	registry.register(tools.SourcegraphToolName, func() renderer { return sourcegraphRenderer{} })
 	registry.register(tools.DiagnosticsToolName, func() renderer { return diagnosticsRenderer{} })
 	registry.register(tools.TodosToolName, func() renderer { return todosRenderer{} })
@@ -505,6 +511,109 @@ func (er editRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Notebook Edit renderer
+// -----------------------------------------------------------------------------
+
+// notebookEditRenderer handles notebook cell edits with diff visualization
+type notebookEditRenderer struct {
+	baseRenderer
+}
+
+// Render displays the edited notebook cell with a formatted diff of its source
+func (nr notebookEditRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.NotebookEditParams
+	var args []string
+	if err := nr.unmarshalParams(v.call.Input, &params); err == nil {
+		file := fsext.PrettyPath(params.FilePath)
+		args = newParamBuilder().
+			addMain(file).
+			addKeyValue("cell", fmt.Sprintf("%d", params.CellIndex)).
+			addKeyValue("mode", params.EditMode).
+			build()
+	}
+
+	return nr.renderWithParams(v, "Notebook Edit", args, func() string {
+		var meta tools.EditResponseMetadata
+		if err := nr.unmarshalParams(v.result.Metadata, &meta); err != nil {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		formatter := core.DiffFormatter().
+			Before(fsext.PrettyPath(params.FilePath), meta.OldContent).
+			After(fsext.PrettyPath(params.FilePath), meta.NewContent).
+			Width(v.textWidth() - 2) // -2 for padding
+		if v.textWidth() > 120 {
+			formatter = formatter.Split()
+		}
+		// add a message to the bottom if the content was truncated
+		formatted := formatter.String()
+		if lipgloss.Height(formatted) > responseContextHeight {
+			contentLines := strings.Split(formatted, "\n")
+			truncateMessage := t.S().Muted.
+				Background(t.BgBaseLighter).
+				PaddingLeft(2).
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("… (%d lines)", len(contentLines)-responseContextHeight))
+			formatted = strings.Join(contentLines[:responseContextHeight], "\n") + "\n" + truncateMessage
+		}
+		return formatted
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Memory renderer
+// -----------------------------------------------------------------------------
+
+// memoryRenderer handles memory entries with diff visualization
+type memoryRenderer struct {
+	baseRenderer
+}
+
+// Render displays the changed memory file with a formatted diff
+func (mr memoryRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.MemoryParams
+	var args []string
+	if err := mr.unmarshalParams(v.call.Input, &params); err == nil {
+		entry := cmp.Or(params.Entry, params.OldEntry)
+		args = newParamBuilder().
+			addMain(entry).
+			addKeyValue("action", params.Action).
+			addKeyValue("scope", params.Scope).
+			build()
+	}
+
+	return mr.renderWithParams(v, "Memory", args, func() string {
+		var meta tools.MemoryResponseMetadata
+		if err := mr.unmarshalParams(v.result.Metadata, &meta); err != nil {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		file := fsext.PrettyPath(meta.FilePath)
+		formatter := core.DiffFormatter().
+			Before(file, meta.OldContent).
+			After(file, meta.NewContent).
+			Width(v.textWidth() - 2) // -2 for padding
+		if v.textWidth() > 120 {
+			formatter = formatter.Split()
+		}
+		// add a message to the bottom if the content was truncated
+		formatted := formatter.String()
+		if lipgloss.Height(formatted) > responseContextHeight {
+			contentLines := strings.Split(formatted, "\n")
+			truncateMessage := t.S().Muted.
+				Background(t.BgBaseLighter).
+				PaddingLeft(2).
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("… (%d lines)", len(contentLines)-responseContextHeight))
+			formatted = strings.Join(contentLines[:responseContextHeight], "\n") + "\n" + truncateMessage
+		}
+		return formatted
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Multi-Edit renderer
 // -----------------------------------------------------------------------------
@@ -786,6 +895,73 @@ func (dr downloadRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Move, Copy and Delete renderers
+// -----------------------------------------------------------------------------
+
+// moveRenderer handles moving files and directories
+type moveRenderer struct {
+	baseRenderer
+}
+
+// Render displays the source and destination paths
+func (mr moveRenderer) Render(v *toolCallCmp) string {
+	var params tools.MoveParams
+	var args []string
+	if err := mr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(fsext.PrettyPath(params.SourcePath)).
+			addKeyValue("to", fsext.PrettyPath(params.DestinationPath)).
+			build()
+	}
+
+	return mr.renderWithParams(v, "Move", args, func() string {
+		return ""
+	})
+}
+
+// copyRenderer handles copying files and directories
+type copyRenderer struct {
+	baseRenderer
+}
+
+// Render displays the source and destination paths
+func (cr copyRenderer) Render(v *toolCallCmp) string {
+	var params tools.CopyParams
+	var args []string
+	if err := cr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(fsext.PrettyPath(params.SourcePath)).
+			addKeyValue("to", fsext.PrettyPath(params.DestinationPath)).
+			build()
+	}
+
+	return cr.renderWithParams(v, "Copy", args, func() string {
+		return ""
+	})
+}
+
+// deleteRenderer handles deleting files and directories
+type deleteRenderer struct {
+	baseRenderer
+}
+
+// Render displays the deleted path
+func (dr deleteRenderer) Render(v *toolCallCmp) string {
+	var params tools.DeleteParams
+	var args []string
+	if err := dr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(fsext.PrettyPath(params.Path)).
+			addFlag("recursive", params.Recursive).
+			build()
+	}
+
+	return dr.renderWithParams(v, "Delete", args, func() string {
+		return ""
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Glob renderer
 // -----------------------------------------------------------------------------
@@ -866,6 +1042,35 @@ func (lr lsRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Repo map renderer
+// -----------------------------------------------------------------------------
+
+// repoMapRenderer handles repository maps with an optional token budget
+type repoMapRenderer struct {
+	baseRenderer
+}
+
+// Render displays the mapped directory, defaulting to current directory
+func (rr repoMapRenderer) Render(v *toolCallCmp) string {
+	var params tools.RepoMapParams
+	var args []string
+	if err := rr.unmarshalParams(v.call.Input, &params); err == nil {
+		path := params.Path
+		if path == "" {
+			path = "."
+		}
+		args = newParamBuilder().
+			addMain(fsext.PrettyPath(path)).
+			addKeyValue("max_tokens", formatNonZero(params.MaxTokens)).
+			build()
+	}
+
+	return rr.renderWithParams(v, "Repo Map", args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Sourcegraph renderer
 // -----------------------------------------------------------------------------
@@ -1284,6 +1489,16 @@ func prettifyToolName(name string) string {
 		return "Edit"
 	case tools.MultiEditToolName:
 		return "Multi-Edit"
+	case tools.NotebookEditToolName:
+		return "Notebook Edit"
+	case tools.MoveToolName:
+		return "Move"
+	case tools.CopyToolName:
+		return "Copy"
+	case tools.DeleteToolName:
+		return "Delete"
+	case tools.MemoryToolName:
+		return "Memory"
 	case tools.FetchToolName:
 		return "Fetch"
 	case tools.AgenticFetchToolName:
@@ -1298,10 +1513,16 @@ func prettifyToolName(name string) string {
 		return "Grep"
 	case tools.LSToolName:
 		return "List"
+	case tools.RepoMapToolName:
+		return "Repo Map"
+	case tools.ReadMCPResourceToolName:
+		return "MCP Resource"
 	case tools.SourcegraphToolName:
 		return "Sourcegraph"
 	case tools.TodosToolName:
 		return "To-Do"
+	case tools.ToolSearchToolName:
+		return "Tool Search"
 	case tools.ViewToolName:
 		return "View"
 	case tools.WriteToolName:
@@ -279,6 +279,39 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			parts = append(parts, fmt.Sprintf("**Edits:** %d", len(params.Edits)))
 			return strings.Join(parts, "\n")
 		}
+	case tools.NotebookEditToolName:
+		var params tools.NotebookEditParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			var parts []string
+			parts = append(parts, fmt.Sprintf("**File:** %s", fsext.PrettyPath(params.FilePath)))
+			parts = append(parts, fmt.Sprintf("**Cell:** %d", params.CellIndex))
+			if params.EditMode != "" {
+				parts = append(parts, fmt.Sprintf("**Mode:** %s", params.EditMode))
+			}
+			return strings.Join(parts, "\n")
+		}
+	case tools.MoveToolName, tools.CopyToolName:
+		var params tools.MoveParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			return fmt.Sprintf("**From:** %s\n**To:** %s", fsext.PrettyPath(params.SourcePath), fsext.PrettyPath(params.DestinationPath))
+		}
+	case tools.DeleteToolName:
+		var params tools.DeleteParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(params.Path))
+		}
+	case tools.MemoryToolName:
+		var params tools.MemoryParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			parts := []string{fmt.Sprintf("**Action:** %s", params.Action)}
+			if params.OldEntry != "" {
+				parts = append(parts, fmt.Sprintf("**Old Entry:** %s", params.OldEntry))
+			}
+			if params.Entry != "" {
+				parts = append(parts, fmt.Sprintf("**Entry:** %s", params.Entry))
+			}
+			return strings.Join(parts, "\n")
+		}
 	case tools.WriteToolName:
 		var params tools.WriteParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
@@ -349,6 +382,15 @@ func (m *toolCallCmp) formatParametersForCopy() string {
 			}
 			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(path))
 		}
+	case tools.RepoMapToolName:
+		var params tools.RepoMapParams
+		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
+			path := params.Path
+			if path == "" {
+				path = "."
+			}
+			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(path))
+		}
 	case tools.DownloadToolName:
 		var params tools.DownloadParams
 		if json.Unmarshal([]byte(m.call.Input), &params) == nil {
@@ -411,7 +453,7 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatBashResultForCopy()
 	case tools.ViewToolName:
 		return m.formatViewResultForCopy()
-	case tools.EditToolName:
+	case tools.EditToolName, tools.NotebookEditToolName, tools.MemoryToolName:
 		return m.formatEditResultForCopy()
 	case tools.MultiEditToolName:
 		return m.formatMultiEditResultForCopy()
@@ -425,7 +467,7 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatWebFetchResultForCopy()
 	case agent.AgentToolName:
 		return m.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.RepoMapToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
 		return fmt.Sprintf("```\n%s\n```", m.result.Content)
 	default:
 		return m.result.Content
@@ -0,0 +1,247 @@
+package elicitation
+
+import (
+	"cmp"
+	"log/slog"
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const (
+	headerHeight      = 3
+	itemHeight        = 3
+	paddingHorizontal = 3
+)
+
+// ElicitationDialog is a form asking the user for the input an MCP server
+// requested.
+type ElicitationDialog interface {
+	dialogs.DialogModel
+	dialogs.CloseCallback
+}
+
+type elicitationDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+
+	request  mcp.ElicitationRequest
+	inputs   []textinput.Model
+	focused  int
+	answered bool
+	keys     KeyMap
+	help     help.Model
+}
+
+// NewElicitationDialog returns the form of the elicitation request req. The
+// request is answered when the form is submitted, and cancelled when it's
+// closed without.
+func NewElicitationDialog(req mcp.ElicitationRequest) ElicitationDialog {
+	t := styles.CurrentTheme()
+	inputs := make([]textinput.Model, len(req.Fields))
+	for i, f := range req.Fields {
+		ti := textinput.New()
+		ti.Placeholder = placeholder(f)
+		ti.SetValue(f.Default)
+		ti.SetWidth(40)
+		ti.SetVirtualCursor(false)
+		ti.Prompt = ""
+		ti.SetStyles(t.S().TextInput)
+		if i == 0 {
+			ti.Focus()
+		}
+		inputs[i] = ti
+	}
+
+	return &elicitationDialogCmp{
+		request: req,
+		inputs:  inputs,
+		keys:    DefaultKeyMap(),
+		width:   60,
+		help:    help.New(),
+	}
+}
+
+// placeholder describes the value expected in the field f.
+func placeholder(f mcp.ElicitationField) string {
+	switch {
+	case len(f.Enum) > 0:
+		return cmp.Or(f.Description, "One of") + " (" + strings.Join(f.Enum, ", ") + ")"
+	case f.Type == "boolean":
+		return cmp.Or(f.Description, "Yes or no") + " (yes/no)"
+	}
+	return cmp.Or(f.Description, "Enter value for "+cmp.Or(f.Title, f.Name))
+}
+
+// Init implements ElicitationDialog.
+func (e *elicitationDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements ElicitationDialog.
+func (e *elicitationDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.wWidth = msg.Width
+		e.wHeight = msg.Height
+		e.width = min(90, e.wWidth)
+		e.height = min(15, e.wHeight)
+		for i := range e.inputs {
+			e.inputs[i].SetWidth(e.width - (paddingHorizontal * 2))
+		}
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, e.keys.Close):
+			return e, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case key.Matches(msg, e.keys.Confirm):
+			if len(e.inputs) == 0 || e.focused == len(e.inputs)-1 {
+				return e, e.submit()
+			}
+			e.focus(e.focused + 1)
+		case key.Matches(msg, e.keys.Next):
+			e.focus(e.focused + 1)
+		case key.Matches(msg, e.keys.Previous):
+			e.focus(e.focused - 1)
+		default:
+			if len(e.inputs) == 0 {
+				return e, nil
+			}
+			var cmd tea.Cmd
+			e.inputs[e.focused], cmd = e.inputs[e.focused].Update(msg)
+			return e, cmd
+		}
+	case tea.PasteMsg:
+		if len(e.inputs) == 0 {
+			return e, nil
+		}
+		var cmd tea.Cmd
+		e.inputs[e.focused], cmd = e.inputs[e.focused].Update(msg)
+		return e, cmd
+	}
+	return e, nil
+}
+
+func (e *elicitationDialogCmp) focus(i int) {
+	if len(e.inputs) == 0 {
+		return
+	}
+	e.inputs[e.focused].Blur()
+	e.focused = (i + len(e.inputs)) % len(e.inputs)
+	e.inputs[e.focused].Focus()
+}
+
+// submit answers the request with the values of the form, keeping it open
+// when they're invalid so the user can fix them.
+func (e *elicitationDialogCmp) submit() tea.Cmd {
+	values := make(map[string]string, len(e.inputs))
+	for i, f := range e.request.Fields {
+		values[f.Name] = e.inputs[i].Value()
+	}
+	if err := mcp.RespondElicitation(e.request.ID, mcp.ElicitationAccept, values); err != nil {
+		return util.ReportWarn(err.Error())
+	}
+	e.answered = true
+	return util.CmdHandler(dialogs.CloseDialogMsg{})
+}
+
+// Close implements ElicitationDialog.
+func (e *elicitationDialogCmp) Close() tea.Cmd {
+	if e.answered {
+		return nil
+	}
+	e.answered = true
+	if err := mcp.RespondElicitation(e.request.ID, mcp.ElicitationCancel, nil); err != nil {
+		slog.Warn("Failed to answer MCP elicitation", "error", err)
+	}
+	return nil
+}
+
+// View implements ElicitationDialog.
+func (e *elicitationDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+
+	title := lipgloss.NewStyle().
+		Foreground(t.Primary).
+		Bold(true).
+		Padding(0, 1).
+		Render(e.request.Name + " Needs Input")
+
+	elements := []string{title, e.message()}
+	for i, input := range e.inputs {
+		labelStyle := baseStyle.Padding(1, 1, 0, 1)
+		if i == e.focused {
+			labelStyle = labelStyle.Foreground(t.FgBase).Bold(true)
+		} else {
+			labelStyle = labelStyle.Foreground(t.FgMuted)
+		}
+
+		f := e.request.Fields[i]
+		name := cmp.Or(f.Title, f.Name)
+		if f.Required {
+			name += "*"
+		}
+		label := labelStyle.Render(name + ":")
+		field := t.S().Text.
+			Padding(0, 1).
+			Render(input.View())
+		elements = append(elements, lipgloss.JoinVertical(lipgloss.Left, label, field))
+	}
+
+	e.help.ShowAll = false
+	helpText := baseStyle.Padding(0, 1).Render(e.help.View(e.keys))
+	elements = append(elements, "", helpText)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, elements...)
+
+	return baseStyle.Padding(1, 1, 0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Width(e.width).
+		Render(content)
+}
+
+// message renders the message of the request, wrapped to the dialog.
+func (e *elicitationDialogCmp) message() string {
+	t := styles.CurrentTheme()
+	return t.S().Text.
+		Padding(0, 1).
+		Width(e.width - 4).
+		Render(e.request.Message)
+}
+
+// Cursor implements ElicitationDialog.
+func (e *elicitationDialogCmp) Cursor() *tea.Cursor {
+	if len(e.inputs) == 0 {
+		return nil
+	}
+	cursor := e.inputs[e.focused].Cursor()
+	if cursor == nil {
+		return nil
+	}
+	row, col := e.Position()
+	// The header fits a one line message.
+	cursor.Y += row + headerHeight + lipgloss.Height(e.message()) - 1 + (1+e.focused)*itemHeight
+	cursor.X += col + paddingHorizontal
+	return cursor
+}
+
+// Position implements ElicitationDialog.
+func (e *elicitationDialogCmp) Position() (int, int) {
+	row := (e.wHeight / 2) - (e.height / 2)
+	col := (e.wWidth / 2) - (e.width / 2)
+	return row, col
+}
+
+// ID implements ElicitationDialog.
+func (e *elicitationDialogCmp) ID() dialogs.DialogID {
+	return dialogs.DialogID("elicitation-" + e.request.ID)
+}
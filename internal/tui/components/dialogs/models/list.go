@@ -166,11 +166,11 @@ func (m *ModelListComponent) SetModelType(modelType int) tea.Cmd {
 					Model:    model,
 				}
 				key := modelKey(string(configProvider.ID), model.ID)
-				item := list.NewCompletionItem(
-					model.Name,
-					modelOption,
-					list.WithCompletionID(key),
-				)
+				opts := []list.CompletionItemOption{list.WithCompletionID(key)}
+				if slices.Contains(providerConfig.DiscoveredModels, model.ID) {
+					opts = append(opts, list.WithCompletionShortcut("discovered"))
+				}
+				item := list.NewCompletionItem(model.Name, modelOption, opts...)
 				itemsByKey[key] = item
 
 				group.Items = append(group.Items, item)
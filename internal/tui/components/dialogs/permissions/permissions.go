@@ -92,7 +92,7 @@ func (p *permissionDialogCmp) Init() tea.Cmd {
 }
 
 func (p *permissionDialogCmp) supportsDiffView() bool {
-	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName
+	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName || p.permission.ToolName == tools.NotebookEditToolName || p.permission.ToolName == tools.MemoryToolName
 }
 
 func (p *permissionDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
@@ -338,7 +338,7 @@ func (p *permissionDialogCmp) renderHeader() string {
 			),
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
-	case tools.EditToolName:
+	case tools.EditToolName, tools.NotebookEditToolName, tools.MemoryToolName:
 		params := p.permission.Params.(tools.EditPermissionsParams)
 		fileKey := t.S().Muted.Render("File")
 		filePath := t.S().Text.
@@ -437,7 +437,7 @@ func (p *permissionDialogCmp) getOrGenerateContent() string {
 		content = p.generateBashContent()
 	case tools.DownloadToolName:
 		content = p.generateDownloadContent()
-	case tools.EditToolName:
+	case tools.EditToolName, tools.NotebookEditToolName, tools.MemoryToolName:
 		content = p.generateEditContent()
 	case tools.WriteToolName:
 		content = p.generateWriteContent()
@@ -814,7 +814,7 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.DownloadToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.4)
-	case tools.EditToolName:
+	case tools.EditToolName, tools.NotebookEditToolName, tools.MemoryToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.8)
 	case tools.WriteToolName:
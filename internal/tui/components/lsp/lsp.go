@@ -81,11 +81,15 @@ func RenderLSPList(lspClients *csync.Map[string, *lsp.Client], opts RenderOption
 			}
 		}
 
+		title := info.Name
+		if info.Client != nil {
+			title = info.Client.GetName()
+		}
 		lspList = append(lspList,
 			core.Status(
 				core.StatusOpts{
 					Icon:         icon.String(),
-					Title:        info.Name,
+					Title:        title,
 					Description:  description,
 					ExtraContent: extraContent,
 				},
@@ -102,7 +106,7 @@ func iconAndDescription(t *styles.Theme, info app.LSPClientInfo) (lipgloss.Style
 	case lsp.StateStarting:
 		return t.ItemBusyIcon, t.S().Subtle.Render("starting...")
 	case lsp.StateReady:
-		return t.ItemOnlineIcon, ""
+		return t.ItemOnlineIcon, t.S().Subtle.Render(rootDescription(info.Root))
 	case lsp.StateError:
 		description := t.S().Subtle.Render("error")
 		if info.Error != nil {
@@ -116,6 +120,14 @@ func iconAndDescription(t *styles.Theme, info app.LSPClientInfo) (lipgloss.Style
 	}
 }
 
+// rootDescription formats the project root of an LSP client for display.
+func rootDescription(root string) string {
+	if root == "" || root == "." {
+		return "./"
+	}
+	return "./" + strings.TrimSuffix(root, "/") + "/"
+}
+
 // RenderLSPBlock renders a complete LSP block with optional truncation indicator.
 func RenderLSPBlock(lspClients *csync.Map[string, *lsp.Client], opts RenderOptions, showTruncationIndicator bool) string {
 	t := styles.CurrentTheme()
@@ -11,7 +11,9 @@ import (
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/app"
+	customcommands "github.com/charmbracelet/crush/internal/commands"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/message"
@@ -404,7 +406,7 @@ func (p *chatPage) Update(msg tea.Msg) (util.Model, tea.Cmd) {
 			return p, util.ReportWarn("Agent is busy, please wait before executing a command...")
 		}
 
-		cmd := p.sendMessage(msg.Content, nil)
+		cmd := p.runCustomCommand(msg.Command, msg.Args)
 		if cmd != nil {
 			return p, cmd
 		}
@@ -958,6 +960,34 @@ func (p *chatPage) toggleDetails() {
 }
 
 func (p *chatPage) sendMessage(text string, attachments []message.Attachment) tea.Cmd {
+	return p.runAgent(func(ctx context.Context, sessionID string) error {
+		_, err := p.app.AgentCoordinator.Run(ctx, sessionID, text, attachments...)
+		return err
+	})
+}
+
+// runCustomCommand expands the prompt of a custom command, which can ask for
+// permission to run its shell blocks, and sends it with the command's model
+// and tools.
+func (p *chatPage) runCustomCommand(command customcommands.CustomCommand, args map[string]string) tea.Cmd {
+	return p.runAgent(func(ctx context.Context, sessionID string) error {
+		workingDir := p.app.Config().WorkingDir()
+		run := customcommands.PermissionShell(p.app.Permissions, sessionID, workingDir)
+		content, err := command.Expand(ctx, workingDir, args, run)
+		if err != nil {
+			return err
+		}
+		_, err = p.app.AgentCoordinator.RunWithOptions(ctx, sessionID, content, agent.RunOptions{
+			Model:        command.Model,
+			Agent:        command.Agent,
+			AllowedTools: command.AllowedTools,
+		})
+		return err
+	})
+}
+
+// runAgent runs fn with the current session, creating one if needed.
+func (p *chatPage) runAgent(fn func(ctx context.Context, sessionID string) error) tea.Cmd {
 	session := p.session
 	var cmds []tea.Cmd
 	if p.session.ID == "" {
@@ -976,7 +1006,7 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 	}
 	cmds = append(cmds, p.chat.GoToBottom())
 	cmds = append(cmds, func() tea.Msg {
-		_, err := p.app.AgentCoordinator.Run(context.Background(), session.ID, text, attachments...)
+		err := fn(context.Background(), session.ID)
 		if err != nil {
 			isCancelErr := errors.Is(err, context.Canceled)
 			isPermissionErr := errors.Is(err, permission.ErrorPermissionDenied)
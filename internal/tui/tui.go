@@ -27,6 +27,7 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/status"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/elicitation"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/permissions"
@@ -38,8 +39,6 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/util"
 	xstrings "github.com/charmbracelet/x/exp/strings"
 	"golang.org/x/mod/semver"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 var lastMouseEvent time.Time
@@ -166,7 +165,13 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, handleMCPPromptsEvent(context.Background(), msg.Payload.Name)
 		case mcp.EventToolsListChanged:
 			return a, handleMCPToolsEvent(context.Background(), msg.Payload.Name)
+		case mcp.EventResourcesListChanged:
+			return a, handleMCPResourcesEvent(context.Background(), msg.Payload.Name)
 		}
+	case pubsub.Event[mcp.ElicitationRequest]:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: elicitation.NewElicitationDialog(msg.Payload),
+		})
 
 	// Completions messages
 	case completions.OpenCompletionsMsg, completions.FilterCompletionsMsg,
@@ -187,11 +192,12 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.Batch(completionCmd, dialogCmd)
 	case commands.ShowArgumentsDialogMsg:
 		var args []commands.Argument
-		for _, arg := range msg.ArgNames {
+		for _, arg := range msg.Arguments {
 			args = append(args, commands.Argument{
-				Name:     arg,
-				Title:    cases.Title(language.English).String(arg),
-				Required: true,
+				Name:        arg.ID,
+				Title:       arg.Title,
+				Description: arg.Description,
+				Required:    arg.Required,
 			})
 		}
 		return a, util.CmdHandler(
@@ -680,6 +686,13 @@ func handleMCPPromptsEvent(ctx context.Context, name string) tea.Cmd {
 	}
 }
 
+func handleMCPResourcesEvent(ctx context.Context, name string) tea.Cmd {
+	return func() tea.Msg {
+		mcp.RefreshResources(ctx, name)
+		return nil
+	}
+}
+
 func handleMCPToolsEvent(ctx context.Context, name string) tea.Cmd {
 	return func() tea.Msg {
 		mcp.RefreshTools(ctx, name)
@@ -277,6 +277,143 @@ func (m *MultiEditToolRenderContext) RenderTool(sty *styles.Styles, width int, o
 	return joinToolParts(header, body)
 }
 
+// -----------------------------------------------------------------------------
+// Notebook Edit Tool
+// -----------------------------------------------------------------------------
+
+// NotebookEditToolMessageItem is a message item that represents a notebook
+// edit tool call.
+type NotebookEditToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*NotebookEditToolMessageItem)(nil)
+
+// NewNotebookEditToolMessageItem creates a new [NotebookEditToolMessageItem].
+func NewNotebookEditToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &NotebookEditToolRenderContext{}, canceled)
+}
+
+// NotebookEditToolRenderContext renders notebook edit tool messages.
+type NotebookEditToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (n *NotebookEditToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	// Notebook edit tool uses full width for diffs.
+	if opts.IsPending() {
+		return pendingTool(sty, "Notebook Edit", opts.Anim)
+	}
+
+	var params tools.NotebookEditParams
+	if err := json.Unmarshal([]byte(opts.ToolCall.Input), &params); err != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, width)
+	}
+
+	file := fsext.PrettyPath(params.FilePath)
+	toolParams := []string{file, "cell", fmt.Sprintf("%d", params.CellIndex)}
+	if params.EditMode != "" {
+		toolParams = append(toolParams, "mode", params.EditMode)
+	}
+
+	header := toolHeader(sty, opts.Status, "Notebook Edit", width, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, width); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if !opts.HasResult() {
+		return header
+	}
+
+	// Get diff content of the cell from metadata.
+	var meta tools.EditResponseMetadata
+	if err := json.Unmarshal([]byte(opts.Result.Metadata), &meta); err != nil {
+		bodyWidth := width - toolBodyLeftPaddingTotal
+		body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		return joinToolParts(header, body)
+	}
+
+	body := toolOutputDiffContent(sty, file, meta.OldContent, meta.NewContent, width, opts.ExpandedContent)
+	return joinToolParts(header, body)
+}
+
+// -----------------------------------------------------------------------------
+// Memory Tool
+// -----------------------------------------------------------------------------
+
+// MemoryToolMessageItem is a message item that represents a memory tool call.
+type MemoryToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*MemoryToolMessageItem)(nil)
+
+// NewMemoryToolMessageItem creates a new [MemoryToolMessageItem].
+func NewMemoryToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &MemoryToolRenderContext{}, canceled)
+}
+
+// MemoryToolRenderContext renders memory tool messages.
+type MemoryToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (m *MemoryToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	// Memory tool uses full width for diffs.
+	if opts.IsPending() {
+		return pendingTool(sty, "Memory", opts.Anim)
+	}
+
+	var params tools.MemoryParams
+	if err := json.Unmarshal([]byte(opts.ToolCall.Input), &params); err != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, width)
+	}
+
+	entry := params.Entry
+	if entry == "" {
+		entry = params.OldEntry
+	}
+	toolParams := []string{entry, "action", params.Action}
+	if params.Scope != "" {
+		toolParams = append(toolParams, "scope", params.Scope)
+	}
+
+	header := toolHeader(sty, opts.Status, "Memory", width, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, width); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if !opts.HasResult() {
+		return header
+	}
+
+	var meta tools.MemoryResponseMetadata
+	if err := json.Unmarshal([]byte(opts.Result.Metadata), &meta); err != nil {
+		bodyWidth := width - toolBodyLeftPaddingTotal
+		body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		return joinToolParts(header, body)
+	}
+
+	body := toolOutputDiffContent(sty, fsext.PrettyPath(meta.FilePath), meta.OldContent, meta.NewContent, width, opts.ExpandedContent)
+	return joinToolParts(header, body)
+}
+
 // -----------------------------------------------------------------------------
 // Download Tool
 // -----------------------------------------------------------------------------
@@ -338,3 +475,115 @@ func (d *DownloadToolRenderContext) RenderTool(sty *styles.Styles, width int, op
 	body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
 	return joinToolParts(header, body)
 }
+
+// -----------------------------------------------------------------------------
+// Move, Copy and Delete Tools
+// -----------------------------------------------------------------------------
+
+// MoveToolMessageItem is a message item that represents a move tool call.
+type MoveToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*MoveToolMessageItem)(nil)
+
+// NewMoveToolMessageItem creates a new [MoveToolMessageItem].
+func NewMoveToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &MoveToolRenderContext{}, canceled)
+}
+
+// MoveToolRenderContext renders move tool messages.
+type MoveToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (m *MoveToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	var params tools.MoveParams
+	err := json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+	return renderFileOpTool(sty, width, opts, "Move", err, params.SourcePath, params.DestinationPath)
+}
+
+// CopyToolMessageItem is a message item that represents a copy tool call.
+type CopyToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*CopyToolMessageItem)(nil)
+
+// NewCopyToolMessageItem creates a new [CopyToolMessageItem].
+func NewCopyToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &CopyToolRenderContext{}, canceled)
+}
+
+// CopyToolRenderContext renders copy tool messages.
+type CopyToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (c *CopyToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	var params tools.CopyParams
+	err := json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+	return renderFileOpTool(sty, width, opts, "Copy", err, params.SourcePath, params.DestinationPath)
+}
+
+// DeleteToolMessageItem is a message item that represents a delete tool call.
+type DeleteToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*DeleteToolMessageItem)(nil)
+
+// NewDeleteToolMessageItem creates a new [DeleteToolMessageItem].
+func NewDeleteToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &DeleteToolRenderContext{}, canceled)
+}
+
+// DeleteToolRenderContext renders delete tool messages.
+type DeleteToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (d *DeleteToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	var params tools.DeleteParams
+	err := json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+	return renderFileOpTool(sty, width, opts, "Delete", err, params.Path, "")
+}
+
+// renderFileOpTool renders a move, copy or delete tool call. The header says
+// it all, so only errors get a body.
+func renderFileOpTool(sty *styles.Styles, width int, opts *ToolRenderOpts, name string, paramsErr error, path, destination string) string {
+	cappedWidth := cappedMessageWidth(width)
+	if opts.IsPending() {
+		return pendingTool(sty, name, opts.Anim)
+	}
+	if paramsErr != nil {
+		return toolErrorContent(sty, &message.ToolResult{Content: "Invalid parameters"}, cappedWidth)
+	}
+
+	toolParams := []string{fsext.PrettyPath(path)}
+	if destination != "" {
+		toolParams = append(toolParams, "to", fsext.PrettyPath(destination))
+	}
+
+	header := toolHeader(sty, opts.Status, name, cappedWidth, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+	return header
+}
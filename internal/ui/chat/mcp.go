@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/stringext"
 	"github.com/charmbracelet/crush/internal/ui/styles"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // MCPToolMessageItem is a message item that represents a bash tool call.
 type MCPToolMessageItem struct {
 	*baseToolMessageItem
+	renderContext *MCPToolRenderContext
 }
 
 var _ ToolMessageItem = (*MCPToolMessageItem)(nil)
@@ -24,11 +28,23 @@ func NewMCPToolMessageItem(
 	result *message.ToolResult,
 	canceled bool,
 ) ToolMessageItem {
-	return newBaseToolMessageItem(sty, toolCall, result, &MCPToolRenderContext{}, canceled)
+	renderContext := &MCPToolRenderContext{}
+	return &MCPToolMessageItem{
+		baseToolMessageItem: newBaseToolMessageItem(sty, toolCall, result, renderContext, canceled),
+		renderContext:       renderContext,
+	}
+}
+
+// SetProgress sets the progress the MCP server reported for the tool call.
+func (m *MCPToolMessageItem) SetProgress(progress mcp.ToolProgress) {
+	m.renderContext.progress = &progress
+	m.clearCache()
 }
 
 // MCPToolRenderContext renders bash tool messages.
-type MCPToolRenderContext struct{}
+type MCPToolRenderContext struct {
+	progress *mcp.ToolProgress
+}
 
 // RenderTool implements the [ToolRenderer] interface.
 func (b *MCPToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
@@ -65,31 +81,79 @@ func (b *MCPToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *T
 		return header
 	}
 
+	if opts.Status == ToolStatusRunning && b.progress != nil {
+		return joinToolParts(header, mcpProgressContent(sty, b.progress, cappedWidth))
+	}
+
 	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
 		return joinToolParts(header, earlyState)
 	}
 
-	if !opts.HasResult() || opts.Result.Content == "" {
+	if !opts.HasResult() {
 		return header
 	}
 
 	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
-	// see if the result is json
-	var result json.RawMessage
-	var body string
-	if err := json.Unmarshal([]byte(opts.Result.Content), &result); err == nil {
-		prettyResult, err := json.MarshalIndent(result, "", "  ")
-		if err == nil {
-			body = sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.json", string(prettyResult), 0, bodyWidth, opts.ExpandedContent))
-		} else {
-			body = sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	var parts []string
+	if content := mcpResultText(opts.Result); content != "" {
+		parts = append(parts, mcpTextContent(sty, content, bodyWidth, opts.ExpandedContent))
+	}
+	// Results carry their first media item, the others are in the metadata.
+	if opts.Result.Data != "" {
+		parts = append(parts, toolOutputImageContent(sty, opts.Result.Data, opts.Result.MIMEType))
+	}
+	var metadata tools.MCPResponseMetadata
+	if opts.Result.Metadata != "" && json.Unmarshal([]byte(opts.Result.Metadata), &metadata) == nil {
+		for _, media := range metadata.Media {
+			parts = append(parts, toolOutputMediaContent(sty, media.MediaType, len(media.Data)))
 		}
-	} else if looksLikeMarkdown(opts.Result.Content) {
-		body = sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.md", opts.Result.Content, 0, bodyWidth, opts.ExpandedContent))
+	}
+	if len(parts) == 0 {
+		return header
+	}
+	return joinToolParts(header, strings.Join(parts, "\n"))
+}
+
+// mcpProgressContent renders the progress of a running MCP tool as a bar
+// when its total is known, followed by its message.
+func mcpProgressContent(sty *styles.Styles, progress *mcp.ToolProgress, width int) string {
+	var parts []string
+	if progress.Total > 0 {
+		const barWidth = 20
+		ratio := min(max(progress.Progress/progress.Total, 0), 1)
+		filled := int(ratio * barWidth)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		parts = append(parts, bar, fmt.Sprintf("%d%%", int(ratio*100)))
 	} else {
-		body = sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+		parts = append(parts, fmt.Sprintf("%g", progress.Progress))
+	}
+	if progress.Message != "" {
+		parts = append(parts, progress.Message)
+	}
+	return sty.Tool.StateWaiting.Render(ansi.Truncate(strings.Join(parts, " "), width, "…"))
+}
+
+// mcpResultText returns the text of an MCP tool result, without the
+// placeholder of results that only have media.
+func mcpResultText(result *message.ToolResult) string {
+	if result.Data != "" && result.Content == fmt.Sprintf("Loaded %s content", result.MIMEType) {
+		return ""
+	}
+	return result.Content
+}
+
+// mcpTextContent renders the text of an MCP tool result as JSON, markdown or
+// plain text.
+func mcpTextContent(sty *styles.Styles, content string, width int, expanded bool) string {
+	var result json.RawMessage
+	if err := json.Unmarshal([]byte(content), &result); err == nil {
+		if prettyResult, err := json.MarshalIndent(result, "", "  "); err == nil {
+			return sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.json", string(prettyResult), 0, width, expanded))
+		}
+	} else if looksLikeMarkdown(content) {
+		return sty.Tool.Body.Render(toolOutputCodeContent(sty, "result.md", content, 0, width, expanded))
 	}
-	return joinToolParts(header, body)
+	return sty.Tool.Body.Render(toolOutputPlainContent(sty, content, width, expanded))
 }
 
 func prettyName(name string) string {
@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"encoding/json"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/ui/styles"
+)
+
+// ReadMCPResourceToolMessageItem is a message item that represents a
+// read_mcp_resource tool call.
+type ReadMCPResourceToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*ReadMCPResourceToolMessageItem)(nil)
+
+// NewReadMCPResourceToolMessageItem creates a new
+// [ReadMCPResourceToolMessageItem].
+func NewReadMCPResourceToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &ReadMCPResourceToolRenderContext{}, canceled)
+}
+
+// ReadMCPResourceToolRenderContext renders read_mcp_resource tool messages.
+type ReadMCPResourceToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (r *ReadMCPResourceToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+	if opts.IsPending() {
+		return pendingTool(sty, "MCP Resource", opts.Anim)
+	}
+
+	var params tools.ReadMCPResourceParams
+	_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+
+	toolParams := []string{}
+	switch {
+	case params.URI != "":
+		toolParams = append(toolParams, params.MCP+":"+params.URI)
+	case params.MCP != "":
+		toolParams = append(toolParams, "list", "mcp", params.MCP)
+	default:
+		toolParams = append(toolParams, "list")
+	}
+
+	header := toolHeader(sty, opts.Status, "MCP Resource", cappedWidth, opts.Compact, toolParams...)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if opts.HasEmptyResult() {
+		return header
+	}
+
+	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	return joinToolParts(header, body)
+}
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"encoding/json"
+
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/ui/styles"
+)
+
+// ToolSearchToolMessageItem is a message item that represents a tool_search
+// tool call.
+type ToolSearchToolMessageItem struct {
+	*baseToolMessageItem
+}
+
+var _ ToolMessageItem = (*ToolSearchToolMessageItem)(nil)
+
+// NewToolSearchToolMessageItem creates a new [ToolSearchToolMessageItem].
+func NewToolSearchToolMessageItem(
+	sty *styles.Styles,
+	toolCall message.ToolCall,
+	result *message.ToolResult,
+	canceled bool,
+) ToolMessageItem {
+	return newBaseToolMessageItem(sty, toolCall, result, &ToolSearchToolRenderContext{}, canceled)
+}
+
+// ToolSearchToolRenderContext renders tool_search tool messages.
+type ToolSearchToolRenderContext struct{}
+
+// RenderTool implements the [ToolRenderer] interface.
+func (r *ToolSearchToolRenderContext) RenderTool(sty *styles.Styles, width int, opts *ToolRenderOpts) string {
+	cappedWidth := cappedMessageWidth(width)
+	if opts.IsPending() {
+		return pendingTool(sty, "Tool Search", opts.Anim)
+	}
+
+	var params tools.ToolSearchParams
+	_ = json.Unmarshal([]byte(opts.ToolCall.Input), &params)
+
+	header := toolHeader(sty, opts.Status, "Tool Search", cappedWidth, opts.Compact, params.Query)
+	if opts.Compact {
+		return header
+	}
+
+	if earlyState, ok := toolEarlyStateContent(sty, opts, cappedWidth); ok {
+		return joinToolParts(header, earlyState)
+	}
+
+	if opts.HasEmptyResult() {
+		return header
+	}
+
+	bodyWidth := cappedWidth - toolBodyLeftPaddingTotal
+	body := sty.Tool.Body.Render(toolOutputPlainContent(sty, opts.Result.Content, bodyWidth, opts.ExpandedContent))
+	return joinToolParts(header, body)
+}
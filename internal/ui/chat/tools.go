@@ -166,7 +166,7 @@ func newBaseToolMessageItem(
 	canceled bool,
 ) *baseToolMessageItem {
 	// we only do full width for diffs (as far as I know)
-	hasCappedWidth := toolCall.Name != tools.EditToolName && toolCall.Name != tools.MultiEditToolName
+	hasCappedWidth := toolCall.Name != tools.EditToolName && toolCall.Name != tools.MultiEditToolName && toolCall.Name != tools.NotebookEditToolName && toolCall.Name != tools.MemoryToolName
 
 	status := ToolStatusRunning
 	if canceled {
@@ -224,12 +224,24 @@ func NewToolMessageItem(
 		item = NewEditToolMessageItem(sty, toolCall, result, canceled)
 	case tools.MultiEditToolName:
 		item = NewMultiEditToolMessageItem(sty, toolCall, result, canceled)
+	case tools.NotebookEditToolName:
+		item = NewNotebookEditToolMessageItem(sty, toolCall, result, canceled)
+	case tools.MoveToolName:
+		item = NewMoveToolMessageItem(sty, toolCall, result, canceled)
+	case tools.CopyToolName:
+		item = NewCopyToolMessageItem(sty, toolCall, result, canceled)
+	case tools.DeleteToolName:
+		item = NewDeleteToolMessageItem(sty, toolCall, result, canceled)
+	case tools.MemoryToolName:
+		item = NewMemoryToolMessageItem(sty, toolCall, result, canceled)
 	case tools.GlobToolName:
 		item = NewGlobToolMessageItem(sty, toolCall, result, canceled)
 	case tools.GrepToolName:
 		item = NewGrepToolMessageItem(sty, toolCall, result, canceled)
 	case tools.LSToolName:
 		item = NewLSToolMessageItem(sty, toolCall, result, canceled)
+	case tools.RepoMapToolName:
+		item = NewRepoMapToolMessageItem(sty, toolCall, result, canceled)
 	case tools.DownloadToolName:
 		item = NewDownloadToolMessageItem(sty, toolCall, result, canceled)
 	case tools.FetchToolName:
@@ -252,6 +264,10 @@ func NewToolMessageItem(
 		item = NewReferencesToolMessageItem(sty, toolCall, result, canceled)
 	case tools.LSPRestartToolName:
 		item = NewLSPRestartToolMessageItem(sty, toolCall, result, canceled)
+	case tools.ReadMCPResourceToolName:
+		item = NewReadMCPResourceToolMessageItem(sty, toolCall, result, canceled)
+	case tools.ToolSearchToolName:
+		item = NewToolSearchToolMessageItem(sty, toolCall, result, canceled)
 	default:
 		if strings.HasPrefix(toolCall.Name, "mcp_") {
 			item = NewMCPToolMessageItem(sty, toolCall, result, canceled)
@@ -620,7 +636,11 @@ func toolOutputCodeContent(sty *styles.Styles, path, content string, offset, wid
 
 // toolOutputImageContent renders image data with size info.
 func toolOutputImageContent(sty *styles.Styles, data, mediaType string) string {
-	dataSize := len(data) * 3 / 4
+	return toolOutputMediaContent(sty, mediaType, len(data)*3/4)
+}
+
+// toolOutputMediaContent renders the type and size of media data.
+func toolOutputMediaContent(sty *styles.Styles, mediaType string, dataSize int) string {
 	sizeStr := formatSize(dataSize)
 
 	loaded := sty.Base.Foreground(sty.Green).Render("Loaded")
@@ -884,6 +904,39 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			parts = append(parts, fmt.Sprintf("**Edits:** %d", len(params.Edits)))
 			return strings.Join(parts, "\n")
 		}
+	case tools.NotebookEditToolName:
+		var params tools.NotebookEditParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			var parts []string
+			parts = append(parts, fmt.Sprintf("**File:** %s", fsext.PrettyPath(params.FilePath)))
+			parts = append(parts, fmt.Sprintf("**Cell:** %d", params.CellIndex))
+			if params.EditMode != "" {
+				parts = append(parts, fmt.Sprintf("**Mode:** %s", params.EditMode))
+			}
+			return strings.Join(parts, "\n")
+		}
+	case tools.MoveToolName, tools.CopyToolName:
+		var params tools.MoveParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			return fmt.Sprintf("**From:** %s\n**To:** %s", fsext.PrettyPath(params.SourcePath), fsext.PrettyPath(params.DestinationPath))
+		}
+	case tools.DeleteToolName:
+		var params tools.DeleteParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(params.Path))
+		}
+	case tools.MemoryToolName:
+		var params tools.MemoryParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			parts := []string{fmt.Sprintf("**Action:** %s", params.Action)}
+			if params.OldEntry != "" {
+				parts = append(parts, fmt.Sprintf("**Old Entry:** %s", params.OldEntry))
+			}
+			if params.Entry != "" {
+				parts = append(parts, fmt.Sprintf("**Entry:** %s", params.Entry))
+			}
+			return strings.Join(parts, "\n")
+		}
 	case tools.WriteToolName:
 		var params tools.WriteParams
 		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
@@ -954,6 +1007,15 @@ func (t *baseToolMessageItem) formatParametersForCopy() string {
 			}
 			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(path))
 		}
+	case tools.RepoMapToolName:
+		var params tools.RepoMapParams
+		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
+			path := params.Path
+			if path == "" {
+				path = "."
+			}
+			return fmt.Sprintf("**Path:** %s", fsext.PrettyPath(path))
+		}
 	case tools.DownloadToolName:
 		var params tools.DownloadParams
 		if json.Unmarshal([]byte(t.toolCall.Input), &params) == nil {
@@ -1021,7 +1083,7 @@ func (t *baseToolMessageItem) formatResultForCopy() string {
 		return t.formatBashResultForCopy()
 	case tools.ViewToolName:
 		return t.formatViewResultForCopy()
-	case tools.EditToolName:
+	case tools.EditToolName, tools.NotebookEditToolName, tools.MemoryToolName:
 		return t.formatEditResultForCopy()
 	case tools.MultiEditToolName:
 		return t.formatMultiEditResultForCopy()
@@ -1035,7 +1097,7 @@ func (t *baseToolMessageItem) formatResultForCopy() string {
 		return t.formatWebFetchResultForCopy()
 	case agent.AgentToolName:
 		return t.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.RepoMapToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName, tools.TodosToolName:
 		return fmt.Sprintf("```\n%s\n```", t.result.Content)
 	default:
 		return t.result.Content
@@ -1365,6 +1427,16 @@ func prettifyToolName(name string) string {
 		return "Edit"
 	case tools.MultiEditToolName:
 		return "Multi-Edit"
+	case tools.NotebookEditToolName:
+		return "Notebook Edit"
+	case tools.MoveToolName:
+		return "Move"
+	case tools.CopyToolName:
+		return "Copy"
+	case tools.DeleteToolName:
+		return "Delete"
+	case tools.MemoryToolName:
+		return "Memory"
 	case tools.FetchToolName:
 		return "Fetch"
 	case tools.AgenticFetchToolName:
@@ -1379,10 +1451,16 @@ func prettifyToolName(name string) string {
 		return "Grep"
 	case tools.LSToolName:
 		return "List"
+	case tools.RepoMapToolName:
+		return "Repo Map"
+	case tools.ReadMCPResourceToolName:
+		return "MCP Resource"
 	case tools.SourcegraphToolName:
 		return "Sourcegraph"
 	case tools.TodosToolName:
 		return "To-Do"
+	case tools.ToolSearchToolName:
+		return "Tool Search"
 	case tools.ViewToolName:
 		return "View"
 	case tools.WriteToolName:
@@ -7,6 +7,7 @@ import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/ui/list"
 	"github.com/charmbracelet/x/ansi"
@@ -29,9 +30,11 @@ type SelectionMsg struct {
 // ClosedMsg is sent when the completions are closed.
 type ClosedMsg struct{}
 
-// FilesLoadedMsg is sent when files have been loaded for completions.
+// FilesLoadedMsg is sent when files and MCP resources have been loaded for
+// completions.
 type FilesLoadedMsg struct {
-	Files []string
+	Files     []string
+	Resources []ResourceCompletionValue
 }
 
 // Completions represents the completions popup component.
@@ -92,18 +95,29 @@ func (c *Completions) KeyMap() KeyMap {
 	return c.keyMap
 }
 
-// OpenWithFiles opens the completions with file items from the filesystem.
+// OpenWithFiles opens the completions with file items from the filesystem
+// and the resources of the connected MCP servers.
 func (c *Completions) OpenWithFiles(depth, limit int) tea.Cmd {
 	return func() tea.Msg {
 		files, _, _ := fsext.ListDirectory(".", nil, depth, limit)
 		slices.Sort(files)
-		return FilesLoadedMsg{Files: files}
+
+		var resources []ResourceCompletionValue
+		for name, rs := range mcp.Resources() {
+			for _, r := range rs {
+				resources = append(resources, ResourceCompletionValue{MCP: name, URI: r.URI})
+			}
+		}
+		slices.SortFunc(resources, func(a, b ResourceCompletionValue) int {
+			return strings.Compare(a.MCP+":"+a.URI, b.MCP+":"+b.URI)
+		})
+		return FilesLoadedMsg{Files: files, Resources: resources}
 	}
 }
 
-// SetFiles sets the file items on the completions popup.
-func (c *Completions) SetFiles(files []string) {
-	items := make([]list.FilterableItem, 0, len(files))
+// SetFiles sets the file and MCP resource items on the completions popup.
+func (c *Completions) SetFiles(files []string, resources []ResourceCompletionValue) {
+	items := make([]list.FilterableItem, 0, len(files)+len(resources))
 	for _, file := range files {
 		file = strings.TrimPrefix(file, "./")
 		item := NewCompletionItem(
@@ -115,6 +129,16 @@ func (c *Completions) SetFiles(files []string) {
 		)
 		items = append(items, item)
 	}
+	for _, r := range resources {
+		item := NewCompletionItem(
+			r.MCP+":"+r.URI,
+			r,
+			c.normalStyle,
+			c.focusedStyle,
+			c.matchStyle,
+		)
+		items = append(items, item)
+	}
 
 	c.open = true
 	c.query = ""
@@ -132,8 +156,8 @@ func (c *Completions) SetFiles(files []string) {
 	start, end := c.list.VisibleItemIndices()
 	width := 0
 	if end != 0 {
-		for _, file := range files[start : end+1] {
-			width = max(width, ansi.StringWidth(file))
+		for _, item := range items[start : end+1] {
+			width = max(width, ansi.StringWidth(item.(*CompletionItem).Text()))
 		}
 	}
 	c.width = ordered.Clamp(width+2, int(minWidth), int(maxWidth))
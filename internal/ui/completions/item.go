@@ -13,6 +13,12 @@ type FileCompletionValue struct {
 	Path string
 }
 
+// ResourceCompletionValue represents an MCP resource completion value.
+type ResourceCompletionValue struct {
+	MCP string
+	URI string
+}
+
 // CompletionItem represents an item in the completions list.
 type CompletionItem struct {
 	text    string
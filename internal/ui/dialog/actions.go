@@ -8,6 +8,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/commands"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/message"
@@ -64,10 +65,34 @@ type (
 	}
 	// ActionRunCustomCommand is a message to run a custom command.
 	ActionRunCustomCommand struct {
-		Content   string
-		Arguments []commands.Argument
-		Args      map[string]string // Actual argument values
+		Command commands.CustomCommand
+		Args    map[string]string // Actual argument values
 	}
+	// ActionLoginMCP is a message to authorize Crush with an MCP server.
+	ActionLoginMCP struct {
+		Name string
+	}
+	// ActionRestartMCP is a message to reconnect to an MCP server.
+	ActionRestartMCP struct {
+		Name string
+	}
+	// ActionSetMCPDisabled is a message to disable or enable an MCP server.
+	ActionSetMCPDisabled struct {
+		Name     string
+		Disabled bool
+	}
+	// ActionViewMCPLogs is a message to show the logs of an MCP server.
+	ActionViewMCPLogs struct {
+		Name string
+	}
+	// ActionSwitchProfile is a message to reload the config with another
+	// profile, or with none when Name is empty.
+	ActionSwitchProfile struct {
+		Name string
+	}
+	// ActionDiscoverModels is a message to list the models of the providers
+	// with discover_models set again.
+	ActionDiscoverModels struct{}
 	// ActionRunMCPPrompt is a message to run a custom command.
 	ActionRunMCPPrompt struct {
 		Title       string
@@ -77,6 +102,13 @@ type (
 		Arguments   []commands.Argument
 		Args        map[string]string // Actual argument values
 	}
+	// ActionRespondElicitation is a message to answer the request of an MCP
+	// server for input.
+	ActionRespondElicitation struct {
+		Request mcp.ElicitationRequest
+		Action  string            // One of the mcp.Elicitation actions
+		Args    map[string]string // Values of the fields when accepted
+	}
 )
 
 // Messages for API key input dialog.
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/commands"
 	"github.com/charmbracelet/crush/internal/ui/common"
 	"github.com/charmbracelet/crush/internal/uiutil"
@@ -98,6 +99,7 @@ func NewArguments(com *common.Common, title, description string, arguments []com
 		} else {
 			input.Placeholder = arg.Title
 		}
+		input.SetValue(arg.Default)
 
 		if i == 0 {
 			input.Focus()
@@ -122,6 +124,9 @@ func (a *Arguments) ID() string {
 
 // focusInput changes focus to a new input by index with wrap-around.
 func (a *Arguments) focusInput(newIndex int) {
+	if len(a.inputs) == 0 {
+		return
+	}
 	a.inputs[a.focused].Blur()
 
 	// Wrap around: Go's modulo can return negative, so add len first.
@@ -193,10 +198,14 @@ func (a *Arguments) HandleMsg(msg tea.Msg) Action {
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, a.keyMap.Close):
+			if action, ok := a.resultAction.(ActionRespondElicitation); ok {
+				action.Action = mcp.ElicitationCancel
+				return action
+			}
 			return ActionClose{}
 		case key.Matches(msg, a.keyMap.Confirm):
 			// If we're on the last input or there's only one input, submit.
-			if a.focused == len(a.inputs)-1 || len(a.inputs) == 1 {
+			if a.focused >= len(a.inputs)-1 {
 				args := make(map[string]string)
 				var warning tea.Cmd
 				for i, arg := range a.arguments {
@@ -217,6 +226,10 @@ func (a *Arguments) HandleMsg(msg tea.Msg) Action {
 				case ActionRunMCPPrompt:
 					action.Args = args
 					return action
+				case ActionRespondElicitation:
+					action.Action = mcp.ElicitationAccept
+					action.Args = args
+					return action
 				}
 			}
 			a.focusInput(a.focused + 1)
@@ -225,6 +238,9 @@ func (a *Arguments) HandleMsg(msg tea.Msg) Action {
 		case key.Matches(msg, a.keyMap.Previous):
 			a.focusInput(a.focused - 1)
 		default:
+			if len(a.inputs) == 0 {
+				break
+			}
 			var cmd tea.Cmd
 			a.inputs[a.focused], cmd = a.inputs[a.focused].Update(msg)
 			return ActionCmd{Cmd: cmd}
@@ -236,6 +252,9 @@ func (a *Arguments) HandleMsg(msg tea.Msg) Action {
 			a.focusInput(a.findVisibleFieldByOffset(msg.Button == tea.MouseWheelDown))
 		}
 	case tea.PasteMsg:
+		if len(a.inputs) == 0 {
+			break
+		}
 		var cmd tea.Cmd
 		a.inputs[a.focused], cmd = a.inputs[a.focused].Update(msg)
 		return ActionCmd{Cmd: cmd}
@@ -246,6 +265,9 @@ func (a *Arguments) HandleMsg(msg tea.Msg) Action {
 // Cursor returns the cursor position relative to the dialog.
 // we pass the description height to offset the cursor correctly.
 func (a *Arguments) Cursor(descriptionHeight int) *tea.Cursor {
+	if len(a.inputs) == 0 {
+		return nil
+	}
 	cursor := InputCursor(a.com.Styles, a.inputs[a.focused].Cursor())
 	if cursor == nil {
 		return nil
@@ -9,6 +9,7 @@ import (
 	"charm.land/bubbles/v2/spinner"
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/commands"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/ui/common"
@@ -357,10 +358,11 @@ func (c *Commands) setCommandItems(commandType CommandType) {
 	case UserCommands:
 		for _, cmd := range c.customCommands {
 			action := ActionRunCustomCommand{
-				Content:   cmd.Content,
-				Arguments: cmd.Arguments,
+				Command: cmd,
 			}
-			commandItems = append(commandItems, NewCommandItem(c.com.Styles, "custom_"+cmd.ID, cmd.Name, "", action))
+			item := NewCommandItem(c.com.Styles, "custom_"+cmd.ID, cmd.Name, "", action)
+			item.SetDescription(cmd.Description)
+			commandItems = append(commandItems, item)
 		}
 	case MCPPrompts:
 		for _, cmd := range c.mcpPrompts {
@@ -371,7 +373,9 @@ func (c *Commands) setCommandItems(commandType CommandType) {
 				ClientID:    cmd.ClientID,
 				Arguments:   cmd.Arguments,
 			}
-			commandItems = append(commandItems, NewCommandItem(c.com.Styles, "mcp_"+cmd.ID, cmd.PromptID, "", action))
+			item := NewCommandItem(c.com.Styles, "mcp_"+cmd.ID, cmd.PromptID, "", action)
+			item.SetDescription(cmd.Description)
+			commandItems = append(commandItems, item)
 		}
 	}
 
@@ -441,6 +445,41 @@ func (c *Commands) defaultCommands() []*CommandItem {
 		commands = append(commands, NewCommandItem(c.com.Styles, "open_external_editor", "Open External Editor", "ctrl+o", ActionExternalEditor{}))
 	}
 
+	// Add commands to manage each MCP server, and to log in to the ones
+	// waiting for authorization.
+	for _, m := range c.com.Config().MCP.Sorted() {
+		state, _ := mcp.GetState(m.Name)
+		if state.State == mcp.StateNeedsAuth {
+			commands = append(commands, NewCommandItem(c.com.Styles, "mcp_login_"+m.Name, "Log In to MCP "+m.Name, "", ActionLoginMCP{Name: m.Name}))
+		}
+		if m.MCP.Disabled {
+			commands = append(commands, NewCommandItem(c.com.Styles, "mcp_enable_"+m.Name, "Enable MCP "+m.Name, "", ActionSetMCPDisabled{Name: m.Name}))
+		} else {
+			commands = append(commands,
+				NewCommandItem(c.com.Styles, "mcp_restart_"+m.Name, "Restart MCP "+m.Name, "", ActionRestartMCP{Name: m.Name}),
+				NewCommandItem(c.com.Styles, "mcp_disable_"+m.Name, "Disable MCP "+m.Name, "", ActionSetMCPDisabled{Name: m.Name, Disabled: true}),
+			)
+		}
+		commands = append(commands, NewCommandItem(c.com.Styles, "mcp_logs_"+m.Name, "View MCP "+m.Name+" Logs", "", ActionViewMCPLogs{Name: m.Name}))
+	}
+
+	// Add commands to switch to the other config profiles.
+	for _, name := range cfg.ProfileNames() {
+		if name != cfg.Profile() {
+			commands = append(commands, NewCommandItem(c.com.Styles, "profile_"+name, "Switch to Profile "+name, "", ActionSwitchProfile{Name: name}))
+		}
+	}
+	if cfg.Profile() != "" {
+		commands = append(commands, NewCommandItem(c.com.Styles, "profile_none", "Switch to No Profile", "", ActionSwitchProfile{}))
+	}
+
+	for p := range cfg.Providers.Seq() {
+		if p.DiscoverModels && !p.Disable {
+			commands = append(commands, NewCommandItem(c.com.Styles, "discover_models", "Discover Models", "", ActionDiscoverModels{}))
+			break
+		}
+	}
+
 	return append(commands,
 		NewCommandItem(c.com.Styles, "toggle_yolo", "Toggle Yolo Mode", "", ActionToggleYoloMode{}),
 		NewCommandItem(c.com.Styles, "toggle_help", "Toggle Help", "ctrl+g", ActionToggleHelp{}),
@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"github.com/charmbracelet/crush/internal/ui/styles"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/sahilm/fuzzy"
 )
 
@@ -10,11 +11,14 @@ type CommandItem struct {
 	id       string
 	title    string
 	shortcut string
-	action   Action
-	t        *styles.Styles
-	m        fuzzy.Match
-	cache    map[int]string
-	focused  bool
+	// description is shown in place of the shortcut, for commands that
+	// don't have one.
+	description string
+	action      Action
+	t           *styles.Styles
+	m           fuzzy.Match
+	cache       map[int]string
+	focused     bool
 }
 
 var _ ListItem = &CommandItem{}
@@ -64,6 +68,12 @@ func (c *CommandItem) Shortcut() string {
 	return c.shortcut
 }
 
+// SetDescription sets the description shown next to the title.
+func (c *CommandItem) SetDescription(description string) {
+	c.cache = nil
+	c.description = description
+}
+
 // Render implements ListItem.
 func (c *CommandItem) Render(width int) string {
 	styles := ListItemStyles{
@@ -72,5 +82,9 @@ func (c *CommandItem) Render(width int) string {
 		InfoTextBlurred: c.t.Base,
 		InfoTextFocused: c.t.Base,
 	}
-	return renderItem(styles, c.title, c.shortcut, c.focused, width, c.cache, &c.m)
+	info := c.shortcut
+	if info == "" && c.description != "" {
+		info = ansi.Truncate(c.description, max(0, width/2), "…")
+	}
+	return renderItem(styles, c.title, info, c.focused, width, c.cache, &c.m)
 }
@@ -0,0 +1,139 @@
+package dialog
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/ui/common"
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+const (
+	// MCPLogsID is the identifier for the MCP logs dialog.
+	MCPLogsID             = "mcp_logs"
+	mcpLogsDialogMaxWidth = defaultDialogMaxWidth
+)
+
+// MCPLogs is a dialog that shows what an MCP server wrote to stderr or
+// logged.
+type MCPLogs struct {
+	com      *common.Common
+	name     string
+	help     help.Model
+	viewport viewport.Model
+	width    int
+
+	keyMap struct {
+		Scroll  key.Binding
+		Refresh key.Binding
+		Close   key.Binding
+	}
+}
+
+var _ Dialog = (*MCPLogs)(nil)
+
+// NewMCPLogs creates a new dialog with the logs of the MCP server name.
+func NewMCPLogs(com *common.Common, name string) *MCPLogs {
+	l := &MCPLogs{com: com, name: name}
+
+	l.help = help.New()
+	l.help.Styles = com.Styles.DialogHelpStyles()
+
+	l.keyMap.Scroll = key.NewBinding(
+		key.WithKeys("up", "down", "pgup", "pgdown"),
+		key.WithHelp("↑/↓", "scroll"),
+	)
+	l.keyMap.Refresh = key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "refresh"),
+	)
+	l.keyMap.Close = CloseKey
+
+	l.viewport = viewport.New()
+	return l
+}
+
+// ID implements [Dialog].
+func (*MCPLogs) ID() string {
+	return MCPLogsID
+}
+
+// HandleMsg implements [Dialog].
+func (l *MCPLogs) HandleMsg(msg tea.Msg) Action {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, l.keyMap.Close):
+			return ActionClose{}
+		case key.Matches(msg, l.keyMap.Refresh):
+			l.refresh()
+			l.viewport.GotoBottom()
+		default:
+			l.viewport, _ = l.viewport.Update(msg)
+		}
+	case tea.MouseWheelMsg:
+		l.viewport, _ = l.viewport.Update(msg)
+	}
+	return nil
+}
+
+// refresh loads the latest lines of the log.
+func (l *MCPLogs) refresh() {
+	lines := mcp.Log(l.name)
+	if len(lines) == 0 {
+		l.viewport.SetContent(l.com.Styles.Subtle.Render("Nothing logged yet."))
+		return
+	}
+	l.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// Draw implements [Dialog].
+func (l *MCPLogs) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
+	t := l.com.Styles
+	width := max(0, min(mcpLogsDialogMaxWidth, area.Dx()))
+	height := max(0, min(defaultDialogHeight, area.Dy()))
+	innerWidth := width - t.Dialog.View.GetHorizontalFrameSize()
+	heightOffset := t.Dialog.Title.GetVerticalFrameSize() + titleContentHeight +
+		t.Dialog.HelpView.GetVerticalFrameSize() + 1 +
+		t.Dialog.View.GetVerticalFrameSize()
+
+	l.help.SetWidth(innerWidth)
+	l.viewport.SetHeight(max(0, height-heightOffset))
+	if l.width != innerWidth-1 {
+		// Load the log the first time, and wrap it again when resized.
+		l.width = innerWidth - 1
+		l.viewport.SetWidth(l.width)
+		l.viewport.SoftWrap = true
+		l.refresh()
+		l.viewport.GotoBottom()
+	}
+
+	content := l.viewport.View()
+	if l.viewport.TotalLineCount() > l.viewport.Height() {
+		scrollbar := common.Scrollbar(t, l.viewport.Height(), l.viewport.TotalLineCount(), l.viewport.Height(), l.viewport.YOffset())
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, scrollbar)
+	}
+
+	rc := NewRenderContext(t, width)
+	rc.Title = l.name + " Logs"
+	rc.AddPart(content)
+	rc.Help = l.help.View(l)
+
+	DrawCenterCursor(scr, area, rc.Render(), nil)
+	return nil
+}
+
+// ShortHelp implements [help.KeyMap].
+func (l *MCPLogs) ShortHelp() []key.Binding {
+	return []key.Binding{l.keyMap.Scroll, l.keyMap.Refresh, l.keyMap.Close}
+}
+
+// FullHelp implements [help.KeyMap].
+func (l *MCPLogs) FullHelp() [][]key.Binding {
+	return [][]key.Binding{l.ShortHelp()}
+}
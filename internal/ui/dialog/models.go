@@ -378,6 +378,7 @@ func (m *Models) setProviderItems() error {
 			group := NewModelGroup(t, name, true)
 			for _, model := range p.Models {
 				item := NewModelItem(t, provider, model, m.modelType, false)
+				item.discovered = slices.Contains(p.DiscoveredModels, model.ID)
 				group.AppendItems(item)
 				itemsMap[item.ID()] = item
 				if model.ID == currentModel.Model && string(provider.ID) == currentModel.Provider {
@@ -470,8 +471,10 @@ func (m *Models) setProviderItems() error {
 			}
 
 			// Show provider for recent items
+			discovered := item.discovered
 			item = NewModelItem(t, item.prov, item.model, m.modelType, true)
 			item.showProvider = true
+			item.discovered = discovered
 
 			validRecentItems = append(validRecentItems, recent)
 			recentGroup.AppendItems(item)
@@ -1,6 +1,8 @@
 package dialog
 
 import (
+	"strings"
+
 	"charm.land/catwalk/pkg/catwalk"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/crush/internal/config"
@@ -59,6 +61,9 @@ type ModelItem struct {
 	m            fuzzy.Match
 	focused      bool
 	showProvider bool
+	// discovered tells whether the model was listed by the provider rather
+	// than configured.
+	discovered bool
 }
 
 // SelectedModel returns this model item as a [config.SelectedModel] instance.
@@ -102,9 +107,12 @@ func (m *ModelItem) ID() string {
 
 // Render implements ListItem.
 func (m *ModelItem) Render(width int) string {
-	var providerInfo string
+	var info []string
 	if m.showProvider {
-		providerInfo = string(m.prov.Name)
+		info = append(info, string(m.prov.Name))
+	}
+	if m.discovered {
+		info = append(info, "discovered")
 	}
 	styles := ListItemStyles{
 		ItemBlurred:     m.t.Dialog.NormalItem,
@@ -112,7 +120,7 @@ func (m *ModelItem) Render(width int) string {
 		InfoTextBlurred: m.t.Base,
 		InfoTextFocused: m.t.Base,
 	}
-	return renderItem(styles, m.model.Name, providerInfo, m.focused, width, m.cache, &m.m)
+	return renderItem(styles, m.model.Name, strings.Join(info, " · "), m.focused, width, m.cache, &m.m)
 }
 
 // SetFocused implements ListItem.
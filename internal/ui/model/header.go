@@ -85,6 +85,10 @@ func renderHeaderDetails(
 		parts = append(parts, t.LSP.ErrorDiagnostic.Render(fmt.Sprintf("%s%d", styles.ErrorIcon, errorCount)))
 	}
 
+	if profile := config.Get().Profile(); profile != "" {
+		parts = append(parts, t.Header.KeystrokeTip.Render("profile ")+t.Header.Percentage.Render(profile))
+	}
+
 	agentCfg := config.Get().Agents[config.AgentCoder]
 	model := config.Get().GetModelByType(agentCfg.Model)
 	percentage := (float64(session.CompletionTokens+session.PromptTokens) / float64(model.ContextWindow)) * 100
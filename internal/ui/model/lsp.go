@@ -2,6 +2,8 @@ package model
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"charm.land/lipgloss/v2"
@@ -23,14 +25,11 @@ type LSPInfo struct {
 func (m *UI) lspInfo(width, maxItems int, isSection bool) string {
 	var lsps []LSPInfo
 	t := m.com.Styles
-	lspConfigs := m.com.Config().LSP.Sorted()
-
-	for _, cfg := range lspConfigs {
-		state, ok := m.lspStates[cfg.Name]
-		if !ok {
-			continue
-		}
+	states := slices.SortedFunc(maps.Values(m.lspStates), func(a, b app.LSPClientInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
+	for _, state := range states {
 		client, ok := m.com.App.LSPClients.Get(state.Name)
 		if !ok {
 			continue
@@ -86,6 +85,9 @@ func lspList(t *styles.Styles, lsps []LSPInfo, width, maxItems int) string {
 	for _, l := range lsps {
 		var icon string
 		title := l.Name
+		if l.Client != nil {
+			title = l.Client.GetName()
+		}
 		var description string
 		var diagnostics string
 		switch l.State {
@@ -94,6 +96,7 @@ func lspList(t *styles.Styles, lsps []LSPInfo, width, maxItems int) string {
 			description = t.Subtle.Render("starting...")
 		case lsp.StateReady:
 			icon = t.ItemOnlineIcon.String()
+			description = t.Subtle.Render(lspRoot(l.Root))
 			diagnostics = lspDiagnostics(t, l.Diagnostics)
 		case lsp.StateError:
 			icon = t.ItemErrorIcon.String()
@@ -123,3 +126,11 @@ func lspList(t *styles.Styles, lsps []LSPInfo, width, maxItems int) string {
 	}
 	return lipgloss.JoinVertical(lipgloss.Left, renderedLsps...)
 }
+
+// lspRoot formats the project root of an LSP client for display.
+func lspRoot(root string) string {
+	if root == "" || root == "." {
+		return "./"
+	}
+	return "./" + strings.TrimSuffix(root, "/") + "/"
+}
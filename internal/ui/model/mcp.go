@@ -1,15 +1,24 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/ui/common"
 	"github.com/charmbracelet/crush/internal/ui/styles"
+	"github.com/charmbracelet/crush/internal/uiutil"
+	"github.com/pkg/browser"
 )
 
+// mcpLoginTimeout is how long to wait for the user to authorize Crush with
+// an MCP server in the browser.
+const mcpLoginTimeout = 5 * time.Minute
+
 // mcpInfo renders the MCP status section showing active MCP clients and their
 // tool/prompt counts.
 func (m *UI) mcpInfo(width, maxItems int, isSection bool) string {
@@ -43,6 +52,9 @@ func mcpCounts(t *styles.Styles, counts mcp.Counts) string {
 	if counts.Prompts > 0 {
 		parts = append(parts, t.Subtle.Render(fmt.Sprintf("%d prompts", counts.Prompts)))
 	}
+	if counts.Resources > 0 {
+		parts = append(parts, t.Subtle.Render(fmt.Sprintf("%d resources", counts.Resources)))
+	}
 	return strings.Join(parts, " ")
 }
 
@@ -73,6 +85,9 @@ func mcpList(t *styles.Styles, mcps []mcp.ClientInfo, width, maxItems int) strin
 			if m.Error != nil {
 				description = t.Subtle.Render(fmt.Sprintf("error: %s", m.Error.Error()))
 			}
+		case mcp.StateNeedsAuth:
+			icon = t.ItemBusyIcon.String()
+			description = t.Subtle.Render("needs auth, log in from the commands")
 		case mcp.StateDisabled:
 			icon = t.ItemOfflineIcon.Foreground(t.Muted.GetBackground()).String()
 			description = t.Subtle.Render("disabled")
@@ -96,3 +111,47 @@ func mcpList(t *styles.Styles, mcps []mcp.ClientInfo, width, maxItems int) strin
 	}
 	return lipgloss.JoinVertical(lipgloss.Left, renderedMcps...)
 }
+
+// loginMCP authorizes Crush with the MCP server name in the browser and
+// reconnects to it.
+func (m *UI) loginMCP(name string) tea.Cmd {
+	login := func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), mcpLoginTimeout)
+		defer cancel()
+		err := m.com.App.LoginMCP(ctx, name, browser.OpenURL)
+		if err != nil {
+			return uiutil.NewErrorMsg(fmt.Errorf("logging in to %s: %w", name, err))
+		}
+		return uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: fmt.Sprintf("Logged in to %s", name)}
+	}
+	return tea.Sequence(
+		uiutil.ReportInfo(fmt.Sprintf("Authorize Crush with %s in your browser...", name)),
+		login,
+	)
+}
+
+// restartMCP reconnects to the MCP server name.
+func (m *UI) restartMCP(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.com.App.RestartMCP(context.Background(), name); err != nil {
+			return uiutil.NewErrorMsg(fmt.Errorf("restarting %s: %w", name, err))
+		}
+		if state, ok := mcp.GetState(name); ok && state.State == mcp.StateError {
+			return uiutil.NewErrorMsg(fmt.Errorf("restarting %s: %w", name, state.Error))
+		}
+		return uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: fmt.Sprintf("Restarted %s", name)}
+	}
+}
+
+// setMCPDisabled disables or enables the MCP server name.
+func (m *UI) setMCPDisabled(name string, disabled bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.com.App.SetMCPDisabled(context.Background(), name, disabled); err != nil {
+			return uiutil.NewErrorMsg(fmt.Errorf("updating %s: %w", name, err))
+		}
+		if disabled {
+			return uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: fmt.Sprintf("Disabled %s", name)}
+		}
+		return uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: fmt.Sprintf("Enabled %s", name)}
+	}
+}
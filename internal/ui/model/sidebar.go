@@ -115,6 +115,9 @@ func (m *UI) drawSidebar(scr uv.Screen, area uv.Rectangle) {
 
 	title := t.Muted.Width(width).MaxHeight(2).Render(m.session.Title)
 	cwd := common.PrettyPath(t, m.com.Config().WorkingDir(), width)
+	if profile := m.com.Config().Profile(); profile != "" {
+		cwd += "\n" + t.Subtle.Render("Profile ") + t.Muted.Render(profile)
+	}
 	sidebarLogo := m.sidebarLogo
 	if height < logoHeightBreakpoint {
 		sidebarLogo = logo.SmallRender(width)
@@ -2,6 +2,7 @@ package model
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/catwalk/pkg/catwalk"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/agent"
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/commands"
@@ -31,6 +33,7 @@ import (
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/memory"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
@@ -486,6 +489,13 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pubsub.Event[app.LSPEvent]:
 		m.lspStates = app.GetLSPStates()
 	case pubsub.Event[mcp.Event]:
+		if msg.Payload.Type == mcp.EventResourcesListChanged {
+			name := msg.Payload.Name
+			cmds = append(cmds, func() tea.Msg {
+				mcp.RefreshResources(context.Background(), name)
+				return nil
+			})
+		}
 		m.mcpStates = mcp.GetStates()
 		// check if all mcps are initialized
 		initialized := true
@@ -498,6 +508,12 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if initialized && m.mcpPrompts == nil {
 			cmds = append(cmds, m.loadMCPrompts())
 		}
+	case pubsub.Event[mcp.ElicitationRequest]:
+		m.openElicitationDialog(msg.Payload)
+	case pubsub.Event[mcp.ToolProgress]:
+		if item, ok := m.chat.MessageItem(msg.Payload.ToolCallID).(*chat.MCPToolMessageItem); ok {
+			item.SetProgress(msg.Payload)
+		}
 	case pubsub.Event[permission.PermissionRequest]:
 		if cmd := m.openPermissionsDialog(msg.Payload); cmd != nil {
 			cmds = append(cmds, cmd)
@@ -680,12 +696,16 @@ func (m *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			ttl = DefaultStatusTTL
 		}
 		cmds = append(cmds, clearInfoMsgCmd(ttl))
+	case profileSwitchedMsg:
+		// The header shows the profile.
+		m.updateLayoutAndSize()
+		cmds = append(cmds, uiutil.CmdHandler(msg.info))
 	case uiutil.ClearStatusMsg:
 		m.status.ClearInfoMsg()
 	case completions.FilesLoadedMsg:
 		// Handle async file loading for completions.
 		if m.completionsOpen {
-			m.completions.SetFiles(msg.Files)
+			m.completions.SetFiles(msg.Files, msg.Resources)
 		}
 	case uv.KittyGraphicsEvent:
 		if !bytes.HasPrefix(msg.Payload, []byte("OK")) {
@@ -1190,6 +1210,24 @@ func (m *UI) handleDialogMsg(msg tea.Msg) tea.Cmd {
 		m.dialog.CloseDialog(dialog.CommandsID)
 	case dialog.ActionQuit:
 		cmds = append(cmds, tea.Quit)
+	case dialog.ActionLoginMCP:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		cmds = append(cmds, m.loginMCP(msg.Name))
+	case dialog.ActionRestartMCP:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		cmds = append(cmds, uiutil.ReportInfo(fmt.Sprintf("Restarting %s...", msg.Name)), m.restartMCP(msg.Name))
+	case dialog.ActionSetMCPDisabled:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		cmds = append(cmds, m.setMCPDisabled(msg.Name, msg.Disabled))
+	case dialog.ActionViewMCPLogs:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		m.dialog.OpenDialog(dialog.NewMCPLogs(m.com, msg.Name))
+	case dialog.ActionSwitchProfile:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		cmds = append(cmds, m.switchProfile(msg.Name))
+	case dialog.ActionDiscoverModels:
+		m.dialog.CloseDialog(dialog.CommandsID)
+		cmds = append(cmds, uiutil.ReportInfo("Discovering models..."), m.discoverModels())
 	case dialog.ActionInitializeProject:
 		if m.isAgentBusy() {
 			cmds = append(cmds, uiutil.ReportWarn("Agent is busy, please wait before summarizing session..."))
@@ -1311,23 +1349,19 @@ func (m *UI) handleDialogMsg(msg tea.Msg) tea.Cmd {
 		))
 
 	case dialog.ActionRunCustomCommand:
-		if len(msg.Arguments) > 0 && msg.Args == nil {
+		if len(msg.Command.Arguments) > 0 && msg.Args == nil {
 			m.dialog.CloseFrontDialog()
 			argsDialog := dialog.NewArguments(
 				m.com,
 				"Custom Command Arguments",
-				"",
-				msg.Arguments,
+				msg.Command.Description,
+				msg.Command.Arguments,
 				msg, // Pass the action as the result
 			)
 			m.dialog.OpenDialog(argsDialog)
 			break
 		}
-		content := msg.Content
-		if msg.Args != nil {
-			content = substituteArgs(content, msg.Args)
-		}
-		cmds = append(cmds, m.sendMessage(content))
+		cmds = append(cmds, m.runCustomCommand(msg.Command, msg.Args))
 		m.dialog.CloseFrontDialog()
 	case dialog.ActionRunMCPPrompt:
 		if len(msg.Arguments) > 0 && msg.Args == nil {
@@ -1347,6 +1381,16 @@ func (m *UI) handleDialogMsg(msg tea.Msg) tea.Cmd {
 			break
 		}
 		cmds = append(cmds, m.runMCPPrompt(msg.ClientID, msg.PromptID, msg.Args))
+	case dialog.ActionRespondElicitation:
+		if err := mcp.RespondElicitation(msg.Request.ID, msg.Action, msg.Args); err != nil {
+			if msg.Action == mcp.ElicitationAccept {
+				// Keep the form open so the user can fix the values.
+				cmds = append(cmds, uiutil.ReportWarn(err.Error()))
+				break
+			}
+			slog.Warn("Failed to answer MCP elicitation", "error", err)
+		}
+		m.dialog.CloseFrontDialog()
 	default:
 		cmds = append(cmds, uiutil.CmdHandler(msg))
 	}
@@ -1354,15 +1398,6 @@ func (m *UI) handleDialogMsg(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-// substituteArgs replaces $ARG_NAME placeholders in content with actual values.
-func substituteArgs(content string, args map[string]string) string {
-	for name, value := range args {
-		placeholder := "$" + name
-		content = strings.ReplaceAll(content, placeholder, value)
-	}
-	return content
-}
-
 func (m *UI) openAuthenticationDialog(provider catwalk.Provider, model config.SelectedModel, modelType config.SelectedModelType) tea.Cmd {
 	var (
 		dlg dialog.Dialog
@@ -1488,8 +1523,11 @@ func (m *UI) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 					switch msg := msg.(type) {
 					case completions.SelectionMsg:
 						// Handle file completion selection.
-						if item, ok := msg.Value.(completions.FileCompletionValue); ok {
+						switch item := msg.Value.(type) {
+						case completions.FileCompletionValue:
 							cmds = append(cmds, m.insertFileCompletion(item.Path))
+						case completions.ResourceCompletionValue:
+							cmds = append(cmds, m.insertResourceCompletion(item))
 						}
 						if !msg.Insert {
 							m.closeCompletions()
@@ -1533,6 +1571,11 @@ func (m *UI) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 					return nil
 				}
 
+				if entry, ok := memory.ParseShortcut(value); ok && len(attachments) == 0 {
+					m.historyReset()
+					return m.saveMemory(entry)
+				}
+
 				m.randomizePlaceholders()
 				m.historyReset()
 
@@ -2491,6 +2534,31 @@ func (m *UI) insertFileCompletion(path string) tea.Cmd {
 	}
 }
 
+// insertResourceCompletion inserts the selected MCP resource into the
+// textarea, replacing the @query, and adds its contents as an attachment.
+func (m *UI) insertResourceCompletion(r completions.ResourceCompletionValue) tea.Cmd {
+	value := m.textarea.Value()
+	word := m.textareaWord()
+
+	if m.completionsStartIndex > len(value) {
+		return nil
+	}
+
+	endIdx := min(m.completionsStartIndex+len(word), len(value))
+	newValue := value[:m.completionsStartIndex] + r.MCP + ":" + r.URI + value[endIdx:]
+	m.textarea.SetValue(newValue)
+	m.textarea.MoveToEnd()
+	m.textarea.InsertRune(' ')
+
+	return func() tea.Msg {
+		attachment, err := mcp.ReadResourceAttachment(context.Background(), r.MCP, r.URI)
+		if err != nil {
+			return uiutil.NewErrorMsg(fmt.Errorf("reading %s:%s: %w", r.MCP, r.URI, err))
+		}
+		return attachment
+	}
+}
+
 // completionsPosition returns the X and Y position for the completions popup.
 func (m *UI) completionsPosition() image.Point {
 	cur := m.textarea.Cursor()
@@ -2579,14 +2647,89 @@ func (m *UI) renderHeader(compact bool, width int) {
 	}
 }
 
+// profileSwitchedMsg is sent once the config was reloaded with another
+// profile.
+type profileSwitchedMsg struct {
+	info uiutil.InfoMsg
+}
+
+// switchProfile reloads the config with the profile name, or with no profile
+// when name is empty.
+func (m *UI) switchProfile(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.com.App.SwitchProfile(context.Background(), name); err != nil {
+			return uiutil.NewErrorMsg(fmt.Errorf("switching profile: %w", err))
+		}
+		info := uiutil.InfoMsg{Type: uiutil.InfoTypeSuccess, Msg: "Switched to profile " + name}
+		if name == "" {
+			info.Msg = "Switched to no profile"
+		}
+		return profileSwitchedMsg{info: info}
+	}
+}
+
+// discoverModels lists the models of the providers with discover_models set
+// again.
+func (m *UI) discoverModels() tea.Cmd {
+	return func() tea.Msg {
+		n, err := m.com.App.DiscoverModels(context.Background())
+		if err != nil {
+			return uiutil.NewErrorMsg(err)
+		}
+		return uiutil.InfoMsg{
+			Type: uiutil.InfoTypeSuccess,
+			Msg:  fmt.Sprintf("Discovered %d models", n),
+		}
+	}
+}
+
 // renderSidebarLogo renders and caches the sidebar logo at the specified
 // width.
 func (m *UI) renderSidebarLogo(width int) {
 	m.sidebarLogo = renderLogo(m.com.Styles, true, width)
 }
 
+// saveMemory saves entry in the project's memory file, without asking the
+// agent.
+func (m *UI) saveMemory(entry string) tea.Cmd {
+	cfg := m.com.Config()
+	path := memory.Path(cfg.WorkingDir(), cfg.Options.InitializeAs, memory.ScopeProject)
+	if err := memory.Save(path, entry); err != nil {
+		return uiutil.ReportError(err)
+	}
+	return uiutil.ReportInfo(fmt.Sprintf("Saved to memory in %s", fsext.PrettyPath(path)))
+}
+
 // sendMessage sends a message with the given content and attachments.
 func (m *UI) sendMessage(content string, attachments ...message.Attachment) tea.Cmd {
+	return m.runAgent(func(ctx context.Context, sessionID string) error {
+		_, err := m.com.App.AgentCoordinator.Run(ctx, sessionID, content, attachments...)
+		return err
+	})
+}
+
+// runCustomCommand expands the prompt of a custom command, which can ask for
+// permission to run its shell blocks, and sends it with the command's model
+// and tools.
+func (m *UI) runCustomCommand(command commands.CustomCommand, args map[string]string) tea.Cmd {
+	return m.runAgent(func(ctx context.Context, sessionID string) error {
+		workingDir := m.com.Config().WorkingDir()
+		run := commands.PermissionShell(m.com.App.Permissions, sessionID, workingDir)
+		content, err := command.Expand(ctx, workingDir, args, run)
+		if err != nil {
+			return err
+		}
+		_, err = m.com.App.AgentCoordinator.RunWithOptions(ctx, sessionID, content, agent.RunOptions{
+			Model:        command.Model,
+			Agent:        command.Agent,
+			AllowedTools: command.AllowedTools,
+		})
+		return err
+	})
+}
+
+// runAgent runs fn with the current session, creating one if needed.
+func (m *UI) runAgent(fn func(ctx context.Context, sessionID string) error) tea.Cmd {
 	if m.com.App.AgentCoordinator == nil {
 		return uiutil.ReportError(fmt.Errorf("coder agent is not initialized"))
 	}
@@ -2614,8 +2757,7 @@ func (m *UI) sendMessage(content string, attachments ...message.Attachment) tea.
 	// Capture session ID to avoid race with main goroutine updating m.session.
 	sessionID := m.session.ID
 	cmds = append(cmds, func() tea.Msg {
-		_, err := m.com.App.AgentCoordinator.Run(context.Background(), sessionID, content, attachments...)
-		if err != nil {
+		if err := fn(context.Background(), sessionID); err != nil {
 			isCancelErr := errors.Is(err, context.Canceled)
 			isPermissionErr := errors.Is(err, permission.ErrorPermissionDenied)
 			if isCancelErr || isPermissionErr {
@@ -2816,6 +2958,36 @@ func (m *UI) openFilesDialog() tea.Cmd {
 }
 
 // openPermissionsDialog opens the permissions dialog for a permission request.
+// openElicitationDialog opens a form asking the user for the input an MCP
+// server requested.
+func (m *UI) openElicitationDialog(req mcp.ElicitationRequest) {
+	args := make([]commands.Argument, 0, len(req.Fields))
+	for _, f := range req.Fields {
+		description := f.Description
+		switch {
+		case len(f.Enum) > 0:
+			description = cmp.Or(description, "One of") + " (" + strings.Join(f.Enum, ", ") + ")"
+		case f.Type == "boolean":
+			description = cmp.Or(description, "Yes or no") + " (yes/no)"
+		}
+		args = append(args, commands.Argument{
+			ID:          f.Name,
+			Title:       cmp.Or(f.Title, f.Name),
+			Description: description,
+			Required:    f.Required,
+			Default:     f.Default,
+		})
+	}
+
+	m.dialog.OpenDialog(dialog.NewArguments(
+		m.com,
+		req.Name+" Needs Input",
+		req.Message,
+		args,
+		dialog.ActionRespondElicitation{Request: req},
+	))
+}
+
 func (m *UI) openPermissionsDialog(perm permission.PermissionRequest) tea.Cmd {
 	// Close any existing permissions dialog first.
 	m.dialog.CloseDialog(dialog.PermissionsID)